@@ -0,0 +1,217 @@
+// Package address is a pure Go encoder and validator of Bitcoin addresses.
+//
+// It supports base58check P2PKH and P2SH addresses and bech32 (segwit
+// version 0) and bech32m (segwit version 1 and above) addresses, with the
+// rules of Bitcoin Core: an address is valid on a network when
+// "validateaddress" on a node of that network would report it valid, and
+// the decoded type, witness program and scriptPubKey are the ones the node
+// returns.
+package address
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A Network represents the address encoding parameters of a Bitcoin network
+type Network struct {
+	// The chain name, as returned by "getblockchaininfo"
+	Name string
+	// The base58 version byte of P2PKH addresses
+	PubKeyHashAddrID byte
+	// The base58 version byte of P2SH addresses
+	ScriptHashAddrID byte
+	// The human readable part of segwit addresses
+	Bech32HRP string
+}
+
+// Supported networks
+var (
+	MainNet  = &Network{Name: "main", PubKeyHashAddrID: 0x00, ScriptHashAddrID: 0x05, Bech32HRP: "bc"}
+	TestNet  = &Network{Name: "test", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4, Bech32HRP: "tb"}
+	TestNet4 = &Network{Name: "testnet4", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4, Bech32HRP: "tb"}
+	SigNet   = &Network{Name: "signet", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4, Bech32HRP: "tb"}
+	RegTest  = &Network{Name: "regtest", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4, Bech32HRP: "bcrt"}
+)
+
+// networks are the networks with distinct address encodings
+var networks = []*Network{MainNet, TestNet, RegTest}
+
+// NetworkByName returns the network of a chain name, as returned by
+// "getblockchaininfo"
+func NetworkByName(name string) (*Network, error) {
+	for _, net := range []*Network{MainNet, TestNet, TestNet4, SigNet, RegTest} {
+		if net.Name == name {
+			return net, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown network %q", name)
+}
+
+// A Type represents the type of an address, named after the type of its
+// scriptPubKey in bitcoind responses
+type Type string
+
+// Address types
+const (
+	PubKeyHash          Type = "pubkeyhash"
+	ScriptHash          Type = "scripthash"
+	WitnessV0KeyHash    Type = "witness_v0_keyhash"
+	WitnessV0ScriptHash Type = "witness_v0_scripthash"
+	WitnessV1Taproot    Type = "witness_v1_taproot"
+	Anchor              Type = "anchor"
+	WitnessUnknown      Type = "witness_unknown"
+)
+
+// anchorProgram is the witness program of pay-to-anchor outputs
+var anchorProgram = []byte{0x4e, 0x73}
+
+// An Address represents a decoded address
+type Address struct {
+	// The address in canonical form, as returned by "validateaddress":
+	// segwit addresses are lower case
+	Address string
+	// The network of the address
+	Network *Network
+	// The address type
+	Type Type
+	// The public key or script hash of P2PKH and P2SH addresses
+	Hash []byte
+	// The witness version of segwit addresses, -1 for the others
+	WitnessVersion int
+	// The witness program of segwit addresses
+	WitnessProgram []byte
+	// The scriptPubKey paying to the address
+	ScriptPubKey []byte
+}
+
+// String returns the address
+func (a *Address) String() string {
+	return a.Address
+}
+
+// IsScript tells whether the address pays to a script, like the "isscript"
+// field of "validateaddress"
+func (a *Address) IsScript() bool {
+	switch a.Type {
+	case ScriptHash, WitnessV0ScriptHash, WitnessV1Taproot, Anchor:
+		return true
+	}
+	return false
+}
+
+// IsWitness tells whether the address is a segwit address, like the
+// "iswitness" field of "validateaddress"
+func (a *Address) IsWitness() bool {
+	return a.WitnessVersion >= 0
+}
+
+// ScriptPubKeyHex returns the hex encoded scriptPubKey, like the
+// "scriptPubKey" field of "validateaddress"
+func (a *Address) ScriptPubKeyHex() string {
+	return hex.EncodeToString(a.ScriptPubKey)
+}
+
+// Decode decodes and validates an address of net
+func Decode(addr string, net *Network) (*Address, error) {
+	if version, payload, err := Base58CheckDecode(addr); err == nil {
+		if len(payload) == 20 {
+			switch version {
+			case net.PubKeyHashAddrID:
+				return &Address{Address: addr, Network: net, Type: PubKeyHash, Hash: payload, WitnessVersion: -1, ScriptPubKey: p2pkhScript(payload)}, nil
+			case net.ScriptHashAddrID:
+				return &Address{Address: addr, Network: net, Type: ScriptHash, Hash: payload, WitnessVersion: -1, ScriptPubKey: p2shScript(payload)}, nil
+			}
+		}
+		return nil, fmt.Errorf("Invalid address %q: invalid or unsupported base58 address for %s", addr, net.Name)
+	}
+	hrp, version, program, err := segwitDecode(addr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid address %q: %v", addr, err)
+	}
+	if hrp != net.Bech32HRP {
+		return nil, fmt.Errorf("Invalid address %q: invalid prefix for %s", addr, net.Name)
+	}
+	return witnessAddress(strings.ToLower(addr), net, version, program), nil
+}
+
+// DecodeAny decodes and validates an address of any network with a distinct
+// encoding: MainNet, TestNet (also used by TestNet4 and SigNet) or RegTest
+func DecodeAny(addr string) (*Address, error) {
+	if hrp, _, _, err := segwitDecode(addr); err == nil {
+		for _, net := range networks {
+			if net.Bech32HRP == hrp {
+				return Decode(addr, net)
+			}
+		}
+		return nil, fmt.Errorf("Invalid address %q: unknown segwit address prefix %q", addr, hrp)
+	}
+	version, _, err := Base58CheckDecode(addr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid address %q", addr)
+	}
+	for _, net := range networks {
+		if version == net.PubKeyHashAddrID || version == net.ScriptHashAddrID {
+			return Decode(addr, net)
+		}
+	}
+	return nil, fmt.Errorf("Invalid address %q: unknown version byte 0x%02x", addr, version)
+}
+
+// FromScript returns the address of net paying to a P2PKH, P2SH or segwit
+// scriptPubKey
+func FromScript(script []byte, net *Network) (*Address, error) {
+	switch {
+	case len(script) == 25 && script[0] == opDup && script[1] == opHash160 && script[2] == 20 && script[23] == opEqualVerify && script[24] == opCheckSig:
+		hash := script[3:23]
+		return &Address{Address: Base58CheckEncode(net.PubKeyHashAddrID, hash), Network: net, Type: PubKeyHash, Hash: hash, WitnessVersion: -1, ScriptPubKey: script}, nil
+	case len(script) == 23 && script[0] == opHash160 && script[1] == 20 && script[22] == opEqual:
+		hash := script[2:22]
+		return &Address{Address: Base58CheckEncode(net.ScriptHashAddrID, hash), Network: net, Type: ScriptHash, Hash: hash, WitnessVersion: -1, ScriptPubKey: script}, nil
+	case len(script) >= 4 && len(script) <= 42 && (script[0] == op0 || (script[0] >= op1 && script[0] <= op16)) && int(script[1]) == len(script)-2:
+		version := script[0]
+		if version != op0 {
+			version -= op1 - 1
+		}
+		addr, err := segwitEncode(net.Bech32HRP, version, script[2:])
+		if err != nil {
+			return nil, err
+		}
+		return witnessAddress(addr, net, version, script[2:]), nil
+	default:
+		return nil, errors.New("scriptPubKey has no address")
+	}
+}
+
+// WitnessScript returns the scriptPubKey of a witness program
+func WitnessScript(version byte, program []byte) []byte {
+	op := byte(op0)
+	if version != 0 {
+		op = op1 - 1 + version
+	}
+	return append([]byte{op, byte(len(program))}, program...)
+}
+
+// witnessAddress returns the address of a valid witness program
+func witnessAddress(addr string, net *Network, version byte, program []byte) *Address {
+	a := &Address{Address: addr, Network: net, Type: WitnessUnknown, WitnessVersion: int(version), WitnessProgram: program, ScriptPubKey: WitnessScript(version, program)}
+	switch {
+	case version == 0 && len(program) == 20:
+		a.Type = WitnessV0KeyHash
+	case version == 0:
+		a.Type = WitnessV0ScriptHash
+	case version == 1 && len(program) == 32:
+		a.Type = WitnessV1Taproot
+	case version == 1 && string(program) == string(anchorProgram):
+		a.Type = Anchor
+	}
+	return a
+}
+
+// IsValid tells whether addr is a valid address of net
+func IsValid(addr string, net *Network) bool {
+	_, err := Decode(addr, net)
+	return err == nil
+}
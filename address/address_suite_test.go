@@ -0,0 +1,13 @@
+package address
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAddress(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Address Suite")
+}
@@ -0,0 +1,156 @@
+package address
+
+import (
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// mustHex decodes a hex string
+func mustHex(s string) []byte {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+var _ = Describe("Address", func() {
+	Describe("Testing Decode", func() {
+		It("should decode valid addresses, as bitcoind does", func() {
+			for _, v := range []struct {
+				addr           string
+				net            *Network
+				canonical      string
+				typ            Type
+				witnessVersion int
+				script         string
+			}{
+				// Genesis block coinbase
+				{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", MainNet, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", PubKeyHash, -1, "76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac"},
+				// BIP 173 and BIP 350 test vectors
+				{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", MainNet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", WitnessV0KeyHash, 0, "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+				{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", TestNet, "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", WitnessV0ScriptHash, 0, "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+				{"bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", MainNet, "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7kt5nd6y", WitnessUnknown, 1, "5128751e76e8199196d454941c45d1b3a323f1433bd6751e76e8199196d454941c45d1b3a323f1433bd6"},
+				{"BC1SW50QGDZ25J", MainNet, "bc1sw50qgdz25j", WitnessUnknown, 16, "6002751e"},
+				{"tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", SigNet, "tb1pqqqqp399et2xygdj5xreqhjjvcmzhxw4aywxecjdzew6hylgvsesf3hn0c", WitnessV1Taproot, 1, "5120000000c4a5cad46221b2a187905e5266362b99d5e91c6ce24d165dab93e86433"},
+				{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", MainNet, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", WitnessV1Taproot, 1, "512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"},
+			} {
+				a, err := Decode(v.addr, v.net)
+				Expect(err).NotTo(HaveOccurred(), v.addr)
+				Expect(a.Address).To(Equal(v.canonical))
+				Expect(a.Network).To(Equal(v.net))
+				Expect(a.Type).To(Equal(v.typ), v.addr)
+				Expect(a.WitnessVersion).To(Equal(v.witnessVersion), v.addr)
+				Expect(a.IsWitness()).To(Equal(v.witnessVersion >= 0))
+				Expect(a.ScriptPubKeyHex()).To(Equal(v.script), v.addr)
+				if a.IsWitness() {
+					Expect(a.WitnessProgram).To(Equal(a.ScriptPubKey[2:]))
+				}
+			}
+		})
+		It("should decode P2SH addresses", func() {
+			a, err := FromScript(mustHex("a914b472a266d0bd89c13706a4132ccfb16f7c3b9fcb87"), MainNet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Address).To(HavePrefix("3"))
+			decoded, err := Decode(a.Address, MainNet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded.Type).To(Equal(ScriptHash))
+			Expect(decoded.IsScript()).To(BeTrue())
+			Expect(decoded.Hash).To(Equal(mustHex("b472a266d0bd89c13706a4132ccfb16f7c3b9fcb")))
+		})
+		It("should decode pay-to-anchor addresses", func() {
+			a, err := Decode("bc1pfeessrawgf", MainNet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Type).To(Equal(Anchor))
+			Expect(a.ScriptPubKeyHex()).To(Equal("51024e73"))
+		})
+		It("should reject invalid addresses, as bitcoind does", func() {
+			for _, v := range []struct {
+				addr string
+				net  *Network
+			}{
+				// Wrong network
+				{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", TestNet},
+				{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", TestNet},
+				{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", RegTest},
+				// Bad checksums
+				{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", MainNet},
+				{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", MainNet},
+				// BIP 350 invalid addresses
+				{"tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut", MainNet},
+				{"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", MainNet},
+				{"BC1S0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ54WELL", MainNet},
+				{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", MainNet},
+				// Mixed case
+				{"bc1qW508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", MainNet},
+				{"", MainNet},
+				{"not an address", MainNet},
+			} {
+				_, err := Decode(v.addr, v.net)
+				Expect(err).To(HaveOccurred(), v.addr)
+				Expect(IsValid(v.addr, v.net)).To(BeFalse())
+			}
+		})
+		It("should reject witness programs of bad length", func() {
+			for _, program := range [][]byte{make([]byte, 1), make([]byte, 41)} {
+				_, err := segwitEncode("bc", 1, program)
+				Expect(err).To(HaveOccurred())
+			}
+			_, err := segwitEncode("bc", 0, make([]byte, 21))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Testing DecodeAny", func() {
+		It("should find the network", func() {
+			for addr, net := range map[string]*Network{
+				"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa":                             MainNet,
+				"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7": TestNet,
+				"mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn":                             TestNet,
+			} {
+				a, err := DecodeAny(addr)
+				Expect(err).NotTo(HaveOccurred(), addr)
+				Expect(a.Network).To(Equal(net))
+			}
+		})
+		It("should fail on unknown prefixes", func() {
+			_, err := DecodeAny("tc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq5zuyut")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Testing FromScript", func() {
+		It("should roundtrip with Decode", func() {
+			for _, net := range []*Network{MainNet, TestNet, RegTest} {
+				for _, script := range []string{
+					"76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac",
+					"a914b472a266d0bd89c13706a4132ccfb16f7c3b9fcb87",
+					"0014751e76e8199196d454941c45d1b3a323f1433bd6",
+					"00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262",
+					"512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+					"6002751e",
+				} {
+					a, err := FromScript(mustHex(script), net)
+					Expect(err).NotTo(HaveOccurred())
+					decoded, err := Decode(a.Address, net)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(decoded).To(Equal(a))
+				}
+			}
+		})
+		It("should fail on scripts without address", func() {
+			_, err := FromScript(mustHex("6a0401020304"), MainNet)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Testing NetworkByName", func() {
+		It("should return the network of a chain", func() {
+			Expect(NetworkByName("signet")).To(Equal(SigNet))
+			_, err := NetworkByName("unknown")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Base58Encode encodes data in base58
+func Base58Encode(data []byte) string {
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// Base58Decode decodes a base58 string
+func Base58Decode(str string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range str {
+		digit := strings.IndexRune(base58Alphabet, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("Invalid base58 character %q", c)
+		}
+		n.Mul(n, radix).Add(n, big.NewInt(int64(digit)))
+	}
+	var zeros int
+	for zeros < len(str) && str[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// Base58CheckEncode encodes a version byte and payload with a checksum
+func Base58CheckEncode(version byte, payload []byte) string {
+	data := append([]byte{version}, payload...)
+	return Base58Encode(append(data, checksum(data)...))
+}
+
+// Base58CheckDecode decodes a base58check string into its version byte and
+// payload
+func Base58CheckDecode(str string) (version byte, payload []byte, err error) {
+	data, err := Base58Decode(str)
+	if err != nil {
+		return
+	}
+	if len(data) < 5 {
+		err = errors.New("Base58check string is too short")
+		return
+	}
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if !bytes.Equal(checksum(body), sum) {
+		err = errors.New("Bad base58check checksum")
+		return
+	}
+	return body[0], body[1:], nil
+}
+
+// checksum returns the first 4 bytes of SHA256(SHA256(data))
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
@@ -0,0 +1,164 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Checksum constants of BIP 173 bech32 and BIP 350 bech32m
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// bech32Polymod computes the bech32 checksum polynomial
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HrpExpand expands the human readable part for checksum computation
+func bech32HrpExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits regroups bits of data from fromBits to toBits per element
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var nbits uint
+	var out []byte
+	maxv := uint32(1)<<toBits - 1
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, errors.New("Invalid data value")
+		}
+		acc = acc<<fromBits | uint32(v)
+		nbits += fromBits
+		for nbits >= toBits {
+			nbits -= toBits
+			out = append(out, byte(acc>>nbits&maxv))
+		}
+	}
+	if pad {
+		if nbits > 0 {
+			out = append(out, byte(acc<<(toBits-nbits)&maxv))
+		}
+	} else if nbits >= fromBits || acc<<(toBits-nbits)&maxv != 0 {
+		return nil, errors.New("Invalid padding")
+	}
+	return out, nil
+}
+
+// validWitnessProgram checks the length of a witness program, as BIP 141
+// requires
+func validWitnessProgram(version byte, program []byte) error {
+	if version > 16 {
+		return errors.New("Invalid witness version")
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return errors.New("Invalid witness program length")
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return errors.New("Invalid witness v0 program length")
+	}
+	return nil
+}
+
+// segwitEncode encodes a witness program as a bech32 (version 0) or bech32m
+// (version 1 and above) address
+func segwitEncode(hrp string, version byte, program []byte) (string, error) {
+	if err := validWitnessProgram(version, program); err != nil {
+		return "", err
+	}
+	data, _ := convertBits(program, 8, 5, true)
+	data = append([]byte{version}, data...)
+	constant := uint32(bech32Const)
+	if version != 0 {
+		constant = bech32mConst
+	}
+	values := append(bech32HrpExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ constant
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(polymod>>uint(5*(5-i)))&31])
+	}
+	return sb.String(), nil
+}
+
+// segwitDecode decodes a bech32 or bech32m segwit address
+func segwitDecode(addr string) (hrp string, version byte, program []byte, err error) {
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		err = errors.New("Mixed case segwit address")
+		return
+	}
+	addr = strings.ToLower(addr)
+	sep := strings.LastIndexByte(addr, '1')
+	if sep < 1 || sep+7 > len(addr) || len(addr) > 90 {
+		err = errors.New("Invalid segwit address length")
+		return
+	}
+	hrp = addr[:sep]
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			err = fmt.Errorf("Invalid segwit address prefix character %q", c)
+			return
+		}
+	}
+	data := make([]byte, 0, len(addr)-sep-1)
+	for _, c := range addr[sep+1:] {
+		d := strings.IndexRune(bech32Charset, c)
+		if d < 0 {
+			err = fmt.Errorf("Invalid bech32 character %q", c)
+			return
+		}
+		data = append(data, byte(d))
+	}
+	if len(data) < 7 {
+		err = errors.New("Invalid segwit address length")
+		return
+	}
+	version = data[0]
+	constant := uint32(bech32Const)
+	if version != 0 {
+		constant = bech32mConst
+	}
+	if bech32Polymod(append(bech32HrpExpand(hrp), data...)) != constant {
+		err = errors.New("Bad segwit address checksum")
+		return
+	}
+	if version > 16 {
+		err = errors.New("Invalid witness version")
+		return
+	}
+	if program, err = convertBits(data[1:len(data)-6], 5, 8, false); err != nil {
+		return
+	}
+	err = validWitnessProgram(version, program)
+	return
+}
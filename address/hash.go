@@ -0,0 +1,15 @@
+package address
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Hash160 returns RIPEMD160(SHA256(data)), used by P2PKH, P2WPKH and P2SH
+func Hash160(data []byte) []byte {
+	h := sha256.Sum256(data)
+	r := ripemd160.New()
+	r.Write(h[:])
+	return r.Sum(nil)
+}
@@ -0,0 +1,27 @@
+package address
+
+// Opcodes of the scriptPubKeys paying to addresses
+const (
+	op0           = 0x00
+	op1           = 0x51
+	op16          = 0x60
+	opDup         = 0x76
+	opEqual       = 0x87
+	opEqualVerify = 0x88
+	opHash160     = 0xa9
+	opCheckSig    = 0xac
+)
+
+// p2pkhScript returns the P2PKH scriptPubKey of a public key hash
+func p2pkhScript(pubKeyHash []byte) []byte {
+	script := []byte{opDup, opHash160, 20}
+	script = append(script, pubKeyHash...)
+	return append(script, opEqualVerify, opCheckSig)
+}
+
+// p2shScript returns the P2SH scriptPubKey of a script hash
+func p2shScript(scriptHash []byte) []byte {
+	script := []byte{opHash160, 20}
+	script = append(script, scriptHash...)
+	return append(script, opEqual)
+}
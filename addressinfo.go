@@ -0,0 +1,99 @@
+package bitcoind
+
+import "encoding/json"
+
+// AddressLabels represents the labels of an address, as returned by
+// "getaddressinfo" call
+type AddressLabels []string
+
+// UnmarshalJSON decodes the labels, given as names since Core 0.20 and as
+// {name, purpose} objects before
+func (l *AddressLabels) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	labels := make(AddressLabels, 0, len(raw))
+	for _, r := range raw {
+		var label string
+		if err := json.Unmarshal(r, &label); err != nil {
+			var named struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(r, &named); err != nil {
+				return err
+			}
+			label = named.Name
+		}
+		labels = append(labels, label)
+	}
+	*l = labels
+	return nil
+}
+
+// AddressInfo represents a response to "getaddressinfo" call
+type AddressInfo struct {
+	// The bitcoin address validated
+	Address string `json:"address"`
+	// The hex-encoded scriptPubKey generated by the address
+	ScriptPubKey string `json:"scriptPubKey"`
+	// If the address is yours
+	IsMine bool `json:"ismine"`
+	// If the address is watchonly
+	IsWatchOnly bool `json:"iswatchonly"`
+	// If we know how to spend coins sent to this address, ignoring the
+	// possible lack of private keys
+	Solvable bool `json:"solvable"`
+	// A descriptor for spending coins sent to this address, only when
+	// solvable
+	Desc string `json:"desc,omitempty"`
+	// The descriptor used to derive this address if this is a descriptor
+	// wallet
+	ParentDesc string `json:"parent_desc,omitempty"`
+	// If the key is a script
+	IsScript bool `json:"isscript"`
+	// If the address was used for change output
+	IsChange bool `json:"ischange"`
+	// If the address is a witness address
+	IsWitness bool `json:"iswitness"`
+	// The version number of the witness program, nil for non witness addresses
+	WitnessVersion *int `json:"witness_version,omitempty"`
+	// The hex value of the witness program, only for witness addresses
+	WitnessProgram string `json:"witness_program,omitempty"`
+	// The output script type, only for P2SH and P2WSH addresses
+	Script string `json:"script,omitempty"`
+	// The redeemscript for the P2SH or P2WSH address
+	Hex string `json:"hex,omitempty"`
+	// The hex public keys, only for multisig scripts
+	PubKeys []string `json:"pubkeys,omitempty"`
+	// The number of signatures required to spend multisig output
+	SigsRequired int `json:"sigsrequired,omitempty"`
+	// The hex value of the raw public key for single-key addresses
+	PubKey string `json:"pubkey,omitempty"`
+	// Information about the address embedded in P2SH or P2WSH, if relevant
+	// and known
+	Embedded *AddressInfo `json:"embedded,omitempty"`
+	// If the pubkey is compressed
+	IsCompressed *bool `json:"iscompressed,omitempty"`
+	// The creation time of the key, if available, in seconds since epoch
+	Timestamp int64 `json:"timestamp,omitempty"`
+	// The HD keypath, if the key is HD and available
+	HDKeyPath string `json:"hdkeypath,omitempty"`
+	// The Hash160 of the HD seed, for legacy wallets
+	HDSeedID string `json:"hdseedid,omitempty"`
+	// The fingerprint of the master key
+	HDMasterFingerprint string `json:"hdmasterfingerprint,omitempty"`
+	// The labels associated with the address
+	Labels AddressLabels `json:"labels,omitempty"`
+}
+
+// GetAddressInfo returns information about the given address, known to the
+// wallet or not
+func (b *Bitcoind) GetAddressInfo(address string) (info AddressInfo, err error) {
+	r, err := b.client.call("getaddressinfo", []string{address})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &info)
+	return
+}
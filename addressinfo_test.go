@@ -0,0 +1,79 @@
+package bitcoind
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AddressInfo", func() {
+	Describe("Testing GetAddressInfo", func() {
+		Context("when the address is a wallet P2SH-P2WPKH address", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getaddressinfo": `{"address":"3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN","scriptPubKey":"a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487","ismine":true,"solvable":true,"desc":"sh(wpkh([1f7a6b2c/49h/0h/0h/0/3]0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c))#7nhhwmr7","parent_desc":"sh(wpkh([1f7a6b2c/49h/0h/0h]xpub6CUGRUonZSQ4TWtTMmzXdrXDtypWKiKrhko4egpiMZbpiaQL2jkwSB1icqYh2cfDfVxdx4df189oLKnC5fSwqPfgyP3hooxujYzAu3fDVmz/0/*))#qwdjjdvk","iswatchonly":false,"isscript":true,"iswitness":false,"script":"witness_v0_keyhash","hex":"0014751e76e8199196d454941c45d1b3a323f1433bd6","pubkey":"0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c","embedded":{"isscript":false,"iswitness":true,"witness_version":0,"witness_program":"751e76e8199196d454941c45d1b3a323f1433bd6","pubkey":"0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c","address":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4","scriptPubKey":"0014751e76e8199196d454941c45d1b3a323f1433bd6"},"ischange":false,"timestamp":1700000000,"hdkeypath":"m/49h/0h/0h/0/3","hdseedid":"0000000000000000000000000000000000000000","hdmasterfingerprint":"1f7a6b2c","labels":["savings"]}`,
+			}, &calls)
+			defer done()
+			info, err := bitcoindClient.GetAddressInfo("3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the address", func() {
+				Expect(calls).To(Equal([]string{"getaddressinfo [3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN]"}))
+			})
+			It("should return the wallet fields", func() {
+				Expect(info.IsMine).To(BeTrue())
+				Expect(info.IsWatchOnly).To(BeFalse())
+				Expect(info.Solvable).To(BeTrue())
+				Expect(info.Desc).To(HavePrefix("sh(wpkh([1f7a6b2c/49h/0h/0h/0/3]"))
+				Expect(info.ParentDesc).To(HaveSuffix("#qwdjjdvk"))
+				Expect(info.HDKeyPath).To(Equal("m/49h/0h/0h/0/3"))
+				Expect(info.HDSeedID).To(Equal("0000000000000000000000000000000000000000"))
+				Expect(info.HDMasterFingerprint).To(Equal("1f7a6b2c"))
+				Expect(info.Labels).To(Equal(AddressLabels{"savings"}))
+			})
+			It("should return the script fields", func() {
+				Expect(info.IsScript).To(BeTrue())
+				Expect(info.IsWitness).To(BeFalse())
+				Expect(info.Script).To(Equal("witness_v0_keyhash"))
+				Expect(info.PubKey).To(Equal("0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c"))
+			})
+			It("should return the embedded address", func() {
+				witnessVersion := 0
+				Expect(info.WitnessVersion).To(BeNil())
+				Expect(info.Embedded).To(Equal(&AddressInfo{
+					Address:        "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+					ScriptPubKey:   "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+					IsWitness:      true,
+					WitnessVersion: &witnessVersion,
+					WitnessProgram: "751e76e8199196d454941c45d1b3a323f1433bd6",
+					PubKey:         "0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c",
+				}))
+			})
+		})
+		Context("when the node returns labels as objects", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getaddressinfo": `{"address":"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy","ismine":true,"labels":[{"name":"tests","purpose":"receive"},{"name":"","purpose":"send"}]}`,
+			}, &calls)
+			defer done()
+			info, err := bitcoindClient.GetAddressInfo("1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return the label names", func() {
+				Expect(info.Labels).To(Equal(AddressLabels{"tests", ""}))
+			})
+		})
+		Context("when the address is invalid", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(nil, &calls, map[string]RPCError{
+				"getaddressinfo": {Code: -5, Message: "Invalid address"},
+			})
+			defer done()
+			_, err := bitcoindClient.GetAddressInfo("bad")
+			It("should return the RPC error", func() {
+				Expect(err).To(Equal(&RPCError{Code: -5, Message: "Invalid address"}))
+			})
+		})
+	})
+})
@@ -0,0 +1,180 @@
+package bitcoind
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// SatoshiPerBitcoin is the number of satoshis in one bitcoin
+const SatoshiPerBitcoin = 1e8
+
+// Amount represents a bitcoin amount in satoshis.
+// Amounts are encoded in JSON as exact BTC decimal values, like bitcoind
+// does, and add up without the rounding errors of float64 BTC values.
+type Amount int64
+
+// NewAmount returns the amount of a float64 BTC value, rounded to the
+// nearest satoshi, which is exact for values returned by bitcoind
+func NewAmount(btc float64) (Amount, error) {
+	if math.IsNaN(btc) || math.IsInf(btc, 0) {
+		return 0, fmt.Errorf("Bad amount: %v is not a number", btc)
+	}
+	sat := math.Round(btc * SatoshiPerBitcoin)
+	if sat >= math.MaxInt64 || sat < math.MinInt64 {
+		return 0, fmt.Errorf("Bad amount: %v BTC overflows", btc)
+	}
+	return Amount(sat), nil
+}
+
+// ParseAmount parses an exact BTC decimal value, like "0.00010000", "-1.5",
+// "1e-8" or "0.5 BTC". It fails on values with more than 8 significant
+// decimals.
+func ParseAmount(s string) (Amount, error) {
+	value := strings.TrimSuffix(s, " BTC")
+	mantissa, exponent := value, 0
+	if i := strings.IndexAny(value, "eE"); i >= 0 {
+		var err error
+		if exponent, err = strconv.Atoi(value[i+1:]); err != nil {
+			return 0, fmt.Errorf("Bad amount %q: bad exponent", s)
+		}
+		mantissa = value[:i]
+	}
+	negative := strings.HasPrefix(mantissa, "-")
+	if negative || strings.HasPrefix(mantissa, "+") {
+		mantissa = mantissa[1:]
+	}
+	integer, fraction := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		integer, fraction = mantissa[:i], mantissa[i+1:]
+	}
+	digits := integer + fraction
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return 0, fmt.Errorf("Bad amount %q: not a decimal number", s)
+	}
+	// The value is digits * 10^(exponent - len(fraction)) BTC, convert it to
+	// satoshis
+	shift := int64(exponent) - int64(len(fraction)) + 8
+	if strings.Trim(digits, "0") == "" {
+		return 0, nil
+	}
+	if shift < -int64(len(digits)) {
+		return 0, fmt.Errorf("Bad amount %q: more than 8 decimals", s)
+	}
+	if shift > 19 {
+		return 0, fmt.Errorf("Bad amount %q: out of range", s)
+	}
+	sat, _ := new(big.Int).SetString(digits, 10)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(absInt64(shift)), nil)
+	if shift >= 0 {
+		sat.Mul(sat, scale)
+	} else {
+		var remainder big.Int
+		if sat.QuoRem(sat, scale, &remainder); remainder.Sign() != 0 {
+			return 0, fmt.Errorf("Bad amount %q: more than 8 decimals", s)
+		}
+	}
+	if negative {
+		sat.Neg(sat)
+	}
+	if !sat.IsInt64() {
+		return 0, fmt.Errorf("Bad amount %q: out of range", s)
+	}
+	return Amount(sat.Int64()), nil
+}
+
+// absInt64 returns the absolute value of i
+func absInt64(i int64) int64 {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// ToBTC returns the amount in BTC, as a float64
+func (a Amount) ToBTC() float64 {
+	return float64(a) / SatoshiPerBitcoin
+}
+
+// Format returns the amount as a BTC decimal value with 8 decimals, like
+// "0.00010000"
+func (a Amount) Format() string {
+	sign, sat := "", uint64(a)
+	if a < 0 {
+		sign, sat = "-", uint64(-(a+1))+1
+	}
+	return fmt.Sprintf("%s%d.%08d", sign, sat/SatoshiPerBitcoin, sat%SatoshiPerBitcoin)
+}
+
+// String returns the amount formatted with its unit, like "0.00010000 BTC"
+func (a Amount) String() string {
+	return a.Format() + " BTC"
+}
+
+// MarshalJSON encodes the amount as an exact BTC decimal number
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.Format()), nil
+}
+
+// UnmarshalJSON decodes an exact BTC decimal number, or a string holding one
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	amount, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// MulF64 returns the amount multiplied by f, rounded to the nearest satoshi.
+// It fails when f is not a number or the result overflows.
+func (a Amount) MulF64(f float64) (Amount, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("Bad parameters for MulF64: %v is not a number", f)
+	}
+	product := math.Round(float64(a) * f)
+	if product >= math.MaxInt64 || product < math.MinInt64 {
+		return 0, fmt.Errorf("Bad parameters for MulF64: %v * %v overflows", a, f)
+	}
+	return Amount(product), nil
+}
+
+// Split divides the amount in n shares, which differ by at most one satoshi
+// and add up to the amount
+func (a Amount) Split(n int) ([]Amount, error) {
+	if n <= 0 {
+		return nil, errors.New("Bad parameters for Split: you must split in at least one share")
+	}
+	shares := make([]Amount, n)
+	share, remainder := a/Amount(n), a%Amount(n)
+	for i := range shares {
+		shares[i] = share
+		if remainder > 0 && Amount(i) < remainder {
+			shares[i]++
+		} else if remainder < 0 && Amount(i) < -remainder {
+			shares[i]--
+		}
+	}
+	return shares, nil
+}
+
+// SumAmounts returns the sum of amounts, failing when it overflows
+func SumAmounts(amounts ...Amount) (sum Amount, err error) {
+	for _, amount := range amounts {
+		if (amount > 0 && sum > math.MaxInt64-amount) || (amount < 0 && sum < math.MinInt64-amount) {
+			return 0, fmt.Errorf("Bad parameters for SumAmounts: sum overflows at %v", amount)
+		}
+		sum += amount
+	}
+	return
+}
@@ -0,0 +1,294 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Amount", func() {
+	Describe("Testing ParseAmount", func() {
+		It("should parse decimal values exactly", func() {
+			for s, expected := range map[string]Amount{
+				"0.00010000":        10000,
+				"-1.5":              -150000000,
+				"+2":                200000000,
+				"1e-8":              1,
+				"1.23E2":            12300000000,
+				"0.5 BTC":           50000000,
+				"0.100000000000":    10000000,
+				"20999999.99999999": 2099999999999999,
+				"-0":                0,
+			} {
+				amount, err := ParseAmount(s)
+				Expect(err).NotTo(HaveOccurred(), s)
+				Expect(amount).To(Equal(expected), s)
+			}
+		})
+		It("should fail on bad values", func() {
+			for _, s := range []string{"", "-", ".", "1.2.3", "abc", "0.000000001", "1e-9", "1e", "92233720368.54775808", "1e20"} {
+				_, err := ParseAmount(s)
+				Expect(err).To(HaveOccurred(), s)
+			}
+		})
+	})
+
+	Describe("Testing NewAmount", func() {
+		It("should round to the nearest satoshi", func() {
+			amount, err := NewAmount(0.1 + 0.2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(amount).To(Equal(Amount(30000000)))
+			amount, err = NewAmount(-0.00000001)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(amount).To(Equal(Amount(-1)))
+		})
+		It("should fail on values which are not amounts", func() {
+			_, err := NewAmount(1e12)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Testing formatting", func() {
+		It("should format with 8 decimals", func() {
+			Expect(Amount(10000).Format()).To(Equal("0.00010000"))
+			Expect(Amount(-150000000).String()).To(Equal("-1.50000000 BTC"))
+			Expect(Amount(-9223372036854775808).Format()).To(Equal("-92233720368.54775808"))
+			Expect(Amount(2099999999999999).ToBTC()).To(Equal(20999999.99999999))
+		})
+	})
+
+	Describe("Testing JSON", func() {
+		It("should roundtrip exact values", func() {
+			var tx struct {
+				Amounts map[string]Amount `json:"amounts"`
+				Fee     Amount            `json:"fee"`
+			}
+			data := `{"amounts":{"a":20999999.99999999,"b":"0.1"},"fee":-0.00000141}`
+			Expect(json.Unmarshal([]byte(data), &tx)).To(Succeed())
+			Expect(tx.Amounts).To(Equal(map[string]Amount{"a": 2099999999999999, "b": 10000000}))
+			Expect(tx.Fee).To(Equal(Amount(-141)))
+			encoded, err := json.Marshal(tx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(encoded)).To(Equal(`{"amounts":{"a":20999999.99999999,"b":0.10000000},"fee":-0.00000141}`))
+		})
+		It("should fail on values with too many decimals", func() {
+			var amount Amount
+			Expect(json.Unmarshal([]byte(`0.000000001`), &amount)).NotTo(Succeed())
+		})
+	})
+
+	Describe("Testing arithmetic", func() {
+		It("should add up float64 values exactly", func() {
+			var total Amount
+			for i := 0; i < 10; i++ {
+				amount, err := NewAmount(0.1)
+				Expect(err).NotTo(HaveOccurred())
+				total += amount
+			}
+			Expect(total).To(Equal(Amount(SatoshiPerBitcoin)))
+			sum, err := SumAmounts(1, 2, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sum).To(Equal(Amount(6)))
+			product, err := Amount(1000).MulF64(1.5)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(product).To(Equal(Amount(1500)))
+		})
+		It("should fail on overflows", func() {
+			_, err := SumAmounts(math.MaxInt64, 1)
+			Expect(err).To(HaveOccurred())
+			_, err = SumAmounts(math.MinInt64, -1)
+			Expect(err).To(HaveOccurred())
+			sum, err := SumAmounts(math.MaxInt64, -1, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sum).To(Equal(Amount(math.MaxInt64)))
+			_, err = Amount(math.MaxInt64).MulF64(2)
+			Expect(err).To(HaveOccurred())
+			_, err = Amount(1).MulF64(math.NaN())
+			Expect(err).To(HaveOccurred())
+		})
+		It("should split without losing satoshis", func() {
+			shares, err := Amount(10).Split(3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(shares).To(Equal([]Amount{4, 3, 3}))
+			shares, err = Amount(-10).Split(4)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(shares).To(Equal([]Amount{-3, -3, -2, -2}))
+			_, err = Amount(10).Split(0)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Testing decoded amounts", func() {
+		It("should decode struct values exactly", func() {
+			var vout Vout
+			Expect(json.Unmarshal([]byte(`{"value":0.0101,"n":0}`), &vout)).To(Succeed())
+			Expect(vout.Value).To(Equal(Amount(1010000)))
+			var tx Transaction
+			Expect(json.Unmarshal([]byte(`{"amount":-0.1,"fee":-0.0000141}`), &tx)).To(Succeed())
+			Expect(tx.Amount).To(Equal(Amount(-10000000)))
+			Expect(tx.Fee).To(Equal(Amount(-1410)))
+			var unspent Unspent
+			Expect(json.Unmarshal([]byte(`{"amount":20999999.99999999}`), &unspent)).To(Succeed())
+			Expect(unspent.Amount).To(Equal(Amount(2099999999999999)))
+		})
+		It("should decode fees exactly", func() {
+			var fees MempoolFees
+			Expect(json.Unmarshal([]byte(`{"base":0.0000141,"modified":0.0001141,"ancestor":0.0000282,"descendant":0.0000423}`), &fees)).To(Succeed())
+			Expect(fees).To(Equal(MempoolFees{Base: 1410, Modified: 11410, Ancestor: 2820, Descendant: 4230}))
+		})
+		It("should fail on values which are not amounts", func() {
+			var vout Vout
+			Expect(json.Unmarshal([]byte(`{"value":0.000000001}`), &vout)).NotTo(Succeed())
+			Expect(json.Unmarshal([]byte(`{"value":1e20}`), &vout)).NotTo(Succeed())
+		})
+	})
+
+	Describe("Testing GetBalanceSat", func() {
+		Context("when success", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"getbalance": `20999999.99999999`}, &calls)
+			defer done()
+			balance, err := bitcoindClient.GetBalanceSat("", 1)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return the exact balance", func() {
+				Expect(balance).To(Equal(Amount(2099999999999999)))
+			})
+		})
+	})
+
+	Describe("Testing SendManySat", func() {
+		Context("when success", func() {
+			var body string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := ioutil.ReadAll(r.Body)
+				body = string(data)
+				fmt.Fprintln(w, `{"result":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.SendManySat(map[string]Amount{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4": 2099999999999999}, 1, nil)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the exact amounts", func() {
+				Expect(body).To(ContainSubstring(`"params":["",{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4":20999999.99999999},1,"",[]]`))
+			})
+			It("should return the txid", func() {
+				Expect(result.TxID).To(Equal("a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"))
+			})
+		})
+		Context("when amounts are empty", func() {
+			_, err := (&Bitcoind{}).SendManySat(nil, 1, nil)
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Testing exact outputs", func() {
+		It("should encode exact output amounts", func() {
+			data, err := json.Marshal([]TxOutput{
+				{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", AmountSat: 2099999999999999},
+				{Address: "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy", Amount: 0.5},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`[{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4":20999999.99999999},{"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy":0.5}]`))
+		})
+		It("should encode exact previous output amounts", func() {
+			data, err := json.Marshal([]PrevTx{
+				{Txid: "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", ScriptPubKey: "51", Amount: 0.5, AmountSat: 2099999999999999},
+				{Txid: "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", Vout: 1, ScriptPubKey: "51", Amount: 0.5},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`[{"txid":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","vout":0,"scriptPubKey":"51","amount":20999999.99999999},` +
+				`{"txid":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","vout":1,"scriptPubKey":"51","amount":0.5}]`))
+		})
+		It("should encode exact recipient amounts", func() {
+			data, err := json.Marshal([]SendAllRecipient{
+				{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", AmountSat: 10000},
+				{Address: "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`[{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4":0.00010000},"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy"]`))
+		})
+		Context("when sending", func() {
+			var body string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := ioutil.ReadAll(r.Body)
+				body = string(data)
+				fmt.Fprintln(w, `{"result":{"complete":true,"txid":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			_, err = bitcoindClient.Send([]TxOutput{{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", AmountSat: 12345678}}, nil)
+			It("should send the exact amounts", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(body).To(ContainSubstring(`"params":[[{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4":0.12345678}]]`))
+			})
+		})
+	})
+
+	Describe("Testing SendFromSat and MoveSat", func() {
+		var bodies []string
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(data))
+			if strings.Contains(string(data), `"method":"move"`) {
+				fmt.Fprintln(w, `{"result":true,"error":null,"id":1400502065079564568}`)
+				return
+			}
+			fmt.Fprintln(w, `{"result":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515","error":null,"id":1400502065079564568}`)
+		})
+		ts, host, port, err := getNewTestServer(handler)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer ts.Close()
+		bitcoindClient, _ := New(host, port, "x", "fake", false)
+		txID, sendErr := bitcoindClient.SendFromSat("tests", "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy", 2099999999999999, 1, "", "")
+		moved, moveErr := bitcoindClient.MoveSat("tests", "other", 1, 1, "")
+		It("should send the exact amounts", func() {
+			Expect(sendErr).NotTo(HaveOccurred())
+			Expect(txID).To(Equal("a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"))
+			Expect(moveErr).NotTo(HaveOccurred())
+			Expect(moved).To(BeTrue())
+			Expect(bodies).To(HaveLen(2))
+			Expect(bodies[0]).To(ContainSubstring(`"params":["tests","1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy",20999999.99999999,1,"",""]`))
+			Expect(bodies[1]).To(ContainSubstring(`"params":["tests","other",0.00000001,1,""]`))
+		})
+	})
+
+	Describe("Testing SendToAddressSat", func() {
+		Context("when success", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"sendtoaddress": `"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"`,
+			}, &calls)
+			defer done()
+			_, err := bitcoindClient.SendToAddressSat("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", 10000, nil)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the amount in BTC", func() {
+				Expect(calls).To(Equal([]string{"sendtoaddress [bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4 0.0001  ]"}))
+			})
+		})
+	})
+})
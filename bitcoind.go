@@ -3,9 +3,14 @@
 package bitcoind
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 	"strconv"
+
+	"github.com/toorop/go-bitcoind/pow"
 )
 
 const (
@@ -59,8 +64,12 @@ func (b *Bitcoind) EncryptWallet(passphrase string) error {
 }
 
 // GetAccount returns the account associated with the given address.
+// On nodes without account RPCs, it returns the label of the address.
 func (b *Bitcoind) GetAccount(address string) (account string, err error) {
 	r, err := b.client.call("getaccount", []string{address})
+	if accountsUnsupported(err, &r) {
+		return b.labelOf(address)
+	}
 	if err = handleError(err, &r); err != nil {
 		return
 	}
@@ -72,8 +81,21 @@ func (b *Bitcoind) GetAccount(address string) (account string, err error) {
 // payments to this account.
 // If account does not exist, it will be created along with an
 // associated new address that will be returned.
+// On nodes without account RPCs, it returns the first receiving address of
+// the label [account], in lexicographic order, or a new address with that
+// label.
 func (b *Bitcoind) GetAccountAddress(account string) (address string, err error) {
 	r, err := b.client.call("getaccountaddress", []string{account})
+	if accountsUnsupported(err, &r) {
+		var addresses []string
+		if addresses, err = b.labelAddresses(account); err != nil || len(addresses) > 0 {
+			if len(addresses) > 0 {
+				address = addresses[0]
+			}
+			return
+		}
+		return b.GetNewAddress(account)
+	}
 	if err = handleError(err, &r); err != nil {
 		return
 	}
@@ -82,8 +104,13 @@ func (b *Bitcoind) GetAccountAddress(account string) (address string, err error)
 }
 
 // GetAddressesByAccount return addresses associated with account <account>
+// On nodes without account RPCs, it returns the receiving addresses of the
+// label [account].
 func (b *Bitcoind) GetAddressesByAccount(account string) (addresses []string, err error) {
 	r, err := b.client.call("getaddressesbyaccount", []string{account})
+	if accountsUnsupported(err, &r) {
+		return b.labelAddresses(account)
+	}
 	if err = handleError(err, &r); err != nil {
 		return
 	}
@@ -103,24 +130,84 @@ func (b *Bitcoind) GetBalance(account string, minconf uint64) (balance float64,
 	return
 }
 
+// GetBalanceSat returns the exact balance of the server or of a specific
+// account, like GetBalance
+func (b *Bitcoind) GetBalanceSat(account string, minconf uint64) (balance Amount, err error) {
+	r, err := b.client.call("getbalance", []interface{}{account, minconf})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &balance)
+	return
+}
+
+// BlockHeader represents a response to "getblockheader" call
 type BlockHeader struct {
-	Hash              string
-	Confirmations     int
-	Height            int
-	Version           uint32
-	VersionHex        string
-	Merkleroot        string
-	Time              int64
-	Mediantime        int64
-	Nonce             uint32
-	Bits              uint32
-	Difficulty        float64
-	Chainwork         string
-	Txes              int    `json:"nTx"`
-	Previousblockhash string `json:"omitempty"`
-	Nextblockhash     string `json:"omitempty"`
+	Hash              string  `json:"hash"`
+	Confirmations     int     `json:"confirmations"`
+	Height            int     `json:"height"`
+	Version           uint32  `json:"version"`
+	VersionHex        string  `json:"versionHex"`
+	Merkleroot        string  `json:"merkleroot"`
+	Time              int64   `json:"time"`
+	Mediantime        int64   `json:"mediantime"`
+	Nonce             uint32  `json:"nonce"`
+	Bits              uint32  `json:"-"`
+	Difficulty        float64 `json:"difficulty"`
+	Chainwork         string  `json:"chainwork"`
+	Txes              int     `json:"nTx"`
+	Previousblockhash string  `json:"previousblockhash,omitempty"`
+	Nextblockhash     string  `json:"nextblockhash,omitempty"`
+}
+
+// UnmarshalJSON decodes a getblockheader result, where bits is returned as
+// an hexadecimal string.
+func (h *BlockHeader) UnmarshalJSON(data []byte) error {
+	type blockHeader BlockHeader
+	aux := struct {
+		*blockHeader
+		Bits string `json:"bits"`
+	}{blockHeader: (*blockHeader)(h)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Bits == "" {
+		return nil
+	}
+	bits, err := strconv.ParseUint(aux.Bits, 16, 32)
+	if err != nil {
+		return err
+	}
+	h.Bits = uint32(bits)
+	return nil
+}
+
+// MarshalJSON encodes the header as a getblockheader result, with bits as an
+// hexadecimal string.
+func (h BlockHeader) MarshalJSON() ([]byte, error) {
+	type blockHeader BlockHeader
+	return json.Marshal(struct {
+		blockHeader
+		Bits string `json:"bits"`
+	}{blockHeader: blockHeader(h), Bits: fmt.Sprintf("%08x", h.Bits)})
 }
 
+// Target returns the target the block hash must be below, decoded from Bits
+func (h BlockHeader) Target() (*big.Int, error) {
+	return pow.CompactToBig(h.Bits)
+}
+
+// Work returns the expected number of hashes needed to produce the block
+func (h BlockHeader) Work() (*big.Int, error) {
+	return pow.CompactToWork(h.Bits)
+}
+
+// ChainworkInt returns the total amount of work in the chain up to the block
+func (h BlockHeader) ChainworkInt() (*big.Int, error) {
+	return pow.ParseChainwork(h.Chainwork)
+}
+
+// GetBlockheader returns information about the header of the block with the given hash.
 func (b *Bitcoind) GetBlockheader(blockHash string) (*BlockHeader, error) {
 	r, err := b.client.call("getblockheader", []string{blockHash})
 	if err = handleError(err, &r); err != nil {
@@ -128,11 +215,24 @@ func (b *Bitcoind) GetBlockheader(blockHash string) (*BlockHeader, error) {
 	}
 
 	var blockHeader BlockHeader
-	err = json.Unmarshal(r.Result, &blockHeader)
+	if err = json.Unmarshal(r.Result, &blockHeader); err != nil {
+		return nil, err
+	}
 
 	return &blockHeader, nil
 }
 
+// GetBlockHeaderRaw returns the 80 bytes serialized header of the block with
+// the given hash, hex encoded.
+func (b *Bitcoind) GetBlockHeaderRaw(blockHash string) (str string, err error) {
+	r, err := b.client.call("getblockheader", []interface{}{blockHash, false})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &str)
+	return
+}
+
 // GetBestBlockhash returns the hash of the best (tip) block in the longest block chain.
 func (b *Bitcoind) GetBestBlockhash() (bestBlockHash string, err error) {
 	r, err := b.client.call("getbestblockhash", nil)
@@ -153,6 +253,87 @@ func (b *Bitcoind) GetBlock(blockHash string) (block Block, err error) {
 	return
 }
 
+// GetBlockVerbose returns information about the block with the given hash,
+// including fully decoded transactions.
+// [verbosity] must be 2 (transactions with fee) or 3 (transactions with prevout
+// information on each input).
+func (b *Bitcoind) GetBlockVerbose(blockHash string, verbosity int) (block BlockVerbose, err error) {
+	if verbosity != 2 && verbosity != 3 {
+		err = errors.New("Bad parameters for GetBlockVerbose: verbosity must be 2 or 3")
+		return
+	}
+	r, err := b.client.call("getblock", []interface{}{blockHash, verbosity})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &block)
+	return
+}
+
+// GetBlockStream returns information about the block with the given hash,
+// decoding its transactions one at a time as the response is read and passing
+// each of them to fn, so memory stays bounded even for very large blocks.
+// [verbosity] must be 2 or 3, as for GetBlockVerbose. The returned block holds
+// the header fields only, its Tx field is left empty.
+// If fn returns an error, decoding stops and that error is returned.
+func (b *Bitcoind) GetBlockStream(blockHash string, verbosity int, fn func(tx RawTransaction) error) (block Block, err error) {
+	if verbosity != 2 && verbosity != 3 {
+		err = errors.New("Bad parameters for GetBlockStream: verbosity must be 2 or 3")
+		return
+	}
+	if fn == nil {
+		err = errors.New("Bad parameters for GetBlockStream: fn must be set")
+		return
+	}
+	found := false
+	err = b.client.callStream("getblock", []interface{}{blockHash, verbosity}, func(dec *json.Decoder) error {
+		found = true
+		for dec.More() {
+			key, err := decodeKey(dec)
+			if err != nil {
+				return err
+			}
+			if key == "tx" {
+				if err = decodeBlockTxs(dec, fn); err != nil {
+					return err
+				}
+				continue
+			}
+			field := block.field(key)
+			if field == nil {
+				var skip json.RawMessage
+				field = &skip
+			}
+			if err = dec.Decode(field); err != nil {
+				return err
+			}
+		}
+		return expectDelim(dec, '}')
+	})
+	if err == nil && !found {
+		err = errors.New("No block returned by getblock")
+	}
+	return
+}
+
+// decodeBlockTxs reads the tx array of a getblock response, passing each
+// decoded transaction to fn
+func decodeBlockTxs(dec *json.Decoder, fn func(tx RawTransaction) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		var tx RawTransaction
+		if err := dec.Decode(&tx); err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
 // GetRawBlock returns information about the block with the given hash.
 func (b *Bitcoind) GetRawBlock(blockHash string) (str string, err error) {
 	r, err := b.client.call("getblock", []interface{}{blockHash, false})
@@ -225,6 +406,15 @@ func (b *Bitcoind) GetChainTips() (tips []ChainTip, err error) {
 	return
 }
 
+// GetChainTipWork returns the total amount of work in the branch ending at tip
+func (b *Bitcoind) GetChainTipWork(tip ChainTip) (*big.Int, error) {
+	header, err := b.GetBlockheader(tip.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return header.ChainworkInt()
+}
+
 // GetConnectionCount returns the number of connections to other nodes.
 func (b *Bitcoind) GetConnectionCount() (count uint64, err error) {
 	r, err := b.client.call("getconnectioncount", nil)
@@ -286,7 +476,9 @@ func (b *Bitcoind) GetMiningInfo() (miningInfo MiningInfo, err error) {
 	return
 }
 
-// GetNewAddress return a new address for account [account].
+// GetNewAddress return a new address for account [account], which is the
+// label of the address on nodes without accounts.
+// Use GetNewAddressWithType to choose the address type.
 func (b *Bitcoind) GetNewAddress(account ...string) (addr string, err error) {
 	// 0 or 1 account
 	if len(account) > 1 {
@@ -337,37 +529,6 @@ func (b *Bitcoind) GetRawMempool() (txId []string, err error) {
 	return
 }
 
-type VerboseTx struct {
-	// Virtual transaction size as defined in BIP 141
-	Size uint32
-	// Transaction fee in BTC
-	Fee float64
-	// Transaction fee with fee deltas used for mining priority
-	ModifiedFee float64
-	// Local time when tx entered pool
-	Time uint32
-	// Block height when tx entered pool
-	Height uint32
-	// Number of inpool descendents (including this one)
-	DescendantCount uint32
-	// Virtual transaction size of in-mempool descendants (including this one)
-	DescendantSize uint32
-	// Modified fees (see above) of in-mempool descendants (including this one)
-	DescendantFees float64
-	// Number of in-mempool ancestor transactions (including this one)
-	AncestorCount uint32
-	// Virtual transaction size of in-mempool ancestors (including this one)
-	AncestorSize uint32
-	// Modified fees (see above) of in-mempool ancestors (including this one)
-	AncestorFees uint32
-	// Hash of serialized transaction, including witness data
-	WTxId string
-	// Unconfirmed transactions used as inputs for this transaction
-	Depends []string
-	// Used by Bitcoin Unlimited RPC
-	SpentBy []string
-}
-
 // GetRawMempoolVerbose returns a verbose set of transactions
 // map [TxId] => VerboseTx
 func (b *Bitcoind) GetRawMempoolVerbose() (txs map[string]VerboseTx, err error) {
@@ -403,11 +564,20 @@ func (b *Bitcoind) GetRawTransaction(txId string, verbose bool) (rawTx interface
 // GetReceivedByAccount Returns the total amount received by addresses with [account] in
 // transactions with at least [minconf] confirmations. If [account] is set to all return
 // will include all transactions to all accounts
+// On nodes without account RPCs, it returns the amount received by the label
+// [account], or by all the labels if [account] is all.
 func (b *Bitcoind) GetReceivedByAccount(account string, minconf uint32) (amount float64, err error) {
-	if account == "all" {
+	all := account == "all"
+	if all {
 		account = ""
 	}
 	r, err := b.client.call("getreceivedbyaccount", []interface{}{account, minconf})
+	if accountsUnsupported(err, &r) {
+		if all {
+			return b.receivedByAllLabels(minconf)
+		}
+		return b.GetReceivedByLabel(account, minconf)
+	}
 	if err = handleError(err, &r); err != nil {
 		return
 	}
@@ -505,8 +675,13 @@ func (b *Bitcoind) KeyPoolRefill() error {
 }
 
 // ListAccounts returns Object that has account names as keys, account balances as values.
+// On nodes without account RPCs, it returns the labels with the balance of
+// the unspent outputs of their addresses.
 func (b *Bitcoind) ListAccounts(minconf int32) (accounts map[string]float64, err error) {
 	r, err := b.client.call("listaccounts", []int32{minconf})
+	if accountsUnsupported(err, &r) {
+		return b.labelBalances(minconf)
+	}
 	if err = handleError(err, &r); err != nil {
 		return
 	}
@@ -517,7 +692,7 @@ func (b *Bitcoind) ListAccounts(minconf int32) (accounts map[string]float64, err
 // ListAddressResult represents a result composing ListAddressGroupings slice reply
 type ListAddressResult struct {
 	Address string
-	Amount  float64
+	Amount  Amount
 	Account string
 }
 
@@ -529,10 +704,16 @@ func (b *Bitcoind) ListAddressGroupings() (list []ListAddressResult, err error)
 	}
 	// hum.....
 	var t [][][]interface{}
-	err = json.Unmarshal(r.Result, &t)
+	dec := json.NewDecoder(bytes.NewReader(r.Result))
+	dec.UseNumber()
+	err = dec.Decode(&t)
 	for _, tt := range t {
 		for _, ttt := range tt {
-			list = append(list, ListAddressResult{ttt[0].(string), ttt[1].(float64), ttt[2].(string)})
+			var amount Amount
+			if amount, err = ParseAmount(ttt[1].(json.Number).String()); err != nil {
+				return
+			}
+			list = append(list, ListAddressResult{ttt[0].(string), amount, ttt[2].(string)})
 		}
 	}
 	return
@@ -543,14 +724,26 @@ type ReceivedByAccount struct {
 	// the account of the receiving addresses
 	Account string
 	// total amount received by addresses with this account
-	Amount float64
+	Amount Amount
 	// number of confirmations of the most recent transaction included
 	Confirmations uint32
 }
 
 // ListReceivedByAccount Returns an slice of AccountRecieved:
+// On nodes without account RPCs, it returns the amounts received by labels.
 func (b *Bitcoind) ListReceivedByAccount(minConf uint32, includeEmpty bool) (list []ReceivedByAccount, err error) {
 	r, err := b.client.call("listreceivedbyaccount", []interface{}{minConf, includeEmpty})
+	if accountsUnsupported(err, &r) {
+		var byLabel []ReceivedByLabel
+		if byLabel, err = b.ListReceivedByLabel(minConf, includeEmpty, false); err != nil {
+			return
+		}
+		list = make([]ReceivedByAccount, len(byLabel))
+		for i, received := range byLabel {
+			list[i] = ReceivedByAccount{Account: received.Label, Amount: received.Amount, Confirmations: received.Confirmations}
+		}
+		return
+	}
 	if err = handleError(err, &r); err != nil {
 		return
 	}
@@ -565,7 +758,7 @@ type ReceivedByAddress struct {
 	// The corresponding account
 	Account string
 	// total amount received by addresses with this account
-	Amount float64
+	Amount Amount
 	// number of confirmations of the most recent transaction included
 	Confirmations uint32
 	// Tansactions ID
@@ -612,6 +805,7 @@ func (b *Bitcoind) ListTransactions(account string, count, from uint32) (transac
 }
 
 // ListUnspent returns array of unspent transaction inputs in the wallet.
+// Use ListUnspentWithOptions for the full output details and filters.
 func (b *Bitcoind) ListUnspent(minconf, maxconf uint32) (transactions []Transaction, err error) {
 	if maxconf > 999999 {
 		maxconf = 999999
@@ -651,8 +845,25 @@ func (b *Bitcoind) LockUnspent(lock bool, outputs []UnspendableOutput) (success
 }
 
 // Move from one account in your wallet to another
+// Labels have no balance, so on nodes without account RPCs it fails with
+// ErrMoveUnsupported.
 func (b *Bitcoind) Move(formAccount, toAccount string, amount float64, minconf uint32, comment string) (success bool, err error) {
-	r, err := b.client.call("move", []interface{}{formAccount, toAccount, amount, minconf, comment})
+	return b.move(formAccount, toAccount, amount, minconf, comment)
+}
+
+// MoveSat moves an exact amount from one account in your wallet to another,
+// like Move
+func (b *Bitcoind) MoveSat(fromAccount, toAccount string, amount Amount, minconf uint32, comment string) (success bool, err error) {
+	return b.move(fromAccount, toAccount, amount, minconf, comment)
+}
+
+// move calls "move" with a float64 or Amount amount
+func (b *Bitcoind) move(fromAccount, toAccount string, amount interface{}, minconf uint32, comment string) (success bool, err error) {
+	r, err := b.client.call("move", []interface{}{fromAccount, toAccount, amount, minconf, comment})
+	if accountsUnsupported(err, &r) {
+		err = ErrMoveUnsupported
+		return
+	}
 	if err = handleError(err, &r); err != nil {
 		return
 	}
@@ -664,8 +875,25 @@ func (b *Bitcoind) Move(formAccount, toAccount string, amount float64, minconf u
 // SendFrom send amount from fromAccount to toAddress
 //  amount is a real and is rounded to 8 decimal places.
 //  Will send the given amount to the given address, ensuring the account has a valid balance using [minconf] confirmations.
+//  Labels do not own coins, so on nodes without account RPCs it fails with
+//  ErrSendFromUnsupported; use SendToAddress to send from the whole wallet.
 func (b *Bitcoind) SendFrom(fromAccount, toAddress string, amount float64, minconf uint32, comment, commentTo string) (txID string, err error) {
+	return b.sendFrom(fromAccount, toAddress, amount, minconf, comment, commentTo)
+}
+
+// SendFromSat sends an exact amount from fromAccount to toAddress, like
+// SendFrom
+func (b *Bitcoind) SendFromSat(fromAccount, toAddress string, amount Amount, minconf uint32, comment, commentTo string) (txID string, err error) {
+	return b.sendFrom(fromAccount, toAddress, amount, minconf, comment, commentTo)
+}
+
+// sendFrom calls "sendfrom" with a float64 or Amount amount
+func (b *Bitcoind) sendFrom(fromAccount, toAddress string, amount interface{}, minconf uint32, comment, commentTo string) (txID string, err error) {
 	r, err := b.client.call("sendfrom", []interface{}{fromAccount, toAddress, amount, minconf, comment, commentTo})
+	if accountsUnsupported(err, &r) {
+		err = ErrSendFromUnsupported
+		return
+	}
 	if err = handleError(err, &r); err != nil {
 		return
 	}
@@ -700,7 +928,7 @@ func (b *Bitcoind) SendManyReplaceable(fromAccount string, amounts map[string]fl
 
 	var r rpcResponse
 
-	if replaceable != nil {
+	if replaceable == nil {
 		r, err = b.client.call("sendmany", []interface{}{fromAccount, amounts, minconf, comment, feefrom})
 	} else {
 		r, err = b.client.call("sendmany", []interface{}{fromAccount, amounts, minconf, comment, feefrom, *replaceable})
@@ -714,6 +942,7 @@ func (b *Bitcoind) SendManyReplaceable(fromAccount string, amounts map[string]fl
 }
 
 // SendToAddress send an amount to a given address
+// Use SendToAddressWithOptions for fee and replaceability options.
 func (b *Bitcoind) SendToAddress(toAddress string, amount float64, comment, commentTo string) (txID string, err error) {
 	r, err := b.client.call("sendtoaddress", []interface{}{toAddress, amount, comment, commentTo})
 	if err = handleError(err, &r); err != nil {
@@ -724,8 +953,12 @@ func (b *Bitcoind) SendToAddress(toAddress string, amount float64, comment, comm
 }
 
 // SetAccount sets the account associated with the given address
+// On nodes without account RPCs, it sets the label of the address.
 func (b *Bitcoind) SetAccount(address, account string) error {
 	r, err := b.client.call("setaccount", []interface{}{address, account})
+	if accountsUnsupported(err, &r) {
+		return b.SetLabel(address, account)
+	}
 	return handleError(err, &r)
 }
 
@@ -770,10 +1003,25 @@ func (b *Bitcoind) VerifyMessage(address, sign, message string) (success bool, e
 
 // ValidateAddressResponse represents a response to "validateaddress" call
 type ValidateAddressResponse struct {
-	IsValid      bool   `json:"isvalid"`
-	Address      string `json:"address"`
-	IsMine       bool   `json:"ismine"`
+	IsValid bool   `json:"isvalid"`
+	Address string `json:"address"`
+	// The hex-encoded scriptPubKey generated by the address
+	ScriptPubKey string `json:"scriptPubKey,omitempty"`
 	IsScript     bool   `json:"isscript"`
+	// If the address is a witness address
+	IsWitness bool `json:"iswitness,omitempty"`
+	// The version number of the witness program, nil for non witness addresses
+	WitnessVersion *int `json:"witness_version,omitempty"`
+	// The hex value of the witness program, only for witness addresses
+	WitnessProgram string `json:"witness_program,omitempty"`
+	// Error message, if any, for invalid addresses
+	Error string `json:"error,omitempty"`
+	// Indices of likely error locations in the address, for invalid
+	// addresses
+	ErrorLocations []int `json:"error_locations,omitempty"`
+	// IsMine, PubKey, IsCompressed and Account are only returned by nodes
+	// before Core 0.18, see GetAddressInfo
+	IsMine       bool   `json:"ismine"`
 	PubKey       string `json:"pubkey"`
 	IsCompressed bool   `json:"iscompressed"`
 	Account      string `json:"account"`
@@ -819,7 +1067,7 @@ const (
 // EstimateSmartFeeResult result for call estimatesmartfee
 // https://bitcoincore.org/en/doc/0.16.0/rpc/util/estimatesmartfee/
 type EstimateSmartFeeResult struct {
-	FeeRate float64  `json:"feerate"`
+	FeeRate Amount   `json:"feerate"`
 	Errors  []string `json:"errors"`
 	Blocks  int      `json:"blocks"`
 }
@@ -1,6 +1,8 @@
 package bitcoind
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -20,6 +22,57 @@ func getNewTestServer(handler http.Handler) (testServer *httptest.Server, host s
 	return
 }
 
+// decodeParams returns the params of the RPC request received by a test server
+func decodeParams(r *http.Request) []interface{} {
+	_, params := decodeRequest(r)
+	return params
+}
+
+// decodeRequest returns the method and params of the RPC request received by
+// a test server
+func decodeRequest(r *http.Request) (method string, params []interface{}) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Fatalln(err)
+	}
+	params, _ = req.Params.([]interface{})
+	return req.Method, params
+}
+
+// methodNotFound is the response of bitcoind to an unknown RPC
+const methodNotFound = `{"result":null,"error":{"code":-32601,"message":"Method not found"},"id":1400502065079564568}`
+
+// newTestNode returns a client of a test server answering RPCs from results,
+// indexed by method, and with methodNotFound for the other methods. Received
+// calls are appended to calls. The methods of the optional failures return
+// the given RPC error.
+func newTestNode(results map[string]string, calls *[]string, failures ...map[string]RPCError) (*Bitcoind, func()) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, params := decodeRequest(r)
+		*calls = append(*calls, fmt.Sprintf("%s %v", method, params))
+		for _, failing := range failures {
+			if failure, ok := failing[method]; ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprintf(w, `{"result":null,"error":{"code":%d,"message":%q},"id":1400502065079564568}`+"\n", failure.Code, failure.Message)
+				return
+			}
+		}
+		result, ok := results[method]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintln(w, methodNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"result":%s,"error":null,"id":1400502065079564568}`+"\n", result)
+	})
+	ts, host, port, err := getNewTestServer(handler)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	bitcoindClient, _ := New(host, port, "x", "fake", false)
+	return bitcoindClient, ts.Close
+}
+
 var _ = Describe("Bitcoind", func() {
 	// We normaly just have to test calls that return data + err
 	// server error handling is already tested in helpers_tests
@@ -191,6 +244,90 @@ var _ = Describe("Bitcoind", func() {
 		})
 	})
 
+	Describe("Testing GetBlockheader", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"hash":"00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048","confirmations":815000,"height":1,"version":1,"versionHex":"00000001","merkleroot":"0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098","time":1231469665,"mediantime":1231469665,"nonce":2573394689,"bits":"1d00ffff","difficulty":1,"chainwork":"0000000000000000000000000000000000000000000000000000000200020002","nTx":1,"previousblockhash":"000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f","nextblockhash":"000000006a625f06636b8bb6ac7b960a8d03705d1ace08b1a19da3fdcc99ddbd"},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			header, err := bitcoindClient.GetBlockheader("00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return", func() {
+				Expect(header).Should(Equal(&BlockHeader{
+					Hash:              "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048",
+					Confirmations:     815000,
+					Height:            1,
+					Version:           1,
+					VersionHex:        "00000001",
+					Merkleroot:        "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098",
+					Time:              1231469665,
+					Mediantime:        1231469665,
+					Nonce:             2573394689,
+					Bits:              0x1d00ffff,
+					Difficulty:        1,
+					Chainwork:         "0000000000000000000000000000000000000000000000000000000200020002",
+					Txes:              1,
+					Previousblockhash: "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f",
+					Nextblockhash:     "000000006a625f06636b8bb6ac7b960a8d03705d1ace08b1a19da3fdcc99ddbd",
+				}))
+			})
+			It("should keep bits when encoded", func() {
+				data, err := json.Marshal(header)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(ContainSubstring(`"bits":"1d00ffff"`))
+				var decoded BlockHeader
+				Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+				Expect(&decoded).To(Equal(header))
+			})
+		})
+		Context("when result can't be decoded", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"hash":"00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048","bits":"zz"},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			header, err := bitcoindClient.GetBlockheader("00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048")
+			It("error should occured", func() {
+				Expect(err).To(HaveOccurred())
+			})
+			It("header should be nil", func() {
+				Expect(header).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Testing GetBlockHeaderRaw", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":"010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			raw, err := bitcoindClient.GetBlockHeaderRaw("00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return the serialized header", func() {
+				Expect(raw).To(Equal("010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299"))
+			})
+		})
+	})
+
 	Describe("Testing GetBestBlockhash", func() {
 		Context("when success", func() {
 			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -768,6 +905,157 @@ var _ = Describe("Bitcoind", func() {
 		})
 	})
 
+	Describe("Testing GetBlockVerbose", func() {
+		Context("when success with verbosity 3", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"hash":"3a1ab43b6a6ed4b4e1b0f66bb0c6b4ad6ee3be3e4b3c0b4e9e5e7db4f7b5fa2c","confirmations":1,"height":102,"version":536870912,"versionHex":"20000000","merkleroot":"8ad7fd2a3d5ab8d30a7ab1bcbd3f24bdbd2ab7a4bd90d96f5c25d7ef0c9b6d36","time":1700000000,"mediantime":1699999990,"nonce":1,"bits":"207fffff","difficulty":4.656542373906925e-10,"chainwork":"00000000000000000000000000000000000000000000000000000000000000ce","nTx":2,"previousblockhash":"5f0a1b7ac1f22d66f9f28e4e3bbcb8c21a2b3a0c9ba2c2f5dc8d5b50c7e6a2d1","strippedsize":418,"size":454,"weight":1708,"tx":[{"txid":"b1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","hash":"c1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","version":2,"size":168,"vsize":141,"weight":564,"locktime":0,"vin":[{"coinbase":"016600","txinwitness":["0000000000000000000000000000000000000000000000000000000000000000"],"sequence":4294967295}],"vout":[{"value":50.00001000,"n":0,"scriptPubKey":{"asm":"0 751e76e8199196d454941c45d1b3a323f1433bd6","hex":"0014751e76e8199196d454941c45d1b3a323f1433bd6","address":"bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080","type":"witness_v0_keyhash"}}],"hex":"02000000"},{"txid":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","hash":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","version":2,"size":191,"vsize":191,"weight":764,"locktime":101,"vin":[{"txid":"e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","vout":0,"scriptSig":{"asm":"","hex":""},"prevout":{"generated":true,"height":1,"value":50.00000000,"scriptPubKey":{"asm":"0 751e76e8199196d454941c45d1b3a323f1433bd6","hex":"0014751e76e8199196d454941c45d1b3a323f1433bd6","address":"bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080","type":"witness_v0_keyhash"}},"sequence":4294967293}],"vout":[{"value":49.99999000,"n":0,"scriptPubKey":{"asm":"0 751e76e8199196d454941c45d1b3a323f1433bd6","hex":"0014751e76e8199196d454941c45d1b3a323f1433bd6","address":"bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080","type":"witness_v0_keyhash"}}],"fee":0.00001000,"hex":"02000000"}]},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			block, err := bitcoindClient.GetBlockVerbose("3a1ab43b6a6ed4b4e1b0f66bb0c6b4ad6ee3be3e4b3c0b4e9e5e7db4f7b5fa2c", 3)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should decode the header fields", func() {
+				Expect(block.Hash).To(Equal("3a1ab43b6a6ed4b4e1b0f66bb0c6b4ad6ee3be3e4b3c0b4e9e5e7db4f7b5fa2c"))
+				Expect(block.VersionHex).To(Equal("20000000"))
+				Expect(block.Mediantime).To(Equal(int64(1699999990)))
+				Expect(block.StrippedSize).To(Equal(uint64(418)))
+				Expect(block.Weight).To(Equal(uint64(1708)))
+				Expect(block.NTx).To(Equal(uint64(2)))
+			})
+			It("should decode the transactions", func() {
+				Expect(block.Tx).To(HaveLen(2))
+				Expect(block.Tx[0].Vin[0].Coinbase).To(Equal("016600"))
+				Expect(block.Tx[0].Vin[0].Prevout).To(BeNil())
+				Expect(block.Tx[1].Fee).To(Equal(Amount(1000)))
+				Expect(block.Tx[1].Vsize).To(Equal(uint64(191)))
+			})
+			It("should decode the prevout of the inputs", func() {
+				Expect(block.Tx[1].Vin[0].Prevout).To(Equal(&Prevout{
+					Generated: true,
+					Height:    1,
+					Value:     5000000000,
+					ScriptPubKey: ScriptPubKey{
+						Asm:     "0 751e76e8199196d454941c45d1b3a323f1433bd6",
+						Hex:     "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+						Type:    "witness_v0_keyhash",
+						Address: "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080",
+					},
+				}))
+			})
+		})
+		Context("when verbosity is invalid", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.GetBlockVerbose("3a1ab43b6a6ed4b4e1b0f66bb0c6b4ad6ee3be3e4b3c0b4e9e5e7db4f7b5fa2c", 1)
+			It("error should occured", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Testing GetBlockStream", func() {
+		blockJSON := `{"result":{"hash":"3a1ab43b6a6ed4b4e1b0f66bb0c6b4ad6ee3be3e4b3c0b4e9e5e7db4f7b5fa2c","confirmations":1,"height":102,"version":536870912,"versionHex":"20000000","merkleroot":"8ad7fd2a3d5ab8d30a7ab1bcbd3f24bdbd2ab7a4bd90d96f5c25d7ef0c9b6d36","time":1700000000,"mediantime":1699999990,"nonce":1,"bits":"207fffff","difficulty":4.656542373906925e-10,"nTx":2,"previousblockhash":"5f0a1b7ac1f22d66f9f28e4e3bbcb8c21a2b3a0c9ba2c2f5dc8d5b50c7e6a2d1","strippedsize":418,"size":454,"weight":1708,"tx":[{"txid":"b1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","version":2,"locktime":0,"vin":[{"coinbase":"016600","sequence":4294967295}],"vout":[{"value":50.00001000,"n":0,"scriptPubKey":{"asm":"","hex":"51","type":"nonstandard"}}],"hex":"02000000"},{"txid":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","version":2,"locktime":101,"vin":[{"txid":"e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","vout":0,"scriptSig":{"asm":"","hex":""},"sequence":4294967293}],"vout":[{"value":49.99999000,"n":0,"scriptPubKey":{"asm":"","hex":"51","type":"nonstandard"}}],"fee":0.00001000,"hex":"02000000"}]},"error":null,"id":1400502065079564568}`
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, blockJSON)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			var txids []string
+			block, err := bitcoindClient.GetBlockStream("3a1ab43b6a6ed4b4e1b0f66bb0c6b4ad6ee3be3e4b3c0b4e9e5e7db4f7b5fa2c", 2, func(tx RawTransaction) error {
+				txids = append(txids, tx.Txid)
+				return nil
+			})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should yield every transaction in order", func() {
+				Expect(txids).To(Equal([]string{
+					"b1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+					"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+				}))
+			})
+			It("should return the header without transactions", func() {
+				Expect(block.Hash).To(Equal("3a1ab43b6a6ed4b4e1b0f66bb0c6b4ad6ee3be3e4b3c0b4e9e5e7db4f7b5fa2c"))
+				Expect(block.Previousblockhash).To(Equal("5f0a1b7ac1f22d66f9f28e4e3bbcb8c21a2b3a0c9ba2c2f5dc8d5b50c7e6a2d1"))
+				Expect(block.NTx).To(Equal(uint64(2)))
+				Expect(block.Tx).To(BeNil())
+			})
+		})
+		Context("when the callback fails", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, blockJSON)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			calls := 0
+			_, err = bitcoindClient.GetBlockStream("3a1ab43b6a6ed4b4e1b0f66bb0c6b4ad6ee3be3e4b3c0b4e9e5e7db4f7b5fa2c", 2, func(tx RawTransaction) error {
+				calls++
+				return errors.New("stop")
+			})
+			It("should return the callback error", func() {
+				Expect(err).Should(MatchError("stop"))
+			})
+			It("should stop after the first transaction", func() {
+				Expect(calls).To(Equal(1))
+			})
+		})
+		Context("when error from server", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":null,"error":{"code":-5,"message":"Block not found"},"id":1400425780999713481}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			_, err = bitcoindClient.GetBlockStream("00", 2, func(tx RawTransaction) error {
+				return nil
+			})
+			It("error should be the RPC error", func() {
+				Expect(err).Should(MatchError("-5: Block not found"))
+			})
+		})
+		Context("when the result is null", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":null,"error":null,"id":1400425780999713481}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			_, err = bitcoindClient.GetBlockStream("00", 2, func(tx RawTransaction) error {
+				return nil
+			})
+			It("should error", func() {
+				Expect(err).Should(MatchError("No block returned by getblock"))
+			})
+		})
+		Context("when fn is nil", func() {
+			bitcoindClient, _ := New("127.0.0.1", 8334, "x", "fake", false)
+			_, err := bitcoindClient.GetBlockStream("00", 2, nil)
+			It("should error", func() {
+				Expect(err).Should(MatchError("Bad parameters for GetBlockStream: fn must be set"))
+			})
+		})
+	})
+
 	Describe("Testing GetBlockCount", func() {
 		Context("when success", func() {
 			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -927,7 +1215,7 @@ var _ = Describe("Bitcoind", func() {
 					KeypoolSize:     101,
 					UnlockedUntil:   1400519823,
 					Paytxfee:        0,
-					Relayfee:        1e-05,
+					Relayfee:        1000,
 					Errors:          "This is a pre-release test build - use at your own risk - do not use for mining or merchant applications",
 				}))
 			})
@@ -1064,6 +1352,50 @@ var _ = Describe("Bitcoind", func() {
 					}}))
 			})
 		})
+		Context("when the node is recent", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getpeerinfo": `[{"id":3,"addr":"[2001:db8::1]:8333","addrbind":"[2001:db8::2]:51234","network":"ipv6","services":"0000000000000c09","servicesnames":["NETWORK","WITNESS","NETWORK_LIMITED","P2P_V2"],"relaytxes":true,"lastsend":1700000100,"lastrecv":1700000101,"last_transaction":1700000090,"last_block":1699999000,"bytessent":123456,"bytesrecv":654321,"conntime":1699990000,"timeoffset":0,"pingtime":0.052,"minping":0.041,"version":70016,"subver":"/Satoshi:27.1.0/","inbound":false,"bip152_hb_to":false,"bip152_hb_from":true,"startingheight":820000,"presynced_headers":-1,"synced_headers":820100,"synced_blocks":820099,"inflight":[820100],"addr_relay_enabled":true,"addr_processed":1203,"addr_rate_limited":12,"permissions":["noban","relay"],"minfeefilter":0.00001000,"bytessent_per_msg":{"ping":512,"tx":20480},"bytesrecv_per_msg":{"block":600000,"pong":512},"connection_type":"outbound-full-relay","transport_protocol_type":"v2","session_id":"a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"}]`,
+			}, &calls)
+			defer done()
+			peerInfo, err := bitcoindClient.GetPeerInfo()
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(peerInfo).To(HaveLen(1))
+			})
+			It("should return the modern fields", func() {
+				peer := peerInfo[0]
+				Expect(peer.ID).To(Equal(3))
+				Expect(peer.Network).To(Equal(NetworkIPv6))
+				Expect(peer.ConnectionType).To(Equal(ConnectionOutboundFullRelay))
+				Expect(peer.TransportProtocolType).To(Equal("v2"))
+				Expect(peer.SessionID).To(HaveLen(64))
+				Expect(peer.Minping).To(Equal(0.041))
+				Expect(peer.PresyncedHeaders).To(Equal(int64(-1)))
+				Expect(peer.SyncedHeaders).To(Equal(int64(820100)))
+				Expect(peer.SyncedBlocks).To(Equal(int64(820099)))
+				Expect(peer.Inflight).To(Equal([]int64{820100}))
+				Expect(peer.Relaytxes).To(BeTrue())
+				Expect(peer.Minfeefilter).To(Equal(Amount(1000)))
+				Expect(peer.BytessentPerMsg).To(Equal(map[string]uint64{"ping": 512, "tx": 20480}))
+				Expect(peer.BytesrecvPerMsg).To(HaveKeyWithValue("block", uint64(600000)))
+				Expect(peer.AddrRelayEnabled).To(BeTrue())
+				Expect(peer.AddrProcessed).To(Equal(uint64(1203)))
+				Expect(peer.AddrRateLimited).To(Equal(uint64(12)))
+				Expect(peer.Bip152HbFrom).To(BeTrue())
+			})
+			It("should return the permissions", func() {
+				Expect(peerInfo[0].Permissions).To(Equal([]string{"noban", "relay"}))
+				Expect(peerInfo[0].HasPermission("noban")).To(BeTrue())
+				Expect(peerInfo[0].HasPermission("download")).To(BeFalse())
+			})
+			It("should derive the connection type of older nodes", func() {
+				Expect(peerInfo[0].Connection()).To(Equal(ConnectionOutboundFullRelay))
+				Expect(Peer{Inbound: true}.Connection()).To(Equal(ConnectionInbound))
+				Expect(Peer{Addnode: true}.Connection()).To(Equal(ConnectionManual))
+				Expect(Peer{}.Connection()).To(Equal(ConnectionOutboundFullRelay))
+			})
+		})
 	})
 
 	Describe("Testing GetRawChangeAddress", func() {
@@ -1162,7 +1494,7 @@ var _ = Describe("Bitcoind", func() {
 						}},
 					Vout: []Vout{
 						{
-							Value: 0.0101,
+							Value: 1010000,
 							N:     0,
 							ScriptPubKey: ScriptPubKey{
 								Asm:       "OP_DUP OP_HASH160 e5344f52ecc92c279028a851c9d8ed57bb5dfc60 OP_EQUALVERIFY OP_CHECKSIG",
@@ -1173,7 +1505,7 @@ var _ = Describe("Bitcoind", func() {
 							},
 						},
 						{
-							Value: 0.01492249,
+							Value: 1492249,
 							N:     1,
 							ScriptPubKey: ScriptPubKey{
 								Asm:       "OP_DUP OP_HASH160 3399e4655281f5dbb3e157930c724dd3e748a420 OP_EQUALVERIFY OP_CHECKSIG",
@@ -1255,7 +1587,7 @@ var _ = Describe("Bitcoind", func() {
 
 			It("should return Transaction", func() {
 				Expect(transaction).Should(Equal(Transaction{
-					Amount:          0.0001,
+					Amount:          10000,
 					Account:         "",
 					Address:         "",
 					Category:        "",
@@ -1273,7 +1605,7 @@ var _ = Describe("Bitcoind", func() {
 							Account:  "tests",
 							Address:  "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy",
 							Category: "receive",
-							Amount:   0.0001,
+							Amount:   10000,
 							Fee:      0,
 						},
 					},
@@ -1301,7 +1633,7 @@ var _ = Describe("Bitcoind", func() {
 
 			It("should return Transaction", func() {
 				Expect(transaction).Should(Equal(Transaction{
-					Amount:          0.0001,
+					Amount:          10000,
 					Account:         "",
 					Address:         "",
 					Category:        "",
@@ -1319,7 +1651,7 @@ var _ = Describe("Bitcoind", func() {
 							Account:  "tests",
 							Address:  "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy",
 							Category: "receive",
-							Amount:   0.0001,
+							Amount:   10000,
 							Fee:      0,
 							Label:    "some-detail",
 						},
@@ -1350,7 +1682,7 @@ var _ = Describe("Bitcoind", func() {
 				Expect(uTxOut).Should(Equal(UTransactionOut{
 					Bestblock:     "00000000000000005fc5487bb67b58573eef3ba369972f6acfc5240cf375878f",
 					Confirmations: 7,
-					Value:         0.0001,
+					Value:         10000,
 					ScriptPubKey: ScriptPubKey{
 						Asm:       "OP_DUP OP_HASH160 fc0d1e43cea1c5df928971f8add5d67ce4313003 OP_EQUALVERIFY OP_CHECKSIG",
 						Hex:       "76a914fc0d1e43cea1c5df928971f8add5d67ce431300388ac",
@@ -1389,7 +1721,7 @@ var _ = Describe("Bitcoind", func() {
 					TxOuts:          1.1028067e+07,
 					BytesSerialized: 3.82233349e+08,
 					HashSerialized:  "6aa4a70a010a7ac8e41e335007ee2f7cfb81db2bd1093bc27663aed55e6fc001",
-					TotalAmount:     1.279763979102867e+07,
+					TotalAmount:     1279763979102867,
 				}))
 			})
 		})
@@ -1522,12 +1854,12 @@ var _ = Describe("Bitcoind", func() {
 				Expect(list).Should(Equal([]ListAddressResult{
 					{
 						Address: "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy",
-						Amount:  0.0002,
+						Amount:  20000,
 						Account: "tests",
 					},
 					{
 						Address: "114fREEjA8XZUypygprUSbrynsUrr4TKjz",
-						Amount:  0.0001,
+						Amount:  10000,
 						Account: "test2",
 					},
 					{
@@ -1563,7 +1895,7 @@ var _ = Describe("Bitcoind", func() {
 						Confirmations: 0,
 					}, {
 						Account:       "tests",
-						Amount:        0.0002,
+						Amount:        20000,
 						Confirmations: 12,
 					},
 				}))
@@ -1591,7 +1923,7 @@ var _ = Describe("Bitcoind", func() {
 					{
 						Address:       "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy",
 						Account:       "tests",
-						Amount:        0.00020000,
+						Amount:        20000,
 						Confirmations: 13,
 						TxIds:         []string{"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515", "eb1c979a968f724f6114c2cce579bd2cac599c154870dae4fdd669319d332346"},
 					}, {
@@ -1624,7 +1956,7 @@ var _ = Describe("Bitcoind", func() {
 			It("sould return a slice of Transaction", func() {
 				Expect(transactions).Should(Equal([]Transaction{
 					{
-						Amount:          0.0002,
+						Amount:          20000,
 						Account:         "test2",
 						Address:         "1Bwq28f3eE1Aa3eKsc9ma2o7KX8S6PnHTK",
 						Category:        "receive",
@@ -1641,7 +1973,7 @@ var _ = Describe("Bitcoind", func() {
 						Hex:             "",
 					},
 					{
-						Amount:          0.0001,
+						Amount:          10000,
 						Account:         "test2",
 						Address:         "114fREEjA8XZUypygprUSbrynsUrr4TKjz",
 						Category:        "receive",
@@ -1680,7 +2012,7 @@ var _ = Describe("Bitcoind", func() {
 			It("should return a slice of transactions", func() {
 				Expect(transactions).Should(Equal([]Transaction{
 					{
-						Amount:          0.0001,
+						Amount:          10000,
 						Account:         "tests",
 						Address:         "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy",
 						Category:        "receive",
@@ -1697,7 +2029,7 @@ var _ = Describe("Bitcoind", func() {
 						Hex:             "",
 					},
 					{
-						Amount:          0.0001,
+						Amount:          10000,
 						Account:         "tests",
 						Address:         "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy",
 						Category:        "receive",
@@ -1736,7 +2068,7 @@ var _ = Describe("Bitcoind", func() {
 			It("should return a transaction ID ", func() {
 				Expect(transactions).Should(Equal([]Transaction{
 					{
-						Amount:          0.0001,
+						Amount:          10000,
 						Account:         "test2",
 						Address:         "114fREEjA8XZUypygprUSbrynsUrr4TKjz",
 						Category:        "",
@@ -1753,7 +2085,7 @@ var _ = Describe("Bitcoind", func() {
 						Hex:             "",
 					},
 					{
-						Amount:          0.0001,
+						Amount:          10000,
 						Account:         "tests",
 						Address:         "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy",
 						Category:        "",
@@ -2035,6 +2367,53 @@ var _ = Describe("Bitcoind", func() {
 				}))
 			})
 		})
+		Context("when success with a current node", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"isvalid":true,"address":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4","scriptPubKey":"0014751e76e8199196d454941c45d1b3a323f1433bd6","isscript":false,"iswitness":true,"witness_version":0,"witness_program":"751e76e8199196d454941c45d1b3a323f1433bd6"},"error":null,"id":1401119296578850111}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			resp, err := bitcoindClient.ValidateAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return the witness fields", func() {
+				witnessVersion := 0
+				Expect(resp).Should(Equal(ValidateAddressResponse{
+					IsValid:        true,
+					Address:        "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+					ScriptPubKey:   "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+					IsWitness:      true,
+					WitnessVersion: &witnessVersion,
+					WitnessProgram: "751e76e8199196d454941c45d1b3a323f1433bd6",
+				}))
+			})
+		})
+		Context("when the address is invalid", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"isvalid":false,"error_locations":[41],"error":"Invalid Bech32 checksum"},"error":null,"id":1401119296578850111}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			resp, err := bitcoindClient.ValidateAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return the error locations", func() {
+				Expect(resp).Should(Equal(ValidateAddressResponse{
+					Error:          "Invalid Bech32 checksum",
+					ErrorLocations: []int{41},
+				}))
+			})
+		})
 	})
 
 	Describe("Testing VerifyMessage", func() {
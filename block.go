@@ -1,5 +1,11 @@
 package bitcoind
 
+import (
+	"math/big"
+
+	"github.com/toorop/go-bitcoind/pow"
+)
+
 // Represents a block
 type Block struct {
 	// The block hash
@@ -11,12 +17,21 @@ type Block struct {
 	// The block size
 	Size uint64 `json:"size"`
 
+	// The block size excluding witness data
+	StrippedSize uint64 `json:"strippedsize,omitempty"`
+
+	// The block weight as defined in BIP 141
+	Weight uint64 `json:"weight,omitempty"`
+
 	// The block height or index
 	Height uint64 `json:"height"`
 
 	// The block version
 	Version uint32 `json:"version"`
 
+	// The block version formatted in hexadecimal
+	VersionHex string `json:"versionHex,omitempty"`
+
 	// The merkle root
 	Merkleroot string `json:"merkleroot"`
 
@@ -26,6 +41,9 @@ type Block struct {
 	// The block time in seconds since epoch (Jan 1 1970 GMT)
 	Time int64 `json:"time"`
 
+	// The median block time in seconds since epoch (Jan 1 1970 GMT)
+	Mediantime int64 `json:"mediantime,omitempty"`
+
 	// The nonce
 	Nonce uint64 `json:"nonce"`
 
@@ -38,9 +56,88 @@ type Block struct {
 	// Total amount of work in active chain, in hexadecimal
 	Chainwork string `json:"chainwork,omitempty"`
 
+	// The number of transactions in the block
+	NTx uint64 `json:"nTx,omitempty"`
+
 	// The hash of the previous block
 	Previousblockhash string `json:"previousblockhash"`
 
 	// The hash of the next block
 	Nextblockhash string `json:"nextblockhash"`
 }
+
+// field returns a pointer to the header field decoded from the getblock key,
+// or nil if the key is not a header field
+func (b *Block) field(key string) interface{} {
+	switch key {
+	case "hash":
+		return &b.Hash
+	case "confirmations":
+		return &b.Confirmations
+	case "size":
+		return &b.Size
+	case "strippedsize":
+		return &b.StrippedSize
+	case "weight":
+		return &b.Weight
+	case "height":
+		return &b.Height
+	case "version":
+		return &b.Version
+	case "versionHex":
+		return &b.VersionHex
+	case "merkleroot":
+		return &b.Merkleroot
+	case "time":
+		return &b.Time
+	case "mediantime":
+		return &b.Mediantime
+	case "nonce":
+		return &b.Nonce
+	case "bits":
+		return &b.Bits
+	case "difficulty":
+		return &b.Difficulty
+	case "chainwork":
+		return &b.Chainwork
+	case "nTx":
+		return &b.NTx
+	case "previousblockhash":
+		return &b.Previousblockhash
+	case "nextblockhash":
+		return &b.Nextblockhash
+	}
+	return nil
+}
+
+// BlockVerbose represents a block returned by getblock with verbosity 2 or 3,
+// where transactions are fully decoded instead of listed by txid.
+type BlockVerbose struct {
+	Block
+
+	// The decoded transactions of the block
+	Tx []RawTransaction `json:"tx"`
+}
+
+// Target returns the target the block hash must be below, decoded from Bits
+func (b Block) Target() (*big.Int, error) {
+	bits, err := pow.ParseCompact(b.Bits)
+	if err != nil {
+		return nil, err
+	}
+	return pow.CompactToBig(bits)
+}
+
+// Work returns the expected number of hashes needed to produce the block
+func (b Block) Work() (*big.Int, error) {
+	target, err := b.Target()
+	if err != nil {
+		return nil, err
+	}
+	return pow.Work(target), nil
+}
+
+// ChainworkInt returns the total amount of work in the chain up to the block
+func (b Block) ChainworkInt() (*big.Int, error) {
+	return pow.ParseChainwork(b.Chainwork)
+}
@@ -0,0 +1,238 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// BlockStats represents a response to "getblockstats" call.
+// Fees are expressed in satoshis, fee rates in satoshis per virtual byte.
+type BlockStats struct {
+	// Average fee in the block
+	AvgFee int64 `json:"avgfee"`
+
+	// Average feerate
+	AvgFeeRate int64 `json:"avgfeerate"`
+
+	// Average transaction size
+	AvgTxSize int64 `json:"avgtxsize"`
+
+	// The block hash
+	BlockHash string `json:"blockhash"`
+
+	// Feerates at the 10th, 25th, 50th, 75th, and 90th percentile weight unit
+	FeeRatePercentiles []int64 `json:"feerate_percentiles"`
+
+	// The height of the block
+	Height uint64 `json:"height"`
+
+	// The number of inputs (excluding coinbase)
+	Ins uint64 `json:"ins"`
+
+	// Maximum fee in the block
+	MaxFee int64 `json:"maxfee"`
+
+	// Maximum feerate
+	MaxFeeRate int64 `json:"maxfeerate"`
+
+	// Maximum transaction size
+	MaxTxSize int64 `json:"maxtxsize"`
+
+	// Truncated median fee in the block
+	MedianFee int64 `json:"medianfee"`
+
+	// The block median time past
+	MedianTime int64 `json:"mediantime"`
+
+	// Truncated median transaction size
+	MedianTxSize int64 `json:"mediantxsize"`
+
+	// Minimum fee in the block
+	MinFee int64 `json:"minfee"`
+
+	// Minimum feerate
+	MinFeeRate int64 `json:"minfeerate"`
+
+	// Minimum transaction size
+	MinTxSize int64 `json:"mintxsize"`
+
+	// The number of outputs
+	Outs uint64 `json:"outs"`
+
+	// The block subsidy
+	Subsidy int64 `json:"subsidy"`
+
+	// Total size of all segwit transactions
+	SwTotalSize uint64 `json:"swtotal_size"`
+
+	// Total weight of all segwit transactions
+	SwTotalWeight uint64 `json:"swtotal_weight"`
+
+	// The number of segwit transactions
+	SwTxs uint64 `json:"swtxs"`
+
+	// The block time
+	Time int64 `json:"time"`
+
+	// Total amount in all outputs (excluding coinbase)
+	TotalOut int64 `json:"total_out"`
+
+	// Total size of all non-coinbase transactions
+	TotalSize uint64 `json:"total_size"`
+
+	// Total weight of all non-coinbase transactions
+	TotalWeight uint64 `json:"total_weight"`
+
+	// The fee total
+	TotalFee int64 `json:"totalfee"`
+
+	// The number of transactions (including coinbase)
+	Txs uint64 `json:"txs"`
+
+	// The increase/decrease in the number of unspent outputs
+	UtxoIncrease int64 `json:"utxo_increase"`
+
+	// The increase/decrease in size for the utxo index
+	UtxoSizeInc int64 `json:"utxo_size_inc"`
+
+	// The increase/decrease in the number of unspent outputs, not counting
+	// unspendables
+	UtxoIncreaseActual int64 `json:"utxo_increase_actual"`
+
+	// The increase/decrease in size for the utxo index, not counting
+	// unspendables
+	UtxoSizeIncActual int64 `json:"utxo_size_inc_actual"`
+}
+
+// GetBlockStats computes per block statistics for the block with the given
+// hash (string) or height (integer).
+// If [stats] is given, only those values are computed and the other fields
+// are left empty.
+func (b *Bitcoind) GetBlockStats(hashOrHeight interface{}, stats ...string) (blockStats BlockStats, err error) {
+	switch hashOrHeight.(type) {
+	case string, int, int32, int64, uint, uint32, uint64:
+	default:
+		err = errors.New("Bad parameters for GetBlockStats: hashOrHeight must be a string or an integer")
+		return
+	}
+	params := []interface{}{hashOrHeight}
+	if len(stats) > 0 {
+		params = append(params, stats)
+	}
+	r, err := b.client.call("getblockstats", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &blockStats)
+	return
+}
+
+// maxBlockStatsRange is the largest number of blocks GetBlockStatsRange
+// fetches in one call, as the results are held in memory
+const maxBlockStatsRange = 100000
+
+// GetBlockStatsRange returns the statistics of the blocks from height [start]
+// to height [end] (inclusive), ordered by height. The range is limited to
+// 100000 blocks.
+// Calls are made concurrently by at most [workers] goroutines.
+func (b *Bitcoind) GetBlockStatsRange(start, end uint64, workers int, stats ...string) ([]BlockStats, error) {
+	if end < start {
+		return nil, errors.New("Bad parameters for GetBlockStatsRange: end is lower than start")
+	}
+	if end-start >= maxBlockStatsRange {
+		return nil, fmt.Errorf("Bad parameters for GetBlockStatsRange: the range must not exceed %d blocks", maxBlockStatsRange)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]BlockStats, end-start+1)
+	heights := make(chan uint64)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for height := range heights {
+				blockStats, err := b.GetBlockStats(height, stats...)
+				if err != nil {
+					errs <- err
+					return
+				}
+				results[height-start] = blockStats
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for height := start; height <= end; height++ {
+		select {
+		case heights <- height:
+		case err = <-errs:
+			break feed
+		}
+	}
+	close(heights)
+	wg.Wait()
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ChainTxStats represents a response to "getchaintxstats" call
+type ChainTxStats struct {
+	// The timestamp for the final block in the window
+	Time int64 `json:"time"`
+
+	// The total number of transactions in the chain up to that point
+	TxCount uint64 `json:"txcount"`
+
+	// The hash of the final block in the window
+	WindowFinalBlockHash string `json:"window_final_block_hash"`
+
+	// The height of the final block in the window
+	WindowFinalBlockHeight uint64 `json:"window_final_block_height"`
+
+	// Size of the window in number of blocks
+	WindowBlockCount uint64 `json:"window_block_count"`
+
+	// The number of transactions in the window
+	WindowTxCount uint64 `json:"window_tx_count,omitempty"`
+
+	// The elapsed time in the window in seconds
+	WindowInterval int64 `json:"window_interval,omitempty"`
+
+	// The average rate of transactions per second in the window
+	TxRate float64 `json:"txrate,omitempty"`
+}
+
+// GetChainTxStats computes statistics about the total number and rate of
+// transactions in the chain, over a window of [nBlocks] blocks ending at
+// [blockHash].
+// If [nBlocks] is 0 the node default (one month) is used, if [blockHash] is
+// "" the window ends at the chain tip.
+func (b *Bitcoind) GetChainTxStats(nBlocks uint32, blockHash string) (stats ChainTxStats, err error) {
+	params := []interface{}{nil}
+	if nBlocks > 0 {
+		params[0] = nBlocks
+	}
+	if blockHash != "" {
+		params = append(params, blockHash)
+	}
+	r, err := b.client.call("getchaintxstats", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &stats)
+	return
+}
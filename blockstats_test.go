@@ -0,0 +1,166 @@
+package bitcoind
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BlockStats", func() {
+	Describe("Testing GetBlockStats", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"avgfee":4432,"avgfeerate":19,"avgtxsize":359,"blockhash":"00000000000000000001b5b3c0b8d6c8d1b8b5f7f5e6a7f8b9c0d1e2f3a4b5c6","feerate_percentiles":[10,12,15,22,40],"height":810000,"ins":7510,"maxfee":1480000,"maxfeerate":503,"maxtxsize":98000,"medianfee":2800,"mediantime":1695000000,"mediantxsize":222,"minfee":141,"minfeerate":1,"mintxsize":150,"outs":9120,"subsidy":625000000,"swtotal_size":1200000,"swtotal_weight":3400000,"swtxs":3100,"time":1695000600,"total_out":1234567890,"total_size":1480000,"total_weight":3990000,"totalfee":14000000,"txs":3159,"utxo_increase":1610,"utxo_size_inc":120000,"utxo_increase_actual":1600,"utxo_size_inc_actual":119000},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			stats, err := bitcoindClient.GetBlockStats(810000)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the height only", func() {
+				Expect(params).To(Equal([]interface{}{float64(810000)}))
+			})
+			It("should return", func() {
+				Expect(stats.AvgFee).To(Equal(int64(4432)))
+				Expect(stats.FeeRatePercentiles).To(Equal([]int64{10, 12, 15, 22, 40}))
+				Expect(stats.TotalWeight).To(Equal(uint64(3990000)))
+				Expect(stats.UtxoIncrease).To(Equal(int64(1610)))
+				Expect(stats.UtxoSizeIncActual).To(Equal(int64(119000)))
+			})
+		})
+		Context("when stats are selected", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"avgfee":4432,"height":810000},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			stats, err := bitcoindClient.GetBlockStats("00000000000000000001b5b3c0b8d6c8d1b8b5f7f5e6a7f8b9c0d1e2f3a4b5c6", "avgfee", "height")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the hash and the stats", func() {
+				Expect(params).To(Equal([]interface{}{"00000000000000000001b5b3c0b8d6c8d1b8b5f7f5e6a7f8b9c0d1e2f3a4b5c6", []interface{}{"avgfee", "height"}}))
+			})
+			It("should return the selected stats", func() {
+				Expect(stats).To(Equal(BlockStats{AvgFee: 4432, Height: 810000}))
+			})
+		})
+		Context("when hashOrHeight has a bad type", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.GetBlockStats(1.5)
+			It("error should occured", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Testing GetBlockStatsRange", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				height := decodeParams(r)[0].(float64)
+				fmt.Fprintf(w, `{"result":{"height":%d,"txs":%d},"error":null,"id":1400502065079564568}`+"\n", int(height), int(height)*2)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			stats, err := bitcoindClient.GetBlockStatsRange(100, 119, 4, "height", "txs")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return the stats ordered by height", func() {
+				Expect(stats).To(HaveLen(20))
+				for i, s := range stats {
+					Expect(s.Height).To(Equal(uint64(100 + i)))
+					Expect(s.Txs).To(Equal(uint64(200 + 2*i)))
+				}
+			})
+		})
+		Context("when a call fails", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if decodeParams(r)[0].(float64) == 105 {
+					fmt.Fprintln(w, `{"result":null,"error":{"code":-8,"message":"Target block height 105 after current tip 104"},"id":1400502065079564568}`)
+					return
+				}
+				fmt.Fprintln(w, `{"result":{"height":1},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			stats, err := bitcoindClient.GetBlockStatsRange(100, 119, 3)
+			It("error should be the RPC error", func() {
+				Expect(err).Should(MatchError("-8: Target block height 105 after current tip 104"))
+			})
+			It("stats should be nil", func() {
+				Expect(stats).To(BeNil())
+			})
+		})
+		Context("when the range is too large", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			stats, err := bitcoindClient.GetBlockStatsRange(0, math.MaxUint64, 4)
+			It("error should occured", func() {
+				Expect(err).Should(MatchError("Bad parameters for GetBlockStatsRange: the range must not exceed 100000 blocks"))
+			})
+			It("stats should be nil", func() {
+				Expect(stats).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Testing GetChainTxStats", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"time":1695000600,"txcount":889012345,"window_final_block_hash":"00000000000000000001b5b3c0b8d6c8d1b8b5f7f5e6a7f8b9c0d1e2f3a4b5c6","window_final_block_height":810000,"window_block_count":144,"window_tx_count":480000,"window_interval":86400,"txrate":5.555555555555555},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			stats, err := bitcoindClient.GetChainTxStats(144, "")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the window size only", func() {
+				Expect(params).To(Equal([]interface{}{float64(144)}))
+			})
+			It("should return", func() {
+				Expect(stats).To(Equal(ChainTxStats{
+					Time:                   1695000600,
+					TxCount:                889012345,
+					WindowFinalBlockHash:   "00000000000000000001b5b3c0b8d6c8d1b8b5f7f5e6a7f8b9c0d1e2f3a4b5c6",
+					WindowFinalBlockHeight: 810000,
+					WindowBlockCount:       144,
+					WindowTxCount:          480000,
+					WindowInterval:         86400,
+					TxRate:                 5.555555555555555,
+				}))
+			})
+		})
+	})
+})
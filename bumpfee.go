@@ -0,0 +1,207 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// rpcInvalidAddressOrKey is the error code of bitcoind for an unknown
+// transaction or address
+const rpcInvalidAddressOrKey RPCErrorCode = -5
+
+// BumpFeeOptions represents the options of "bumpfee" and "psbtbumpfee"
+// calls, nil and zero fields are left to the node defaults
+type BumpFeeOptions struct {
+	// Confirmation target in blocks
+	ConfTarget *int `json:"conf_target,omitempty"`
+	// Fee rate in sat/vB
+	FeeRate *float64 `json:"fee_rate,omitempty"`
+	// Whether the new transaction should still be BIP 125 replaceable
+	Replaceable *bool `json:"replaceable,omitempty"`
+	// The fee estimate mode, one of the EstimateMode constants
+	EstimateMode string `json:"estimate_mode,omitempty"`
+	// New outputs replacing the ones of the original transaction, which
+	// must then have a single output to the wallet or none
+	Outputs []TxOutput `json:"outputs,omitempty"`
+	// The index of the change output to recycle from the original
+	// transaction
+	OriginalChangeIndex *int `json:"original_change_index,omitempty"`
+}
+
+// BumpFeeResult represents a response to "bumpfee" and "psbtbumpfee" calls
+type BumpFeeResult struct {
+	// The id of the new transaction, only returned by "bumpfee"
+	TxID string `json:"txid,omitempty"`
+	// The base64-encoded unsigned PSBT of the new transaction, only
+	// returned by "psbtbumpfee"
+	Psbt string `json:"psbt,omitempty"`
+	// The fee of the replaced transaction
+	OrigFee Amount `json:"origfee"`
+	// The fee of the new transaction
+	Fee Amount `json:"fee"`
+	// Errors encountered during processing, may be empty
+	Errors []string `json:"errors"`
+}
+
+// FeeBumpStrategy is the way to speed up the confirmation of a transaction
+type FeeBumpStrategy string
+
+// Strategies of a FeeBumpPlan
+const (
+	// The transaction can't or doesn't need to be bumped
+	FeeBumpNone FeeBumpStrategy = "none"
+	// The wallet can replace the transaction with BumpFee or PsbtBumpFee
+	FeeBumpRBF FeeBumpStrategy = "rbf"
+	// The wallet can spend an output of the transaction with a higher fee
+	// child transaction
+	FeeBumpCPFP FeeBumpStrategy = "cpfp"
+	// The transaction is not in the mempool, it can be abandoned with
+	// AbandonTransaction to release its inputs
+	FeeBumpAbandon FeeBumpStrategy = "abandon"
+)
+
+// FeeBumpPlan represents how to speed up a wallet transaction, as returned
+// by PlanFeeBump
+type FeeBumpPlan struct {
+	// The strategy to use
+	Strategy FeeBumpStrategy
+	// Why this strategy was chosen
+	Reason string
+	// The mempool entry of the transaction, nil if it is not in the mempool
+	Entry *VerboseTx
+	// The outputs of the transaction the wallet can spend in a child
+	// transaction, only for FeeBumpCPFP
+	Outputs []Unspent
+}
+
+// BumpFee replaces an unconfirmed wallet transaction signaling BIP 125
+// replaceability with a new one paying a higher fee, and broadcasts it.
+// [options] may be nil.
+func (b *Bitcoind) BumpFee(txid string, options *BumpFeeOptions) (result BumpFeeResult, err error) {
+	return b.bumpFee("bumpfee", txid, options)
+}
+
+// PsbtBumpFee returns a PSBT of a transaction replacing an unconfirmed wallet
+// transaction signaling BIP 125 replaceability with a higher fee, to be
+// signed and broadcast, for watch-only wallets.
+// [options] may be nil.
+func (b *Bitcoind) PsbtBumpFee(txid string, options *BumpFeeOptions) (result BumpFeeResult, err error) {
+	return b.bumpFee("psbtbumpfee", txid, options)
+}
+
+// bumpFee calls "bumpfee" or "psbtbumpfee"
+func (b *Bitcoind) bumpFee(method, txid string, options *BumpFeeOptions) (result BumpFeeResult, err error) {
+	params := []interface{}{txid}
+	if options != nil {
+		params = append(params, options)
+	}
+	r, err := b.client.call(method, params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// AbandonTransaction marks an unconfirmed wallet transaction, which is not in
+// the mempool, and its in-wallet descendants as abandoned, so that their
+// inputs can be respent
+func (b *Bitcoind) AbandonTransaction(txid string) error {
+	r, err := b.client.call("abandontransaction", []string{txid})
+	return handleError(err, &r)
+}
+
+// maxBIP125Sequence is the highest input sequence signaling BIP 125
+// replaceability
+const maxBIP125Sequence = 0xfffffffd
+
+// SignalsReplaceability tells whether the transaction itself signals BIP 125
+// replaceability, that is whether one of its inputs has a sequence below
+// 0xfffffffe
+func (tx RawTransaction) SignalsReplaceability() bool {
+	for _, in := range tx.Vin {
+		if in.Sequence <= maxBIP125Sequence {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanFeeBump tells how to speed up the confirmation of a wallet
+// transaction, from its wallet and mempool data:
+// - a confirmed or replaced transaction is left alone
+// - a transaction which left the mempool can be abandoned
+// - a transaction sent by the wallet, signaling BIP 125 replaceability
+// itself, from the sequences of its inputs, and without descendants can be
+// replaced (RBF). Inheriting replaceability from unconfirmed ancestors, as
+// reported by the mempool, is not enough for bumpfee.
+// - otherwise a transaction with an output the wallet can spend can be
+// bumped by a child transaction (CPFP)
+func (b *Bitcoind) PlanFeeBump(txid string) (plan FeeBumpPlan, err error) {
+	tx, err := b.GetTransaction(txid)
+	if err != nil {
+		return
+	}
+	plan.Strategy = FeeBumpNone
+	switch {
+	case tx.Confirmations > 0:
+		plan.Reason = "transaction is confirmed"
+		return
+	case tx.Confirmations < 0:
+		plan.Reason = "transaction conflicts with a confirmed transaction"
+		return
+	case tx.ReplacedByTxID != "":
+		plan.Reason = fmt.Sprintf("transaction was replaced by %s", tx.ReplacedByTxID)
+		return
+	}
+
+	entry, err := b.GetMempoolEntry(txid)
+	if rpcErr, ok := err.(*RPCError); ok && rpcErr.Code == rpcInvalidAddressOrKey {
+		plan.Strategy = FeeBumpAbandon
+		plan.Reason = "transaction is not in the mempool"
+		return plan, nil
+	}
+	if err != nil {
+		return
+	}
+	plan.Entry = &entry
+
+	raw, err := ParseTransaction(tx.Hex)
+	if err != nil {
+		return
+	}
+	signals := raw.SignalsReplaceability()
+
+	// Only the wallet transactions spending wallet coins have a fee
+	sent := tx.Fee < 0
+	if sent && signals && entry.DescendantCount <= 1 {
+		plan.Strategy = FeeBumpRBF
+		plan.Reason = "transaction is sent by the wallet and signals BIP 125 replaceability"
+		return
+	}
+
+	includeUnsafe := true
+	unspents, err := b.ListUnspentWithOptions(0, 0, &ListUnspentOptions{IncludeUnsafe: &includeUnsafe})
+	if err != nil {
+		return
+	}
+	for _, unspent := range unspents {
+		if unspent.TxID == txid && unspent.Spendable {
+			plan.Outputs = append(plan.Outputs, unspent)
+		}
+	}
+	if len(plan.Outputs) > 0 {
+		plan.Strategy = FeeBumpCPFP
+		plan.Reason = "transaction has outputs the wallet can spend"
+		return
+	}
+	switch {
+	case !sent:
+		plan.Reason = "transaction is not sent by the wallet and has no output it can spend"
+	case !signals:
+		plan.Reason = "transaction is not BIP 125 replaceable and has no output the wallet can spend"
+	default:
+		plan.Reason = "transaction has descendants and no output the wallet can spend"
+	}
+	return
+}
@@ -0,0 +1,206 @@
+package bitcoind
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BumpFee", func() {
+	const txid = "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+
+	Describe("Testing BumpFee", func() {
+		Context("when options are set", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"bumpfee": `{"txid":"f1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","origfee":0.0000141,"fee":0.0000282,"errors":[]}`,
+			}, &calls)
+			defer done()
+			feeRate, replaceable := 20.0, false
+			result, err := bitcoindClient.BumpFee(txid, &BumpFeeOptions{
+				FeeRate:     &feeRate,
+				Replaceable: &replaceable,
+				Outputs:     []TxOutput{{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", Amount: 0.5}},
+			})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the options", func() {
+				Expect(calls).To(Equal([]string{"bumpfee [" + txid + " map[fee_rate:20 outputs:[map[bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4:0.5]] replaceable:false]]"}))
+			})
+			It("should return", func() {
+				Expect(result).To(Equal(BumpFeeResult{TxID: "f1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", OrigFee: 1410, Fee: 2820, Errors: []string{}}))
+			})
+		})
+	})
+
+	Describe("Testing PsbtBumpFee", func() {
+		Context("when options are nil", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"psbtbumpfee": `{"psbt":"cHNidP8BAAoCAAAAAAAAAAAAAAAA","origfee":0.0000141,"fee":0.0000282,"errors":[]}`,
+			}, &calls)
+			defer done()
+			result, err := bitcoindClient.PsbtBumpFee(txid, nil)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should only send the txid", func() {
+				Expect(calls).To(Equal([]string{"psbtbumpfee [" + txid + "]"}))
+			})
+			It("should return the psbt", func() {
+				Expect(result.Psbt).To(Equal("cHNidP8BAAoCAAAAAAAAAAAAAAAA"))
+				Expect(result.TxID).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Testing AbandonTransaction", func() {
+		Context("when success", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"abandontransaction": `null`}, &calls)
+			defer done()
+			err := bitcoindClient.AbandonTransaction(txid)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the txid", func() {
+				Expect(calls).To(Equal([]string{"abandontransaction [" + txid + "]"}))
+			})
+		})
+		Context("when the transaction is in the mempool", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(nil, &calls, map[string]RPCError{
+				"abandontransaction": {Code: -5, Message: "Transaction not eligible for abandonment"},
+			})
+			defer done()
+			err := bitcoindClient.AbandonTransaction(txid)
+			It("should return the RPC error", func() {
+				Expect(err).To(Equal(&RPCError{Code: -5, Message: "Transaction not eligible for abandonment"}))
+			})
+		})
+	})
+
+	Describe("Testing PlanFeeBump", func() {
+		// txHex returns a one input transaction with the given little endian
+		// sequence
+		txHex := func(sequence string) string {
+			return "0200000001f1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f900000000000" + sequence +
+				"0180f0fa0200000000160014751e76e8199196d454941c45d1b3a323f1433bd600000000"
+		}
+		sentTx := `{"amount":-0.5,"fee":-0.0000141,"confirmations":0,"bip125-replaceable":"yes","txid":"` + txid + `","walletconflicts":[],"time":1700000000,"timereceived":1700000000,"details":[],"hex":"` + txHex("fdffffff") + `"}`
+		// inheritingTx does not signal replaceability, but has an unconfirmed
+		// ancestor which does
+		inheritingTx := `{"amount":-0.5,"fee":-0.0000141,"confirmations":0,"bip125-replaceable":"yes","txid":"` + txid + `","walletconflicts":[],"time":1700000000,"timereceived":1700000000,"details":[],"hex":"` + txHex("feffffff") + `"}`
+		receivedTx := `{"amount":0.5,"confirmations":0,"bip125-replaceable":"no","txid":"` + txid + `","walletconflicts":[],"time":1700000000,"timereceived":1700000000,"details":[],"hex":"` + txHex("ffffffff") + `"}`
+		entry := func(replaceable bool, descendants int) string {
+			return fmt.Sprintf(`{"vsize":141,"weight":561,"time":1700000000,"height":815000,"descendantcount":%d,"ancestorcount":1,"wtxid":"%s","fees":{"base":0.0000141,"modified":0.0000141,"ancestor":0.0000141,"descendant":0.0000141},"depends":[],"spentby":[],"bip125-replaceable":%t,"unbroadcast":false}`, descendants, txid, replaceable)
+		}
+		unspents := `[{"txid":"` + txid + `","vout":1,"scriptPubKey":"0014751e76e8199196d454941c45d1b3a323f1433bd6","amount":0.5,"confirmations":0,"spendable":true,"solvable":true,"safe":false},` +
+			`{"txid":"f1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","vout":0,"scriptPubKey":"0014751e76e8199196d454941c45d1b3a323f1433bd6","amount":1,"confirmations":0,"spendable":true,"solvable":true,"safe":true}]`
+
+		Context("when the transaction is confirmed", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"gettransaction": `{"amount":-0.5,"fee":-0.0000141,"confirmations":3,"txid":"` + txid + `","walletconflicts":[],"time":1700000000,"timereceived":1700000000}`,
+			}, &calls)
+			defer done()
+			plan, err := bitcoindClient.PlanFeeBump(txid)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should not bump", func() {
+				Expect(plan).To(Equal(FeeBumpPlan{Strategy: FeeBumpNone, Reason: "transaction is confirmed"}))
+				Expect(calls).To(HaveLen(1))
+			})
+		})
+		Context("when the transaction left the mempool", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"gettransaction": sentTx}, &calls, map[string]RPCError{
+				"getmempoolentry": {Code: -5, Message: "Transaction not in mempool"},
+			})
+			defer done()
+			plan, err := bitcoindClient.PlanFeeBump(txid)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should abandon", func() {
+				Expect(plan.Strategy).To(Equal(FeeBumpAbandon))
+				Expect(plan.Entry).To(BeNil())
+			})
+		})
+		Context("when the transaction is sent and replaceable", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"gettransaction":  sentTx,
+				"getmempoolentry": entry(true, 1),
+			}, &calls)
+			defer done()
+			plan, err := bitcoindClient.PlanFeeBump(txid)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should replace", func() {
+				Expect(plan.Strategy).To(Equal(FeeBumpRBF))
+				Expect(plan.Entry.Vsize).To(Equal(uint32(141)))
+				Expect(calls).To(HaveLen(2))
+			})
+		})
+		Context("when the transaction is sent and only inherits replaceability", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"gettransaction":  inheritingTx,
+				"getmempoolentry": entry(true, 1),
+				"listunspent":     `[]`,
+			}, &calls)
+			defer done()
+			plan, err := bitcoindClient.PlanFeeBump(txid)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should not replace", func() {
+				Expect(plan.Strategy).To(Equal(FeeBumpNone))
+				Expect(plan.Reason).To(Equal("transaction is not BIP 125 replaceable and has no output the wallet can spend"))
+			})
+		})
+		Context("when the transaction is sent and has descendants", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"gettransaction":  sentTx,
+				"getmempoolentry": entry(true, 2),
+				"listunspent":     unspents,
+			}, &calls)
+			defer done()
+			plan, err := bitcoindClient.PlanFeeBump(txid)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should include unsafe outputs", func() {
+				Expect(calls[2]).To(Equal("listunspent [0 0 [] true]"))
+			})
+			It("should spend the outputs of the transaction", func() {
+				Expect(plan.Strategy).To(Equal(FeeBumpCPFP))
+				Expect(plan.Outputs).To(HaveLen(1))
+				Expect(plan.Outputs[0].Vout).To(Equal(uint32(1)))
+			})
+		})
+		Context("when the transaction is received without a spendable output", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"gettransaction":  receivedTx,
+				"getmempoolentry": entry(false, 1),
+				"listunspent":     `[]`,
+			}, &calls)
+			defer done()
+			plan, err := bitcoindClient.PlanFeeBump(txid)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should not bump", func() {
+				Expect(plan.Strategy).To(Equal(FeeBumpNone))
+				Expect(plan.Reason).To(Equal("transaction is not sent by the wallet and has no output it can spend"))
+			})
+		})
+	})
+})
@@ -0,0 +1,180 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// DescriptorInfo represents a response to "getdescriptorinfo" call
+type DescriptorInfo struct {
+	// The descriptor in canonical form, without private keys
+	Descriptor string `json:"descriptor"`
+	// The checksum for the input descriptor
+	Checksum string `json:"checksum"`
+	// Whether the descriptor is ranged
+	IsRange bool `json:"isrange"`
+	// Whether the descriptor is solvable
+	IsSolvable bool `json:"issolvable"`
+	// Whether the input descriptor contained at least one private key
+	HasPrivateKeys bool `json:"hasprivatekeys"`
+}
+
+// DescriptorRange represents the range of indexes of a ranged descriptor,
+// both ends included
+type DescriptorRange struct {
+	Begin uint32
+	End   uint32
+}
+
+// MarshalJSON encodes the range as bitcoind expects it, [begin,end]
+func (r DescriptorRange) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]uint32{r.Begin, r.End})
+}
+
+// UnmarshalJSON decodes a range given either as [begin,end] or as a single
+// end index, which means [0,end]
+func (r *DescriptorRange) UnmarshalJSON(data []byte) error {
+	var end uint32
+	if err := json.Unmarshal(data, &end); err == nil {
+		*r = DescriptorRange{End: end}
+		return nil
+	}
+	var bounds []uint32
+	if err := json.Unmarshal(data, &bounds); err != nil {
+		return err
+	}
+	if len(bounds) != 2 {
+		return errors.New("Bad descriptor range: expecting [begin,end]")
+	}
+	*r = DescriptorRange{Begin: bounds[0], End: bounds[1]}
+	return nil
+}
+
+// ImportDescriptorRequest represents a descriptor to import with
+// "importdescriptors" call
+type ImportDescriptorRequest struct {
+	// The descriptor to import
+	Desc string `json:"desc"`
+	// Whether this descriptor should be set as the active one for its output
+	// type and internal flag
+	Active *bool `json:"active,omitempty"`
+	// The range of a ranged descriptor to import
+	Range *DescriptorRange `json:"range,omitempty"`
+	// The next index to generate addresses from for a ranged descriptor
+	NextIndex *uint32 `json:"next_index,omitempty"`
+	// The creation time of the oldest key in UNIX epoch time, the wallet is
+	// rescanned from it. nil means "now", with no rescan.
+	Timestamp *int64 `json:"-"`
+	// Whether the matching outputs should be treated as change
+	Internal *bool `json:"internal,omitempty"`
+	// The label to assign to the addresses, not allowed for internal ones
+	Label string `json:"label,omitempty"`
+}
+
+// MarshalJSON encodes the request, sending "now" when Timestamp is nil
+func (r ImportDescriptorRequest) MarshalJSON() ([]byte, error) {
+	type request ImportDescriptorRequest
+	var timestamp interface{} = "now"
+	if r.Timestamp != nil {
+		timestamp = *r.Timestamp
+	}
+	return json.Marshal(struct {
+		request
+		Timestamp interface{} `json:"timestamp"`
+	}{request(r), timestamp})
+}
+
+// ImportDescriptorResult represents the result of "importdescriptors" call
+// for a descriptor
+type ImportDescriptorResult struct {
+	// Whether the descriptor was imported
+	Success bool `json:"success"`
+	// Warnings raised while importing the descriptor
+	Warnings []string `json:"warnings,omitempty"`
+	// The import error if the descriptor was not imported
+	Error *RPCError `json:"error,omitempty"`
+}
+
+// WalletDescriptor represents a descriptor of a wallet, as returned by
+// "listdescriptors" call
+type WalletDescriptor struct {
+	// The descriptor string
+	Desc string `json:"desc"`
+	// The creation time of the descriptor
+	Timestamp int64 `json:"timestamp"`
+	// Whether this descriptor is currently used to generate new addresses
+	Active bool `json:"active"`
+	// Whether this descriptor is used to generate change addresses, only
+	// present for active descriptors
+	Internal *bool `json:"internal,omitempty"`
+	// The range of a ranged descriptor
+	Range *DescriptorRange `json:"range,omitempty"`
+	// The next index to generate addresses from for a ranged descriptor
+	Next *uint32 `json:"next,omitempty"`
+	// Same as Next, returned by recent versions of bitcoind
+	NextIndex *uint32 `json:"next_index,omitempty"`
+}
+
+// ListDescriptorsResult represents a response to "listdescriptors" call
+type ListDescriptorsResult struct {
+	// The name of the wallet
+	WalletName string `json:"wallet_name"`
+	// The descriptors of the wallet
+	Descriptors []WalletDescriptor `json:"descriptors"`
+}
+
+// GetDescriptorInfo analyses a descriptor and returns its canonical form and
+// checksum
+func (b *Bitcoind) GetDescriptorInfo(descriptor string) (info DescriptorInfo, err error) {
+	r, err := b.client.call("getdescriptorinfo", []string{descriptor})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &info)
+	return
+}
+
+// DeriveAddresses returns the addresses of a descriptor.
+// [derivationRange] is required for ranged descriptors and must be nil for
+// the others.
+func (b *Bitcoind) DeriveAddresses(descriptor string, derivationRange *DescriptorRange) (addresses []string, err error) {
+	params := []interface{}{descriptor}
+	if derivationRange != nil {
+		params = append(params, derivationRange)
+	}
+	r, err := b.client.call("deriveaddresses", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &addresses)
+	return
+}
+
+// ImportDescriptors imports descriptors in a descriptor wallet and returns
+// the result of each import, in the order of the requests.
+// The call succeeds even when some descriptors fail to import, check the
+// results.
+func (b *Bitcoind) ImportDescriptors(requests []ImportDescriptorRequest) (results []ImportDescriptorResult, err error) {
+	if len(requests) == 0 {
+		err = errors.New("Bad parameters for ImportDescriptors: you must set at least one request")
+		return
+	}
+	r, err := b.client.call("importdescriptors", []interface{}{requests})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &results)
+	return
+}
+
+// ListDescriptors returns the descriptors of a descriptor wallet.
+// If [private] is set, descriptors are returned with their private keys,
+// which requires an unlocked wallet.
+func (b *Bitcoind) ListDescriptors(private bool) (result ListDescriptorsResult, err error) {
+	r, err := b.client.call("listdescriptors", []bool{private})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
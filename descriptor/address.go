@@ -0,0 +1,14 @@
+package descriptor
+
+import "github.com/toorop/go-bitcoind/address"
+
+// A Network represents the address encoding parameters of a Bitcoin network
+type Network = address.Network
+
+// Supported networks
+var (
+	MainNet = address.MainNet
+	TestNet = address.TestNet
+	SigNet  = address.SigNet
+	RegTest = address.RegTest
+)
@@ -0,0 +1,96 @@
+package descriptor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// inputCharset is the set of characters allowed in descriptors, ordered so
+// that the checksum detects the most common errors
+const inputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+	"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+	"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+// checksumCharset is the set of characters of a descriptor checksum
+const checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// checksumLength is the number of characters of a descriptor checksum
+const checksumLength = 8
+
+// descriptorPolymod computes the descriptor checksum polynomial
+func descriptorPolymod(c uint64, value int) uint64 {
+	top := c >> 35
+	c = (c&0x7ffffffff)<<5 ^ uint64(value)
+	generator := [5]uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+	for i := 0; i < 5; i++ {
+		if (top>>uint(i))&1 == 1 {
+			c ^= generator[i]
+		}
+	}
+	return c
+}
+
+// Checksum returns the checksum of a descriptor given without its
+// "#checksum" suffix
+func Checksum(desc string) (string, error) {
+	c := uint64(1)
+	var class, classCount int
+	for _, ch := range desc {
+		pos := strings.IndexRune(inputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("Invalid character %q in descriptor", ch)
+		}
+		// Emit a symbol for the position inside the group, for every
+		// character, and a symbol for the group of every 3 characters
+		c = descriptorPolymod(c, pos&31)
+		class = class*3 + pos>>5
+		if classCount++; classCount == 3 {
+			c = descriptorPolymod(c, class)
+			class, classCount = 0, 0
+		}
+	}
+	if classCount > 0 {
+		c = descriptorPolymod(c, class)
+	}
+	for i := 0; i < checksumLength; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, checksumLength)
+	for i := range checksum {
+		checksum[i] = checksumCharset[(c>>uint(5*(7-i)))&31]
+	}
+	return string(checksum), nil
+}
+
+// AddChecksum returns the descriptor with its "#checksum" suffix
+func AddChecksum(desc string) (string, error) {
+	checksum, err := Checksum(desc)
+	if err != nil {
+		return "", err
+	}
+	return desc + "#" + checksum, nil
+}
+
+// splitChecksum splits a descriptor from its checksum, verifying it when it
+// is present
+func splitChecksum(str string) (desc string, err error) {
+	sep := strings.LastIndexByte(str, '#')
+	if sep < 0 {
+		return str, nil
+	}
+	desc, given := str[:sep], str[sep+1:]
+	if len(given) != checksumLength {
+		return "", fmt.Errorf("Expected %d character checksum, not %d characters", checksumLength, len(given))
+	}
+	expected, err := Checksum(desc)
+	if err != nil {
+		return "", err
+	}
+	if given != expected {
+		return "", errors.New("Provided checksum '" + given + "' does not match computed checksum '" + expected + "'")
+	}
+	return desc, nil
+}
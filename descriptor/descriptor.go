@@ -0,0 +1,463 @@
+// Package descriptor is a pure Go parser of Bitcoin Core output script
+// descriptors.
+//
+// It supports the pk, pkh, wpkh, sh, wsh, tr, multi, sortedmulti, addr and
+// raw expressions, key origins and extended public keys with derivation
+// paths. It computes and verifies descriptor checksums, and derives the
+// scriptPubKeys and addresses "deriveaddresses" would return, so that
+// descriptors can be checked before being sent to "importdescriptors".
+//
+// Only public keys are supported: descriptors holding private keys or
+// hardened derivation steps after an extended key are rejected.
+package descriptor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/toorop/go-bitcoind/address"
+)
+
+// A context represents where an expression appears, which restricts the
+// expressions and keys allowed
+type context int
+
+const (
+	// Top level expression
+	ctxTop context = iota
+	// Inside sh()
+	ctxP2SH
+	// Inside wsh(), or the key of wpkh()
+	ctxWitnessV0
+	// Inside tr()
+	ctxP2TR
+)
+
+// Script limits enforced by Bitcoin Core
+const (
+	maxPubKeysPerMultisig = 20
+	maxBareMultisigKeys   = 3
+	maxScriptElementSize  = 520
+	maxTaprootTreeDepth   = 128
+)
+
+// A Descriptor represents a parsed output script descriptor
+type Descriptor struct {
+	// The descriptor, without checksum
+	desc string
+	root *node
+}
+
+// A node represents an expression of a descriptor
+type node struct {
+	name string
+	// The keys of pk, pkh, wpkh, multi, sortedmulti and the internal key of tr
+	keys []*key
+	// The threshold of multi and sortedmulti
+	threshold int
+	// The inner expression of sh and wsh
+	sub *node
+	// The script tree of tr, nil for a key path only output
+	tree *tapNode
+	// The scriptPubKey of raw and addr
+	script []byte
+}
+
+// A tapNode represents a node of a taproot script tree, either a leaf or a
+// branch
+type tapNode struct {
+	leaf        *node
+	left, right *tapNode
+}
+
+// Parse decodes a descriptor, verifying its checksum if present
+func Parse(str string) (*Descriptor, error) {
+	desc, err := splitChecksum(str)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseExpr(desc, ctxTop)
+	if err != nil {
+		return nil, err
+	}
+	return &Descriptor{desc: desc, root: root}, nil
+}
+
+// String returns the descriptor with its checksum
+func (d *Descriptor) String() string {
+	str, _ := AddChecksum(d.desc)
+	return str
+}
+
+// IsRange checks the descriptor has a key ending with /*, whose scripts
+// depend on the derivation index
+func (d *Descriptor) IsRange() bool {
+	return d.root.isRange()
+}
+
+// ScriptPubKey returns the scriptPubKey of the descriptor at the given
+// derivation index, ignored by non ranged descriptors
+func (d *Descriptor) ScriptPubKey(index uint32) ([]byte, error) {
+	return d.root.scriptPubKey(index)
+}
+
+// Address returns the address of the descriptor on net at the given
+// derivation index, ignored by non ranged descriptors
+func (d *Descriptor) Address(index uint32, net *Network) (string, error) {
+	script, err := d.ScriptPubKey(index)
+	if err != nil {
+		return "", err
+	}
+	addr, err := address.FromScript(script, net)
+	if err != nil {
+		return "", err
+	}
+	return addr.String(), nil
+}
+
+// Addresses returns the addresses of a ranged descriptor on net for the
+// indexes from begin to end included, as "deriveaddresses" does
+func (d *Descriptor) Addresses(begin, end uint32, net *Network) ([]string, error) {
+	if !d.IsRange() {
+		return nil, errors.New("Range should not be specified for an un-ranged descriptor")
+	}
+	if begin > end {
+		return nil, errors.New("Range specified as [begin,end] must not have begin after end")
+	}
+	var addresses []string
+	for i := uint64(begin); i <= uint64(end); i++ {
+		addr, err := d.Address(uint32(i), net)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, addr)
+	}
+	return addresses, nil
+}
+
+// parseExpr decodes a NAME(ARGS) expression in the given context
+func parseExpr(str string, ctx context) (*node, error) {
+	open := strings.IndexByte(str, '(')
+	if open < 0 || !strings.HasSuffix(str, ")") {
+		return nil, fmt.Errorf("'%s' is not a valid descriptor function", str)
+	}
+	n := &node{name: str[:open]}
+	args := str[open+1 : len(str)-1]
+
+	switch n.name {
+	case "pk", "pkh":
+		k, err := parseKey(args, ctx)
+		if err != nil {
+			return nil, err
+		}
+		n.keys = []*key{k}
+	case "wpkh":
+		if ctx != ctxTop && ctx != ctxP2SH {
+			return nil, errors.New("Can only have wpkh() at top level or inside sh()")
+		}
+		k, err := parseKey(args, ctxWitnessV0)
+		if err != nil {
+			return nil, err
+		}
+		n.keys = []*key{k}
+	case "sh":
+		if ctx != ctxTop {
+			return nil, errors.New("Can only have sh() at top level")
+		}
+		sub, err := parseExpr(args, ctxP2SH)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkScriptSize(sub, "P2SH"); err != nil {
+			return nil, err
+		}
+		n.sub = sub
+	case "wsh":
+		if ctx != ctxTop && ctx != ctxP2SH {
+			return nil, errors.New("Can only have wsh() at top level or inside sh()")
+		}
+		sub, err := parseExpr(args, ctxWitnessV0)
+		if err != nil {
+			return nil, err
+		}
+		n.sub = sub
+	case "multi", "sortedmulti":
+		if ctx == ctxP2TR {
+			return nil, fmt.Errorf("Can only have %s() at top level, inside sh() or inside wsh()", n.name)
+		}
+		if err := n.parseMulti(args, ctx); err != nil {
+			return nil, err
+		}
+	case "tr":
+		if ctx != ctxTop {
+			return nil, errors.New("Can only have tr at top level")
+		}
+		if err := n.parseTr(args); err != nil {
+			return nil, err
+		}
+	case "addr":
+		if ctx != ctxTop {
+			return nil, errors.New("Can only have addr() at top level")
+		}
+		addr, err := address.DecodeAny(args)
+		if err != nil {
+			return nil, err
+		}
+		n.script = addr.ScriptPubKey
+	case "raw":
+		if ctx != ctxTop {
+			return nil, errors.New("Can only have raw() at top level")
+		}
+		script, err := hex.DecodeString(args)
+		if err != nil {
+			return nil, errors.New("Raw script is not hex")
+		}
+		n.script = script
+	default:
+		return nil, fmt.Errorf("'%s' is not a valid descriptor function", str)
+	}
+	return n, nil
+}
+
+// parseMulti decodes the threshold and keys of multi and sortedmulti
+func (n *node) parseMulti(args string, ctx context) error {
+	parts := splitArgs(args)
+	threshold, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return fmt.Errorf("Multi threshold '%s' is not valid", parts[0])
+	}
+	for _, part := range parts[1:] {
+		k, err := parseKey(part, ctx)
+		if err != nil {
+			return err
+		}
+		n.keys = append(n.keys, k)
+	}
+	switch {
+	case len(n.keys) == 0 || len(n.keys) > maxPubKeysPerMultisig:
+		return fmt.Errorf("Cannot have %d keys in multisig; must have between 1 and %d keys, inclusive", len(n.keys), maxPubKeysPerMultisig)
+	case threshold < 1:
+		return fmt.Errorf("Multisig threshold cannot be %d, must be at least 1", threshold)
+	case threshold > len(n.keys):
+		return fmt.Errorf("Multisig threshold cannot be larger than the number of keys; threshold is %d but only %d keys specified", threshold, len(n.keys))
+	case ctx == ctxTop && len(n.keys) > maxBareMultisigKeys:
+		return fmt.Errorf("Cannot have %d pubkeys in bare multisig; only at most %d pubkeys", len(n.keys), maxBareMultisigKeys)
+	}
+	n.threshold = threshold
+	return nil
+}
+
+// parseTr decodes the internal key and script tree of tr
+func (n *node) parseTr(args string) error {
+	parts := splitArgs(args)
+	if len(parts) > 2 {
+		return errors.New("tr() takes at most 2 arguments")
+	}
+	k, err := parseKey(parts[0], ctxP2TR)
+	if err != nil {
+		return err
+	}
+	n.keys = []*key{k}
+	if len(parts) == 2 {
+		n.tree, err = parseTapTree(parts[1], 0)
+	}
+	return err
+}
+
+// parseTapTree decodes a taproot script tree: either a leaf script or a
+// {left,right} branch
+func parseTapTree(str string, depth int) (*tapNode, error) {
+	if depth > maxTaprootTreeDepth {
+		return nil, fmt.Errorf("tr() supports at most %d nesting levels", maxTaprootTreeDepth)
+	}
+	if !strings.HasPrefix(str, "{") {
+		leaf, err := parseExpr(str, ctxP2TR)
+		if err != nil {
+			return nil, err
+		}
+		if leaf.name != "pk" && leaf.name != "pkh" {
+			return nil, fmt.Errorf("%s() is not supported in tapscript, only pk() and pkh() are", leaf.name)
+		}
+		return &tapNode{leaf: leaf}, nil
+	}
+	if !strings.HasSuffix(str, "}") {
+		return nil, fmt.Errorf("Unbalanced script tree '%s'", str)
+	}
+	parts := splitArgs(str[1 : len(str)-1])
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Script tree branch '%s' must have 2 children", str)
+	}
+	left, err := parseTapTree(parts[0], depth+1)
+	if err != nil {
+		return nil, err
+	}
+	right, err := parseTapTree(parts[1], depth+1)
+	if err != nil {
+		return nil, err
+	}
+	return &tapNode{left: left, right: right}, nil
+}
+
+// splitArgs splits a comma separated list of arguments, ignoring commas
+// nested in (), [] or {}
+func splitArgs(str string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range str {
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, str[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, str[start:])
+}
+
+// checkScriptSize checks the script of sub fits in a script element, as
+// required for the redeem script of a P2SH output
+func checkScriptSize(sub *node, kind string) error {
+	script, err := sub.scriptPubKey(0)
+	if err != nil {
+		return err
+	}
+	if len(script) > maxScriptElementSize {
+		return fmt.Errorf("%s script is too large, %d bytes is larger than %d bytes", kind, len(script), maxScriptElementSize)
+	}
+	return nil
+}
+
+// isRange checks a key of the node or of its children is ranged
+func (n *node) isRange() bool {
+	for _, k := range n.keys {
+		if k.ranged {
+			return true
+		}
+	}
+	if n.sub != nil && n.sub.isRange() {
+		return true
+	}
+	return n.tree != nil && n.tree.isRange()
+}
+
+// isRange checks a key of the tree is ranged
+func (t *tapNode) isRange() bool {
+	if t.leaf != nil {
+		return t.leaf.isRange()
+	}
+	return t.left.isRange() || t.right.isRange()
+}
+
+// pubKeys returns the keys of the node derived at index
+func (n *node) pubKeys(index uint32) ([][]byte, error) {
+	pubKeys := make([][]byte, len(n.keys))
+	for i, k := range n.keys {
+		pubKey, err := k.derive(index)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys[i] = pubKey
+	}
+	return pubKeys, nil
+}
+
+// scriptPubKey returns the script of the node at the derivation index
+func (n *node) scriptPubKey(index uint32) ([]byte, error) {
+	if n.script != nil {
+		return n.script, nil
+	}
+	pubKeys, err := n.pubKeys(index)
+	if err != nil {
+		return nil, err
+	}
+	switch n.name {
+	case "pk":
+		return append(pushData(pubKeys[0]), opCheckSig), nil
+	case "pkh":
+		return p2pkhScript(hash160(pubKeys[0])), nil
+	case "wpkh":
+		return address.WitnessScript(0, hash160(pubKeys[0])), nil
+	case "sh":
+		redeemScript, err := n.sub.scriptPubKey(index)
+		if err != nil {
+			return nil, err
+		}
+		return p2shScript(hash160(redeemScript)), nil
+	case "wsh":
+		witnessProgram, err := n.sub.scriptPubKey(index)
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(witnessProgram)
+		return address.WitnessScript(0, hash[:]), nil
+	case "multi", "sortedmulti":
+		if n.name == "sortedmulti" {
+			sort.Slice(pubKeys, func(i, j int) bool { return string(pubKeys[i]) < string(pubKeys[j]) })
+		}
+		script := pushSmallInt(n.threshold)
+		for _, pubKey := range pubKeys {
+			script = append(script, pushData(pubKey)...)
+		}
+		script = append(script, pushSmallInt(len(pubKeys))...)
+		return append(script, opCheckMultiSig), nil
+	case "tr":
+		return n.taprootScript(pubKeys[0], index)
+	default:
+		return nil, fmt.Errorf("%s() has no scriptPubKey", n.name)
+	}
+}
+
+// taprootScript returns the P2TR scriptPubKey of an internal key and the
+// node script tree, as BIP 341
+func (n *node) taprootScript(internalKey []byte, index uint32) ([]byte, error) {
+	tweakData := [][]byte{internalKey}
+	if n.tree != nil {
+		merkleRoot, err := n.tree.hash(index)
+		if err != nil {
+			return nil, err
+		}
+		tweakData = append(tweakData, merkleRoot)
+	}
+	p, err := parsePubKey(internalKey)
+	if err != nil {
+		return nil, err
+	}
+	q, err := tweakAdd(p, taggedHash("TapTweak", tweakData...))
+	if err != nil {
+		return nil, err
+	}
+	return address.WitnessScript(1, xOnly(q)), nil
+}
+
+// hash returns the BIP 341 hash of a script tree node
+func (t *tapNode) hash(index uint32) ([]byte, error) {
+	if t.leaf != nil {
+		script, err := t.leaf.scriptPubKey(index)
+		if err != nil {
+			return nil, err
+		}
+		return taggedHash("TapLeaf", []byte{tapscriptLeafVersion}, compactSize(len(script)), script), nil
+	}
+	left, err := t.left.hash(index)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.right.hash(index)
+	if err != nil {
+		return nil, err
+	}
+	if string(right) < string(left) {
+		left, right = right, left
+	}
+	return taggedHash("TapBranch", left, right), nil
+}
@@ -0,0 +1,13 @@
+package descriptor
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDescriptor(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Descriptor Suite")
+}
@@ -0,0 +1,178 @@
+package descriptor
+
+import (
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	// The public key of the private key 1
+	pubKeyG = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	// The public key of the private key 2
+	pubKey2G = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+	// The BIP 86 account key of the "abandon ... about" mnemonic
+	bip86Xpub = "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ"
+	// The master key of BIP 32 test vector 2
+	bip32Vector2Xpub = "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB"
+)
+
+var _ = Describe("Descriptor", func() {
+	Describe("Checksum", func() {
+		It("should compute Bitcoin Core checksums", func() {
+			Expect(Checksum("pkh(" + pubKey2G + ")")).To(Equal("8fhd9pwu"))
+			Expect(Checksum("raw(deadbeef)")).To(Equal("89f8spxm"))
+		})
+		It("should add the checksum", func() {
+			Expect(AddChecksum("raw(deadbeef)")).To(Equal("raw(deadbeef)#89f8spxm"))
+		})
+		It("should reject characters outside of the descriptor charset", func() {
+			_, err := Checksum("raw(deadbeef)é")
+			Expect(err).To(MatchError("Invalid character 'é' in descriptor"))
+		})
+	})
+
+	Describe("Parse", func() {
+		Context("when the checksum is valid", func() {
+			d, err := Parse("raw(deadbeef)#89f8spxm")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return the descriptor with its checksum", func() {
+				Expect(d.String()).To(Equal("raw(deadbeef)#89f8spxm"))
+			})
+			It("should return the raw script", func() {
+				Expect(d.ScriptPubKey(0)).To(Equal([]byte{0xde, 0xad, 0xbe, 0xef}))
+			})
+		})
+		Context("when the checksum is wrong", func() {
+			_, err := Parse("raw(deadbeef)#89f8spxq")
+			It("should error", func() {
+				Expect(err).To(MatchError("Provided checksum '89f8spxq' does not match computed checksum '89f8spxm'"))
+			})
+		})
+		Context("when there is no checksum", func() {
+			d, err := Parse("raw(deadbeef)")
+			It("should add it", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(d.String()).To(Equal("raw(deadbeef)#89f8spxm"))
+			})
+		})
+	})
+
+	Describe("Addresses of single key descriptors", func() {
+		for desc, expected := range map[string]string{
+			"pk(" + pubKeyG + ")":                      "",
+			"pkh(" + pubKeyG + ")":                     "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+			"wpkh(" + pubKeyG + ")":                    "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+			"sh(wpkh(" + pubKeyG + "))":                "3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN",
+			"wsh(pk(" + pubKeyG + "))":                 "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3",
+			"tr(" + pubKeyG[2:] + ")":                  "bc1pmfr3p9j00pfxjh0zmgp99y8zftmd3s5pmedqhyptwy6lm87hf5sspknck9",
+			"addr(1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH)": "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		} {
+			desc, expected := desc, expected
+			Context(desc, func() {
+				d, err := Parse(desc)
+				It("should not error", func() {
+					Expect(err).NotTo(HaveOccurred())
+				})
+				It("should not be ranged", func() {
+					Expect(d.IsRange()).To(BeFalse())
+				})
+				if expected == "" {
+					It("should have no address", func() {
+						_, err := d.Address(0, MainNet)
+						Expect(err).To(MatchError("scriptPubKey has no address"))
+					})
+				} else {
+					It("should return the address", func() {
+						Expect(d.Address(0, MainNet)).To(Equal(expected))
+					})
+				}
+			})
+		}
+		It("should encode addresses for the network", func() {
+			d, _ := Parse("wpkh(" + pubKeyG + ")")
+			Expect(d.Address(0, TestNet)).To(Equal("tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"))
+			Expect(d.Address(0, RegTest)).To(HavePrefix("bcrt1q"))
+		})
+	})
+
+	Describe("Extended keys", func() {
+		Context("when deriving a BIP 86 ranged descriptor", func() {
+			d, err := Parse("tr([73c5da0a/86'/0'/0']" + bip86Xpub + "/0/*)")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should be ranged", func() {
+				Expect(d.IsRange()).To(BeTrue())
+			})
+			It("should derive the BIP 86 addresses", func() {
+				Expect(d.Addresses(0, 1, MainNet)).To(Equal([]string{
+					"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+					"bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh",
+				}))
+			})
+		})
+		Context("when deriving a fixed path", func() {
+			d, err := Parse("pk(" + bip32Vector2Xpub + "/0)")
+			It("should derive the BIP 32 test vector key", func() {
+				Expect(err).NotTo(HaveOccurred())
+				script, _ := d.ScriptPubKey(0)
+				Expect(hex.EncodeToString(script)).To(Equal("21" + "02fc9e5af0ac8d9b3cecfe2a888e2117ba3d089d8585886c9c826b6b22a98d12ea" + "ac"))
+			})
+			It("should not allow a range for a fixed path", func() {
+				_, err := d.Addresses(0, 1, MainNet)
+				Expect(err).To(MatchError("Range should not be specified for an un-ranged descriptor"))
+			})
+		})
+	})
+
+	Describe("Multisig", func() {
+		multi, _ := Parse("wsh(multi(1," + pubKey2G + "," + pubKeyG + "))")
+		sorted, _ := Parse("wsh(sortedmulti(1," + pubKey2G + "," + pubKeyG + "))")
+		ordered, _ := Parse("wsh(multi(1," + pubKeyG + "," + pubKey2G + "))")
+		It("should keep the key order of multi", func() {
+			Expect(mustScript(multi)).NotTo(Equal(mustScript(ordered)))
+		})
+		It("should sort the keys of sortedmulti", func() {
+			Expect(mustScript(sorted)).To(Equal(mustScript(ordered)))
+		})
+		It("should build the multisig script", func() {
+			d, _ := Parse("multi(1," + pubKeyG + "," + pubKey2G + ")")
+			Expect(hex.EncodeToString(mustScript(d))).To(Equal("51" + "21" + pubKeyG + "21" + pubKey2G + "52ae"))
+		})
+	})
+
+	Describe("Invalid descriptors", func() {
+		for desc, msg := range map[string]string{
+			"wsh(wpkh(" + pubKeyG + "))":  "Can only have wpkh() at top level or inside sh()",
+			"sh(sh(pk(" + pubKeyG + ")))": "Can only have sh() at top level",
+			"wpkh(04" + pubKeyG[2:] + "483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8)": "Uncompressed keys are not allowed in segwit descriptors",
+			"pkh(" + bip86Xpub + "/0'/*)":                               "hardened derivation requires a private key",
+			"pkh(" + bip86Xpub + "/*')":                                 "hardened derivation requires a private key",
+			"pkh(KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn)": "private keys are not supported",
+			"multi(3," + pubKeyG + "," + pubKey2G + ")":                 "Multisig threshold cannot be larger than the number of keys; threshold is 3 but only 2 keys specified",
+			"tr(" + pubKeyG + ")":                                       "Pubkey '" + pubKeyG + "' is invalid, taproot keys must be x-only",
+			"tr(" + pubKeyG[2:] + ",multi(1," + pubKeyG[2:] + "))":      "Can only have multi() at top level, inside sh() or inside wsh()",
+			"pkh([d34db33/0]" + pubKeyG + ")":                           "Fingerprint is not 4 bytes (7 characters instead of 8 characters)",
+			"foo(" + pubKeyG + ")":                                      "'foo(" + pubKeyG + ")' is not a valid descriptor function",
+			"addr(1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMx)":                  "Invalid address \"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMx\"",
+		} {
+			desc, msg := desc, msg
+			It("should reject "+desc, func() {
+				_, err := Parse(desc)
+				Expect(err).To(MatchError(msg))
+			})
+		}
+	})
+})
+
+func mustScript(d *Descriptor) []byte {
+	script, err := d.ScriptPubKey(0)
+	if err != nil {
+		panic(err)
+	}
+	return script
+}
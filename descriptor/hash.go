@@ -0,0 +1,31 @@
+package descriptor
+
+import (
+	"crypto/sha256"
+
+	"github.com/toorop/go-bitcoind/address"
+)
+
+// hash160 returns RIPEMD160(SHA256(data)), used by P2PKH, P2WPKH and P2SH
+func hash160(data []byte) []byte {
+	return address.Hash160(data)
+}
+
+// doubleSha256 returns SHA256(SHA256(data))
+func doubleSha256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// taggedHash returns the BIP 340 tagged hash of msg
+func taggedHash(tag string, msg ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, m := range msg {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,222 @@
+package descriptor
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/toorop/go-bitcoind/address"
+)
+
+// hardenedKeyStart is the index of the first hardened child key
+const hardenedKeyStart = 0x80000000
+
+// Extended key version bytes
+var (
+	xpubVersion = []byte{0x04, 0x88, 0xb2, 0x1e}
+	tpubVersion = []byte{0x04, 0x35, 0x87, 0xcf}
+	xprvVersion = []byte{0x04, 0x88, 0xad, 0xe4}
+	tprvVersion = []byte{0x04, 0x35, 0x83, 0x94}
+)
+
+// An extendedKey represents a BIP 32 extended public key
+type extendedKey struct {
+	pubKey    *secp256k1.PublicKey
+	chainCode []byte
+}
+
+// child returns the non hardened child of index i, as BIP 32 CKDpub
+func (k extendedKey) child(i uint32) (extendedKey, error) {
+	if i >= hardenedKeyStart {
+		return extendedKey{}, errors.New("hardened derivation requires a private key")
+	}
+	data := make([]byte, 37)
+	copy(data, k.pubKey.SerializeCompressed())
+	binary.BigEndian.PutUint32(data[33:], i)
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	pubKey, err := tweakAdd(k.pubKey, sum[:32])
+	if err != nil {
+		return extendedKey{}, fmt.Errorf("invalid child key %d: %v", i, err)
+	}
+	return extendedKey{pubKey: pubKey, chainCode: sum[32:]}, nil
+}
+
+// parseExtendedKey decodes a base58 xpub or tpub
+func parseExtendedKey(str string) (extendedKey, error) {
+	data, err := address.Base58Decode(str)
+	if err != nil || len(data) != 82 || !bytes.Equal(doubleSha256(data[:78])[:4], data[78:]) {
+		return extendedKey{}, fmt.Errorf("key '%s' is not valid", str)
+	}
+	version := data[:4]
+	if bytes.Equal(version, xprvVersion) || bytes.Equal(version, tprvVersion) {
+		return extendedKey{}, errors.New("private keys are not supported")
+	}
+	if !bytes.Equal(version, xpubVersion) && !bytes.Equal(version, tpubVersion) {
+		return extendedKey{}, fmt.Errorf("key '%s' is not valid", str)
+	}
+	pubKey, err := parsePubKey(data[45:78])
+	if err != nil || len(data[45:78]) != 33 || data[45] == 0x04 {
+		return extendedKey{}, fmt.Errorf("key '%s' is not valid", str)
+	}
+	return extendedKey{pubKey: pubKey, chainCode: data[13:45]}, nil
+}
+
+// A key represents a key expression of a descriptor: an optional key origin
+// followed by either a hex public key or an extended key with a derivation
+// path
+type key struct {
+	// The origin: master key fingerprint and derivation path, hex encoded
+	// and as given, without brackets
+	origin string
+	// The public key, for a non extended key
+	pubKey []byte
+	// The extended key and the derivation path following it
+	ext  *extendedKey
+	path []uint32
+	// Whether the path ends with /*
+	ranged bool
+	// Whether keys are serialized as x-only
+	xOnly bool
+}
+
+// parseKey decodes a key expression in the given context
+func parseKey(str string, ctx context) (*key, error) {
+	k := &key{xOnly: ctx == ctxP2TR}
+	if strings.HasPrefix(str, "[") {
+		end := strings.IndexByte(str, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("Key origin start '[ character expected but not found, got '%s' instead", str)
+		}
+		k.origin = str[1:end]
+		if err := checkOrigin(k.origin); err != nil {
+			return nil, err
+		}
+		str = str[end+1:]
+	}
+
+	parts := strings.Split(str, "/")
+	if len(parts) == 1 {
+		pubKey, err := hex.DecodeString(str)
+		if err == nil {
+			return k, k.setPubKey(pubKey, ctx)
+		}
+		if version, payload, err := address.Base58CheckDecode(str); err == nil && (version == 0x80 || version == 0xef) && (len(payload) == 32 || len(payload) == 33) {
+			return nil, errors.New("private keys are not supported")
+		}
+	}
+	ext, err := parseExtendedKey(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	k.ext = &ext
+	for i, elem := range parts[1:] {
+		if elem == "*" && i == len(parts)-2 {
+			k.ranged = true
+			break
+		}
+		if elem == "*'" || elem == "*h" {
+			return nil, errors.New("hardened derivation requires a private key")
+		}
+		index, err := parsePathElement(elem)
+		if err != nil {
+			return nil, err
+		}
+		k.path = append(k.path, index)
+	}
+	// Derive the fixed part of the path once
+	for _, index := range k.path {
+		if ext, err = ext.child(index); err != nil {
+			return nil, err
+		}
+	}
+	k.ext = &ext
+	return k, nil
+}
+
+// setPubKey checks a hex public key is allowed in the context
+func (k *key) setPubKey(pubKey []byte, ctx context) error {
+	if ctx == ctxP2TR {
+		if len(pubKey) != 32 {
+			return fmt.Errorf("Pubkey '%s' is invalid, taproot keys must be x-only", hex.EncodeToString(pubKey))
+		}
+	} else if len(pubKey) == 32 {
+		return fmt.Errorf("Pubkey '%s' is invalid, x-only keys are only allowed in tr()", hex.EncodeToString(pubKey))
+	}
+	if _, err := parsePubKey(pubKey); err != nil {
+		return fmt.Errorf("Pubkey '%s' is invalid", hex.EncodeToString(pubKey))
+	}
+	if len(pubKey) == 65 && ctx == ctxWitnessV0 {
+		return errors.New("Uncompressed keys are not allowed in segwit descriptors")
+	}
+	k.pubKey = pubKey
+	return nil
+}
+
+// derive returns the serialized public key for the given index, ignored for
+// non ranged keys
+func (k *key) derive(index uint32) ([]byte, error) {
+	if k.ext == nil {
+		return k.pubKey, nil
+	}
+	ext := *k.ext
+	if k.ranged {
+		var err error
+		if ext, err = ext.child(index); err != nil {
+			return nil, err
+		}
+	}
+	if k.xOnly {
+		return xOnly(ext.pubKey), nil
+	}
+	return ext.pubKey.SerializeCompressed(), nil
+}
+
+// checkOrigin checks a key origin: a 4 bytes hex fingerprint followed by a
+// derivation path
+func checkOrigin(origin string) error {
+	parts := strings.Split(origin, "/")
+	if len(parts[0]) != 8 {
+		return fmt.Errorf("Fingerprint is not 4 bytes (%d characters instead of 8 characters)", len(parts[0]))
+	}
+	if _, err := hex.DecodeString(parts[0]); err != nil {
+		return fmt.Errorf("Fingerprint '%s' is not hex", parts[0])
+	}
+	for _, elem := range parts[1:] {
+		if _, err := parsePathElement(elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parsePathElement decodes a derivation path element, hardened ones ending
+// with ' or h
+func parsePathElement(elem string) (uint32, error) {
+	hardened := strings.HasSuffix(elem, "'") || strings.HasSuffix(elem, "h")
+	digits := strings.TrimRight(elem, "'h")
+	index, err := strconv.ParseUint(digits, 10, 32)
+	if err != nil || index >= hardenedKeyStart || len(digits) != len(elem)-boolToInt(hardened) {
+		return 0, fmt.Errorf("Key path value '%s' is not a valid uint32", elem)
+	}
+	if hardened {
+		index += hardenedKeyStart
+	}
+	return uint32(index), nil
+}
+
+// boolToInt returns 1 for true, 0 for false
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
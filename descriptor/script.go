@@ -0,0 +1,77 @@
+package descriptor
+
+import "encoding/binary"
+
+// Opcodes used by descriptor scripts
+const (
+	op0             = 0x00
+	opPushData1     = 0x4c
+	opPushData2     = 0x4d
+	op1             = 0x51
+	opDup           = 0x76
+	opEqual         = 0x87
+	opEqualVerify   = 0x88
+	opHash160       = 0xa9
+	opCheckSig      = 0xac
+	opCheckMultiSig = 0xae
+)
+
+// tapscriptLeafVersion is the BIP 342 leaf version
+const tapscriptLeafVersion = 0xc0
+
+// pushData returns the minimal push of data
+func pushData(data []byte) []byte {
+	var script []byte
+	switch {
+	case len(data) < opPushData1:
+		script = []byte{byte(len(data))}
+	case len(data) <= 0xff:
+		script = []byte{opPushData1, byte(len(data))}
+	default:
+		script = []byte{opPushData2, 0, 0}
+		binary.LittleEndian.PutUint16(script[1:], uint16(len(data)))
+	}
+	return append(script, data...)
+}
+
+// pushSmallInt returns the minimal push of a number up to 127
+func pushSmallInt(n int) []byte {
+	switch {
+	case n == 0:
+		return []byte{op0}
+	case n <= 16:
+		return []byte{byte(op1 - 1 + n)}
+	default:
+		return []byte{1, byte(n)}
+	}
+}
+
+// p2pkhScript returns the P2PKH scriptPubKey of a public key hash
+func p2pkhScript(pubKeyHash []byte) []byte {
+	script := []byte{opDup, opHash160, 20}
+	script = append(script, pubKeyHash...)
+	return append(script, opEqualVerify, opCheckSig)
+}
+
+// p2shScript returns the P2SH scriptPubKey of a script hash
+func p2shScript(scriptHash []byte) []byte {
+	script := []byte{opHash160, 20}
+	script = append(script, scriptHash...)
+	return append(script, opEqual)
+}
+
+// compactSize returns the Bitcoin variable length integer encoding of n
+func compactSize(n int) []byte {
+	switch {
+	case n < 0xfd:
+		return []byte{byte(n)}
+	case n <= 0xffff:
+		out := []byte{0xfd, 0, 0}
+		binary.LittleEndian.PutUint16(out[1:], uint16(n))
+		return out
+	default:
+		out := []byte{0xfe, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(out[1:], uint32(n))
+		return out
+	}
+}
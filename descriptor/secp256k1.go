@@ -0,0 +1,39 @@
+package descriptor
+
+import (
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// parsePubKey decodes a compressed, uncompressed or x-only public key. An
+// x-only key is lifted to its even y point.
+func parsePubKey(key []byte) (*secp256k1.PublicKey, error) {
+	if len(key) == 32 {
+		key = append([]byte{secp256k1.PubKeyFormatCompressedEven}, key...)
+	}
+	return secp256k1.ParsePubKey(key)
+}
+
+// tweakAdd returns p + t*G, failing when t is not a valid scalar or the
+// result is the point at infinity
+func tweakAdd(p *secp256k1.PublicKey, t []byte) (*secp256k1.PublicKey, error) {
+	var k secp256k1.ModNScalar
+	if len(t) != 32 || k.SetByteSlice(t) {
+		return nil, errors.New("tweak is out of the curve order")
+	}
+	var tG, point, sum secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&k, &tG)
+	p.AsJacobian(&point)
+	secp256k1.AddNonConst(&point, &tG, &sum)
+	if (sum.X.IsZero() && sum.Y.IsZero()) || sum.Z.IsZero() {
+		return nil, errors.New("tweaked key is the point at infinity")
+	}
+	sum.ToAffine()
+	return secp256k1.NewPublicKey(&sum.X, &sum.Y), nil
+}
+
+// xOnly returns the 32 bytes BIP 340 encoding of p
+func xOnly(p *secp256k1.PublicKey) []byte {
+	return p.SerializeCompressed()[1:]
+}
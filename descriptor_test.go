@@ -0,0 +1,141 @@
+package bitcoind
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Descriptor", func() {
+	Describe("Testing GetDescriptorInfo", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"descriptor":"wpkh([d34db33f/84h/0h/0h]0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798)#qwlqgth7","checksum":"tmaeg7s7","isrange":false,"issolvable":true,"hasprivatekeys":false},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			info, err := bitcoindClient.GetDescriptorInfo("wpkh([d34db33f/84'/0'/0']0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798)")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return", func() {
+				Expect(info).To(Equal(DescriptorInfo{
+					Descriptor: "wpkh([d34db33f/84h/0h/0h]0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798)#qwlqgth7",
+					Checksum:   "tmaeg7s7",
+					IsSolvable: true,
+				}))
+			})
+		})
+	})
+
+	Describe("Testing DeriveAddresses", func() {
+		Context("when success with a range", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":["bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu","bc1qngw83fg8dz0k749cg7k3emc7v98wy0c74dlrkd"],"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			addresses, err := bitcoindClient.DeriveAddresses("wpkh(xpub/0/*)#abcdefgh", &DescriptorRange{Begin: 0, End: 1})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the range as [begin,end]", func() {
+				Expect(params).To(Equal([]interface{}{"wpkh(xpub/0/*)#abcdefgh", []interface{}{float64(0), float64(1)}}))
+			})
+			It("should return", func() {
+				Expect(addresses).To(Equal([]string{"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", "bc1qngw83fg8dz0k749cg7k3emc7v98wy0c74dlrkd"}))
+			})
+		})
+	})
+
+	Describe("Testing ImportDescriptors", func() {
+		Context("when some imports fail", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":[{"success":true,"warnings":["Range not given, using default keypool range"]},{"success":false,"error":{"code":-5,"message":"Invalid descriptor"}}],"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			active := true
+			timestamp := int64(1455191478)
+			results, err := bitcoindClient.ImportDescriptors([]ImportDescriptorRequest{
+				{Desc: "wpkh(xpub/0/*)#abcdefgh", Active: &active, Range: &DescriptorRange{End: 999}, Timestamp: &timestamp},
+				{Desc: "bad", Label: "cold"},
+			})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the requests", func() {
+				Expect(params).To(Equal([]interface{}{[]interface{}{
+					map[string]interface{}{"desc": "wpkh(xpub/0/*)#abcdefgh", "active": true, "range": []interface{}{float64(0), float64(999)}, "timestamp": float64(1455191478)},
+					map[string]interface{}{"desc": "bad", "label": "cold", "timestamp": "now"},
+				}}))
+			})
+			It("should return per request results", func() {
+				Expect(results).To(Equal([]ImportDescriptorResult{
+					{Success: true, Warnings: []string{"Range not given, using default keypool range"}},
+					{Success: false, Error: &RPCError{Code: -5, Message: "Invalid descriptor"}},
+				}))
+			})
+		})
+		Context("when no request is given", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.ImportDescriptors(nil)
+			It("should error", func() {
+				Expect(err).To(MatchError("Bad parameters for ImportDescriptors: you must set at least one request"))
+			})
+		})
+	})
+
+	Describe("Testing ListDescriptors", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"wallet_name":"main","descriptors":[{"desc":"wpkh(xpub/0/*)#abcdefgh","timestamp":1455191478,"active":true,"internal":false,"range":[0,999],"next":12,"next_index":12},{"desc":"addr(bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu)#qwertyui","timestamp":1455191480,"active":false}]},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.ListDescriptors(true)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the private flag", func() {
+				Expect(params).To(Equal([]interface{}{true}))
+			})
+			It("should return", func() {
+				internal := false
+				next := uint32(12)
+				Expect(result).To(Equal(ListDescriptorsResult{
+					WalletName: "main",
+					Descriptors: []WalletDescriptor{
+						{Desc: "wpkh(xpub/0/*)#abcdefgh", Timestamp: 1455191478, Active: true, Internal: &internal, Range: &DescriptorRange{End: 999}, Next: &next, NextIndex: &next},
+						{Desc: "addr(bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu)#qwertyui", Timestamp: 1455191480},
+					},
+				}))
+			})
+		})
+	})
+})
@@ -3,9 +3,11 @@ module github.com/toorop/go-bitcoind
 go 1.18
 
 require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.2.0
 	github.com/onsi/ginkgo v1.16.5
 	github.com/onsi/gomega v1.19.0
 	github.com/toorop/go-bitcoind v0.0.0-20201025081558-87ada228a807
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 )
 
 require (
@@ -0,0 +1,215 @@
+package bitcoind
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/toorop/go-bitcoind/pow"
+)
+
+// BlockHeaderSize is the size in bytes of a serialized block header
+const BlockHeaderSize = 80
+
+// medianTimeSpan is the number of previous headers used to compute the
+// median time past
+const medianTimeSpan = 11
+
+// maxHeaderRangeAlloc caps the headers allocated ahead by GetBlockHeaderRange,
+// the number of headers of a headers message
+const maxHeaderRangeAlloc = 2000
+
+// A RawBlockHeader represents a block header decoded from its 80 bytes
+// serialized form, as returned by GetBlockHeaderRaw
+type RawBlockHeader struct {
+	// The block version
+	Version int32
+
+	// The hash of the previous block
+	Previousblockhash string
+
+	// The merkle root
+	Merkleroot string
+
+	// The block time in seconds since epoch (Jan 1 1970 GMT)
+	Time uint32
+
+	// The compact representation of the target
+	Bits uint32
+
+	// The nonce
+	Nonce uint32
+}
+
+// ParseBlockHeader decodes an hex encoded 80 bytes block header
+func ParseBlockHeader(rawHex string) (header RawBlockHeader, err error) {
+	data, err := hex.DecodeString(rawHex)
+	if err != nil {
+		return
+	}
+	return DecodeBlockHeader(data)
+}
+
+// DecodeBlockHeader decodes a 80 bytes serialized block header
+func DecodeBlockHeader(data []byte) (header RawBlockHeader, err error) {
+	if len(data) != BlockHeaderSize {
+		err = fmt.Errorf("Bad block header size: %d bytes, expecting %d", len(data), BlockHeaderSize)
+		return
+	}
+	header.Version = int32(binary.LittleEndian.Uint32(data[0:4]))
+	header.Previousblockhash = hashToString(data[4:36])
+	header.Merkleroot = hashToString(data[36:68])
+	header.Time = binary.LittleEndian.Uint32(data[68:72])
+	header.Bits = binary.LittleEndian.Uint32(data[72:76])
+	header.Nonce = binary.LittleEndian.Uint32(data[76:80])
+	return
+}
+
+// Serialize returns the 80 bytes serialized form of the header
+func (h RawBlockHeader) Serialize() ([]byte, error) {
+	prev, err := hashFromString(h.Previousblockhash)
+	if err != nil {
+		return nil, err
+	}
+	merkleroot, err := hashFromString(h.Merkleroot)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, BlockHeaderSize)
+	binary.LittleEndian.PutUint32(data[0:4], uint32(h.Version))
+	copy(data[4:36], prev)
+	copy(data[36:68], merkleroot)
+	binary.LittleEndian.PutUint32(data[68:72], h.Time)
+	binary.LittleEndian.PutUint32(data[72:76], h.Bits)
+	binary.LittleEndian.PutUint32(data[76:80], h.Nonce)
+	return data, nil
+}
+
+// Hash returns the block hash of the header
+func (h RawBlockHeader) Hash() (string, error) {
+	data, err := h.Serialize()
+	if err != nil {
+		return "", err
+	}
+	return hashToString(doubleSha256(data)), nil
+}
+
+// CheckProofOfWork checks the header hash is below the target encoded in bits
+func (h RawBlockHeader) CheckProofOfWork() error {
+	target, err := pow.CompactToBig(h.Bits)
+	if err != nil {
+		return err
+	}
+	hash, err := h.Hash()
+	if err != nil {
+		return err
+	}
+	hashNum, _ := new(big.Int).SetString(hash, 16)
+	if hashNum.Cmp(target) > 0 {
+		return fmt.Errorf("Block hash %s is above target %064x", hash, target)
+	}
+	return nil
+}
+
+// ValidateHeaderChain checks a range of consecutive headers, ordered by
+// height: proof of work of each header, linkage to the previous header and
+// time strictly greater than the median time past of the 11 previous headers.
+// The median time past is only checked for headers having 11 predecessors in
+// the range, so callers should include the 11 headers preceding the ones they
+// want fully validated.
+func ValidateHeaderChain(headers []RawBlockHeader) error {
+	var prevHash string
+	for i, header := range headers {
+		hash, err := header.Hash()
+		if err != nil {
+			return fmt.Errorf("Header %d: %v", i, err)
+		}
+		if err = header.CheckProofOfWork(); err != nil {
+			return fmt.Errorf("Header %d (%s): %v", i, hash, err)
+		}
+		if i > 0 && header.Previousblockhash != prevHash {
+			return fmt.Errorf("Header %d (%s): previous block hash %s does not match %s", i, hash, header.Previousblockhash, prevHash)
+		}
+		if i >= medianTimeSpan {
+			mtp := medianTime(headers[i-medianTimeSpan : i])
+			if header.Time <= mtp {
+				return fmt.Errorf("Header %d (%s): time %d is not after median time past %d", i, hash, header.Time, mtp)
+			}
+		}
+		prevHash = hash
+	}
+	return nil
+}
+
+// GetBlockHeaderRange returns [count] consecutive raw headers of the best
+// block chain starting at height [start]
+func (b *Bitcoind) GetBlockHeaderRange(start, count uint64) (headers []RawBlockHeader, err error) {
+	if start+count < start {
+		return nil, errors.New("Bad parameters for GetBlockHeaderRange: start + count overflows")
+	}
+	capacity := count
+	if capacity > maxHeaderRangeAlloc {
+		capacity = maxHeaderRangeAlloc
+	}
+	headers = make([]RawBlockHeader, 0, capacity)
+	for height := start; height < start+count; height++ {
+		var hash, rawHex string
+		if hash, err = b.GetBlockHash(height); err != nil {
+			return nil, err
+		}
+		if rawHex, err = b.GetBlockHeaderRaw(hash); err != nil {
+			return nil, err
+		}
+		var header RawBlockHeader
+		if header, err = ParseBlockHeader(rawHex); err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	}
+	return
+}
+
+// medianTime returns the median time of headers
+func medianTime(headers []RawBlockHeader) uint32 {
+	times := make([]uint32, len(headers))
+	for i, header := range headers {
+		times[i] = header.Time
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times[len(times)/2]
+}
+
+// doubleSha256 returns sha256(sha256(data))
+func doubleSha256(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// hashToString returns the hex representation of an internal byte order hash
+func hashToString(hash []byte) string {
+	reversed := make([]byte, len(hash))
+	for i, b := range hash {
+		reversed[len(hash)-1-i] = b
+	}
+	return hex.EncodeToString(reversed)
+}
+
+// hashFromString returns the internal byte order of an hex hash
+func hashFromString(str string) ([]byte, error) {
+	hash, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+	if len(hash) != sha256.Size {
+		return nil, errors.New("Bad hash length: " + str)
+	}
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+	return hash, nil
+}
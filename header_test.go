@@ -0,0 +1,140 @@
+package bitcoind
+
+import (
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// mustDecodeHex decodes an hex string known to be valid
+func mustDecodeHex(str string) []byte {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// mineRegtestHeader grinds the nonce of a regtest difficulty header until it
+// satisfies its proof of work
+func mineRegtestHeader(prev string, time uint32) RawBlockHeader {
+	header := RawBlockHeader{
+		Version:           0x20000000,
+		Previousblockhash: prev,
+		Merkleroot:        "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+		Time:              time,
+		Bits:              0x207fffff,
+	}
+	for header.CheckProofOfWork() != nil {
+		header.Nonce++
+	}
+	return header
+}
+
+// regtestChain returns a chain of n mined headers with the given times
+func regtestChain(times ...uint32) []RawBlockHeader {
+	prev := "0f9188f13cb7b2c71f2a335e3a4fc328bf5beb436012afca590b1a11466e2206"
+	headers := make([]RawBlockHeader, 0, len(times))
+	for _, time := range times {
+		header := mineRegtestHeader(prev, time)
+		prev, _ = header.Hash()
+		headers = append(headers, header)
+	}
+	return headers
+}
+
+var _ = Describe("Header", func() {
+	genesis := "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+	block1 := "010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299"
+	block2 := "010000004860eb18bf1b1620e37e9490fc8a427514416fd75159ab86688e9a8300000000d5fdcc541e25de1c7a5addedf24858b8bb665c9f36ef744ee42c316022c90f9bb0bc6649ffff001d08d2bd61"
+
+	Describe("Parse a block header", func() {
+		Context("when header is the mainnet genesis", func() {
+			header, err := ParseBlockHeader(genesis)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should decode the fields", func() {
+				Expect(header).To(Equal(RawBlockHeader{
+					Version:           1,
+					Previousblockhash: "0000000000000000000000000000000000000000000000000000000000000000",
+					Merkleroot:        "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+					Time:              1231006505,
+					Bits:              0x1d00ffff,
+					Nonce:             2083236893,
+				}))
+			})
+			It("should compute the block hash", func() {
+				Expect(header.Hash()).To(Equal("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"))
+			})
+			It("should serialize back to the same bytes", func() {
+				data, err := header.Serialize()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(Equal(mustDecodeHex(genesis)))
+			})
+		})
+		Context("when header has a bad size", func() {
+			_, err := ParseBlockHeader(genesis[:158])
+			It("error should occured", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Validate a header chain", func() {
+		Context("when headers are the first mainnet blocks", func() {
+			var headers []RawBlockHeader
+			for _, raw := range []string{genesis, block1, block2} {
+				header, _ := ParseBlockHeader(raw)
+				headers = append(headers, header)
+			}
+			It("should not error", func() {
+				Expect(ValidateHeaderChain(headers)).To(Succeed())
+			})
+		})
+		Context("when headers are not linked", func() {
+			var headers []RawBlockHeader
+			for _, raw := range []string{genesis, block2} {
+				header, _ := ParseBlockHeader(raw)
+				headers = append(headers, header)
+			}
+			It("error should occured", func() {
+				Expect(ValidateHeaderChain(headers)).To(MatchError(ContainSubstring("previous block hash")))
+			})
+		})
+		Context("when proof of work is invalid", func() {
+			header, _ := ParseBlockHeader(block1)
+			header.Nonce++
+			It("error should occured", func() {
+				Expect(ValidateHeaderChain([]RawBlockHeader{header})).To(MatchError(ContainSubstring("above target")))
+			})
+		})
+		Context("when time is after the median time past", func() {
+			headers := regtestChain(100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110, 106)
+			It("should not error", func() {
+				Expect(ValidateHeaderChain(headers)).To(Succeed())
+			})
+		})
+		Context("when time is not after the median time past", func() {
+			headers := regtestChain(100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110, 105)
+			It("error should occured", func() {
+				Expect(ValidateHeaderChain(headers)).To(MatchError(ContainSubstring("median time past")))
+			})
+		})
+	})
+
+	Describe("Get a range of headers", func() {
+		Context("when the range overflows", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(nil, &calls)
+			defer done()
+			headers, err := bitcoindClient.GetBlockHeaderRange(1, ^uint64(0))
+			It("error should occured", func() {
+				Expect(err).To(MatchError(ContainSubstring("Bad parameters for GetBlockHeaderRange")))
+				Expect(headers).To(BeNil())
+				Expect(calls).To(BeEmpty())
+			})
+		})
+	})
+})
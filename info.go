@@ -13,7 +13,7 @@ type Info struct {
 	Walletversion uint32 `json:"walletversion"`
 
 	// The total bitcoin balance of the wallet
-	Balance float64 `json:"balance"`
+	Balance Amount `json:"balance"`
 
 	// The current number of blocks processed in the server
 	Blocks uint32 `json:"blocks"`
@@ -42,11 +42,11 @@ type Info struct {
 	// The timestamp in seconds since epoch (midnight Jan 1 1970 GMT) that the wallet is unlocked for transfers, or 0 if the wallet is locked
 	UnlockedUntil int64 `json:"unlocked_until,omitempty"`
 
-	// the transaction fee set in btc/kb
-	Paytxfee float64 `json:"paytxfee"`
+	// the transaction fee set per kB
+	Paytxfee Amount `json:"paytxfee"`
 
-	// Minimum relay fee for non-free transactions in btc/kb
-	Relayfee float64 `json:"relayfee"`
+	// Minimum relay fee per kB for non-free transactions
+	Relayfee Amount `json:"relayfee"`
 
 	//  Any error messages
 	Errors string `json:"errors"`
@@ -57,14 +57,14 @@ type Info struct {
 type WalletInfo struct {
 	WalletName            string  `json:"walletname"`
 	WalletVersion         float64 `json:"walletversion"`
-	Balance               float64 `json:"balance"`
-	UnconfirmedBalance    float64 `json:"unconfirmed_balance"`
-	ImmatureBalance       float64 `json:"immature_balance"`
+	Balance               Amount  `json:"balance"`
+	UnconfirmedBalance    Amount  `json:"unconfirmed_balance"`
+	ImmatureBalance       Amount  `json:"immature_balance"`
 	TxCount               int64   `json:"txcount"`
 	KeyPoolOldest         int64   `json:"keypoololdest"`
 	KeyPoolSize           int64   `json:"keypoolsize"`
 	KeyPoolSizeHdInternal int64   `json:"keypoolsize_hd_internal"`
 	UnlockedUntil         *int64  `json:"unlocked_until"`
-	PaytxFee              float64 `json:"paytxfee"`
+	PaytxFee              Amount  `json:"paytxfee"`
 	HdMasterKeyID         *string `json:"hdmasterkeyid"`
 }
\ No newline at end of file
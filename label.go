@@ -0,0 +1,208 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// Error codes of bitcoind telling a RPC is not available
+const (
+	// The RPC does not exist, like account RPCs since Core 0.18
+	rpcMethodNotFound RPCErrorCode = -32601
+	// The RPC is deprecated and disabled, like account RPCs in Core 0.17
+	rpcMethodDeprecated RPCErrorCode = -32
+	// The label does not exist
+	rpcWalletInvalidLabelName RPCErrorCode = -11
+)
+
+// ErrMoveUnsupported is returned by Move on nodes without account RPCs
+var ErrMoveUnsupported = errors.New("Move is not supported by nodes without accounts")
+
+// ErrSendFromUnsupported is returned by SendFrom on nodes without account RPCs
+var ErrSendFromUnsupported = errors.New("SendFrom is not supported by nodes without accounts")
+
+// Address types of "getnewaddress" and "getrawchangeaddress" calls
+const (
+	AddressTypeLegacy     = "legacy"
+	AddressTypeP2SHSegwit = "p2sh-segwit"
+	AddressTypeBech32     = "bech32"
+	AddressTypeBech32m    = "bech32m"
+)
+
+// LabelAddress represents an address of a label, as returned by
+// "getaddressesbylabel" call
+type LabelAddress struct {
+	// Purpose of address ("send" for sending address, "receive" for
+	// receiving address)
+	Purpose string `json:"purpose"`
+}
+
+// ReceivedByLabel represents how much coin a label has received
+type ReceivedByLabel struct {
+	// Only returned if imported addresses were involved in transaction
+	InvolvesWatchonly bool `json:"involvesWatchonly,omitempty"`
+	// The total amount received by addresses with this label
+	Amount Amount `json:"amount"`
+	// The number of confirmations of the most recent transaction included
+	Confirmations uint32 `json:"confirmations"`
+	// The label of the receiving addresses
+	Label string `json:"label"`
+}
+
+// GetAddressesByLabel returns the addresses assigned to a label, indexed by
+// address
+func (b *Bitcoind) GetAddressesByLabel(label string) (addresses map[string]LabelAddress, err error) {
+	r, err := b.client.call("getaddressesbylabel", []string{label})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &addresses)
+	return
+}
+
+// ListLabels returns the labels of the wallet.
+// [purpose] (0 or 1 value) restricts the labels to the addresses with that
+// purpose, "send" or "receive".
+func (b *Bitcoind) ListLabels(purpose ...string) (labels []string, err error) {
+	if len(purpose) > 1 {
+		err = errors.New("Bad parameters for ListLabels: you can set 0 or 1 purpose")
+		return
+	}
+	r, err := b.client.call("listlabels", purpose)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &labels)
+	return
+}
+
+// SetLabel sets the label associated with the given address
+func (b *Bitcoind) SetLabel(address, label string) error {
+	r, err := b.client.call("setlabel", []string{address, label})
+	return handleError(err, &r)
+}
+
+// GetReceivedByLabel returns the total amount received by addresses with
+// [label] in transactions with at least [minconf] confirmations
+func (b *Bitcoind) GetReceivedByLabel(label string, minconf uint32) (amount float64, err error) {
+	r, err := b.client.call("getreceivedbylabel", []interface{}{label, minconf})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &amount)
+	return
+}
+
+// ListReceivedByLabel returns the amounts received by each label
+func (b *Bitcoind) ListReceivedByLabel(minConf uint32, includeEmpty, includeWatchOnly bool) (list []ReceivedByLabel, err error) {
+	r, err := b.client.call("listreceivedbylabel", []interface{}{minConf, includeEmpty, includeWatchOnly})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &list)
+	return
+}
+
+// GetNewAddressWithType returns a new address with the given label and
+// address type, one of the AddressType constants or "" for the node default
+func (b *Bitcoind) GetNewAddressWithType(label, addressType string) (addr string, err error) {
+	params := []string{label}
+	if addressType != "" {
+		params = append(params, addressType)
+	}
+	r, err := b.client.call("getnewaddress", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &addr)
+	return
+}
+
+// accountsUnsupported checks a call failed because the node has no account
+// RPCs, in which case account methods fall back to labels
+func accountsUnsupported(err error, r *rpcResponse) bool {
+	return err == nil && r.Err != nil && (r.Err.Code == rpcMethodNotFound || r.Err.Code == rpcMethodDeprecated)
+}
+
+// labelOf returns the label of an address, "" if it has none
+func (b *Bitcoind) labelOf(address string) (label string, err error) {
+	info, err := b.GetAddressInfo(address)
+	if err != nil || len(info.Labels) == 0 {
+		return
+	}
+	return info.Labels[0], nil
+}
+
+// labelAddresses returns the sorted receiving addresses of a label, none if
+// the label does not exist. Sending addresses, labelled address book entries
+// of other people, are left out.
+func (b *Bitcoind) labelAddresses(label string) (addresses []string, err error) {
+	r, err := b.client.call("getaddressesbylabel", []string{label})
+	if err == nil && r.Err != nil && r.Err.Code == rpcWalletInvalidLabelName {
+		return []string{}, nil
+	}
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	var byAddress map[string]LabelAddress
+	if err = json.Unmarshal(r.Result, &byAddress); err != nil {
+		return
+	}
+	addresses = make([]string, 0, len(byAddress))
+	for address, info := range byAddress {
+		if info.Purpose == "receive" {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+	return
+}
+
+// labelBalances returns the balance of each label, computed from the wallet
+// unspent outputs with at least [minconf] confirmations
+func (b *Bitcoind) labelBalances(minconf int32) (balances map[string]float64, err error) {
+	labels, err := b.ListLabels()
+	if err != nil {
+		return
+	}
+	if minconf < 0 {
+		minconf = 0
+	}
+	unspents, err := b.ListUnspentWithOptions(uint32(minconf), MaxConfirmations, nil)
+	if err != nil {
+		return
+	}
+	sums := make(map[string]Amount, len(labels))
+	for _, label := range labels {
+		sums[label] = 0
+	}
+	for _, unspent := range unspents {
+		if sums[unspent.Label], err = SumAmounts(sums[unspent.Label], unspent.Amount); err != nil {
+			return
+		}
+	}
+	balances = make(map[string]float64, len(sums))
+	for label, sum := range sums {
+		balances[label] = sum.ToBTC()
+	}
+	return
+}
+
+// receivedByAllLabels returns the total amount received by the addresses of
+// every label, summed in satoshis
+func (b *Bitcoind) receivedByAllLabels(minconf uint32) (amount float64, err error) {
+	list, err := b.ListReceivedByLabel(minconf, false, false)
+	if err != nil {
+		return
+	}
+	amounts := make([]Amount, len(list))
+	for i, received := range list {
+		amounts[i] = received.Amount
+	}
+	total, err := SumAmounts(amounts...)
+	if err != nil {
+		return
+	}
+	return total.ToBTC(), nil
+}
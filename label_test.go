@@ -0,0 +1,261 @@
+package bitcoind
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Label", func() {
+	Describe("Testing GetAddressesByLabel", func() {
+		Context("when success", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getaddressesbylabel": `{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4":{"purpose":"receive"}}`,
+			}, &calls)
+			defer done()
+			addresses, err := bitcoindClient.GetAddressesByLabel("savings")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the label", func() {
+				Expect(calls).To(Equal([]string{"getaddressesbylabel [savings]"}))
+			})
+			It("should return", func() {
+				Expect(addresses).To(Equal(map[string]LabelAddress{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4": {Purpose: "receive"}}))
+			})
+		})
+	})
+
+	Describe("Testing ListLabels", func() {
+		Context("when a purpose is given", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"listlabels": `["","savings"]`}, &calls)
+			defer done()
+			labels, err := bitcoindClient.ListLabels("receive")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the purpose", func() {
+				Expect(calls).To(Equal([]string{"listlabels [receive]"}))
+			})
+			It("should return", func() {
+				Expect(labels).To(Equal([]string{"", "savings"}))
+			})
+		})
+		Context("when two purposes are given", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.ListLabels("send", "receive")
+			It("should error", func() {
+				Expect(err).To(MatchError("Bad parameters for ListLabels: you can set 0 or 1 purpose"))
+			})
+		})
+	})
+
+	Describe("Testing ListReceivedByLabel", func() {
+		Context("when success", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"listreceivedbylabel": `[{"amount":0.00020000,"confirmations":12,"label":"savings"},{"involvesWatchonly":true,"amount":1.5,"confirmations":3,"label":"cold"}]`,
+			}, &calls)
+			defer done()
+			list, err := bitcoindClient.ListReceivedByLabel(1, true, true)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the parameters", func() {
+				Expect(calls).To(Equal([]string{"listreceivedbylabel [1 true true]"}))
+			})
+			It("should return", func() {
+				Expect(list).To(Equal([]ReceivedByLabel{
+					{Amount: 20000, Confirmations: 12, Label: "savings"},
+					{InvolvesWatchonly: true, Amount: 150000000, Confirmations: 3, Label: "cold"},
+				}))
+			})
+		})
+	})
+
+	Describe("Testing GetNewAddressWithType", func() {
+		Context("when success", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"getnewaddress": `"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"`}, &calls)
+			defer done()
+			addr, err := bitcoindClient.GetNewAddressWithType("savings", AddressTypeBech32)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the label and address type", func() {
+				Expect(calls).To(Equal([]string{"getnewaddress [savings bech32]"}))
+			})
+			It("should return", func() {
+				Expect(addr).To(Equal("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"))
+			})
+		})
+	})
+
+	Describe("Account methods on a node without accounts", func() {
+		Context("GetAccount", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"getaddressinfo": `{"address":"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy","labels":["tests"]}`}, &calls)
+			defer done()
+			account, err := bitcoindClient.GetAccount("1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy")
+			It("should return the label of the address", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(account).To(Equal("tests"))
+				Expect(calls).To(Equal([]string{"getaccount [1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy]", "getaddressinfo [1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy]"}))
+			})
+		})
+		Context("GetAccount on a node returning label objects", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"getaddressinfo": `{"labels":[{"name":"tests","purpose":"receive"}]}`}, &calls)
+			defer done()
+			account, err := bitcoindClient.GetAccount("1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy")
+			It("should return the label name", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(account).To(Equal("tests"))
+			})
+		})
+		Context("SetAccount", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"setlabel": `null`}, &calls)
+			defer done()
+			err := bitcoindClient.SetAccount("1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy", "tests")
+			It("should set the label", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls[1]).To(Equal("setlabel [1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy tests]"))
+			})
+		})
+		Context("GetAccountAddress for a label with addresses", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getaddressesbylabel": `{"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy":{"purpose":"receive"},"1Bvs4PDXKsjPqsfftuXLteDf7bn5PRdyQV":{"purpose":"receive"},"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa":{"purpose":"send"}}`,
+			}, &calls)
+			defer done()
+			address, err := bitcoindClient.GetAccountAddress("tests")
+			It("should return the first receiving address", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(Equal("1Bvs4PDXKsjPqsfftuXLteDf7bn5PRdyQV"))
+			})
+		})
+		Context("GetAccountAddress for a label with only sending addresses", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getaddressesbylabel": `{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa":{"purpose":"send"}}`,
+				"getnewaddress":       `"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy"`,
+			}, &calls)
+			defer done()
+			address, err := bitcoindClient.GetAccountAddress("tests")
+			It("should create an address with the label", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(Equal("1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy"))
+				Expect(calls).To(Equal([]string{"getaccountaddress [tests]", "getaddressesbylabel [tests]", "getnewaddress [tests]"}))
+			})
+		})
+		Context("GetAccountAddress for an unknown label", func() {
+			var calls []string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method, params := decodeRequest(r)
+				calls = append(calls, fmt.Sprintf("%s %v", method, params))
+				switch method {
+				case "getaddressesbylabel":
+					fmt.Fprintln(w, `{"result":null,"error":{"code":-11,"message":"No addresses with label tests"},"id":1400502065079564568}`)
+				case "getnewaddress":
+					fmt.Fprintln(w, `{"result":"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy","error":null,"id":1400502065079564568}`)
+				default:
+					fmt.Fprintln(w, methodNotFound)
+				}
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			address, err := bitcoindClient.GetAccountAddress("tests")
+			It("should create an address with the label", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(Equal("1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy"))
+				Expect(calls).To(Equal([]string{"getaccountaddress [tests]", "getaddressesbylabel [tests]", "getnewaddress [tests]"}))
+			})
+		})
+		Context("GetAddressesByAccount", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getaddressesbylabel": `{"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy":{"purpose":"receive"},"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa":{"purpose":"send"},"1Bvs4PDXKsjPqsfftuXLteDf7bn5PRdyQV":{"purpose":"receive"}}`,
+			}, &calls)
+			defer done()
+			addresses, err := bitcoindClient.GetAddressesByAccount("tests")
+			It("should return the sorted receiving addresses of the label", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(addresses).To(Equal([]string{"1Bvs4PDXKsjPqsfftuXLteDf7bn5PRdyQV", "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy"}))
+			})
+		})
+		Context("ListAccounts", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"listlabels":  `["","savings","empty"]`,
+				"listunspent": `[{"amount":0.1,"label":"savings"},{"amount":0.2,"label":"savings"},{"amount":0.5}]`,
+			}, &calls)
+			defer done()
+			accounts, err := bitcoindClient.ListAccounts(1)
+			It("should return the balance of each label", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(accounts).To(Equal(map[string]float64{"": 0.5, "savings": 0.3, "empty": 0}))
+			})
+		})
+		Context("GetReceivedByAccount", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"getreceivedbylabel": `0.0002`}, &calls)
+			defer done()
+			amount, err := bitcoindClient.GetReceivedByAccount("tests", 1)
+			It("should return the amount received by the label", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(amount).To(Equal(0.0002))
+				Expect(calls[1]).To(Equal("getreceivedbylabel [tests 1]"))
+			})
+		})
+		Context("GetReceivedByAccount for all accounts", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"listreceivedbylabel": `[{"amount":0.1,"confirmations":12,"label":""},{"amount":0.2,"confirmations":3,"label":"tests"}]`}, &calls)
+			defer done()
+			amount, err := bitcoindClient.GetReceivedByAccount("all", 1)
+			It("should sum the amounts received by every label", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(amount).To(Equal(0.3))
+				Expect(calls[1]).To(Equal("listreceivedbylabel [1 false false]"))
+			})
+		})
+		Context("ListReceivedByAccount", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"listreceivedbylabel": `[{"amount":0.0002,"confirmations":12,"label":"tests"}]`}, &calls)
+			defer done()
+			list, err := bitcoindClient.ListReceivedByAccount(1, true)
+			It("should return the amounts received by labels", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(list).To(Equal([]ReceivedByAccount{{Account: "tests", Amount: 20000, Confirmations: 12}}))
+			})
+		})
+		Context("SendFrom", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"sendtoaddress": `"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"`}, &calls)
+			defer done()
+			_, err := bitcoindClient.SendFrom("tests", "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy", 0.1, 1, "comment", "to")
+			It("should error without sending from the wallet", func() {
+				Expect(err).To(Equal(ErrSendFromUnsupported))
+				Expect(calls).To(Equal([]string{"sendfrom [tests 1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy 0.1 1 comment to]"}))
+			})
+		})
+		Context("Move", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{}, &calls)
+			defer done()
+			_, err := bitcoindClient.Move("tests", "other", 0.1, 1, "")
+			It("should error", func() {
+				Expect(err).To(Equal(ErrMoveUnsupported))
+			})
+		})
+	})
+})
@@ -0,0 +1,173 @@
+package bitcoind
+
+import "encoding/json"
+
+// MempoolFees represents the fees of a mempool entry
+type MempoolFees struct {
+	// Transaction fee
+	Base Amount `json:"base"`
+	// Transaction fee with fee deltas used for mining priority
+	Modified Amount `json:"modified"`
+	// Modified fees of in-mempool ancestors (including this one)
+	Ancestor Amount `json:"ancestor"`
+	// Modified fees of in-mempool descendants (including this one)
+	Descendant Amount `json:"descendant"`
+}
+
+// VerboseTx represents a mempool entry, as returned by getrawmempool with
+// verbose set, getmempoolentry, getmempoolancestors and getmempooldescendants
+type VerboseTx struct {
+	// Virtual transaction size as defined in BIP 141 (removed in 0.19, see Vsize)
+	Size uint32 `json:"size,omitempty"`
+	// Virtual transaction size as defined in BIP 141
+	Vsize uint32 `json:"vsize"`
+	// Transaction weight as defined in BIP 141
+	Weight uint32 `json:"weight"`
+	// Transaction fee (removed in 23.0, see Fees)
+	Fee Amount `json:"fee,omitempty"`
+	// Transaction fee with fee deltas used for mining priority (removed in 23.0, see Fees)
+	ModifiedFee Amount `json:"modifiedfee,omitempty"`
+	// Local time when tx entered pool
+	Time uint32 `json:"time"`
+	// Block height when tx entered pool
+	Height uint32 `json:"height"`
+	// Number of inpool descendents (including this one)
+	DescendantCount uint32 `json:"descendantcount"`
+	// Virtual transaction size of in-mempool descendants (including this one)
+	DescendantSize uint32 `json:"descendantsize"`
+	// Modified fees (see above) of in-mempool descendants (including this one) in satoshis (removed in 23.0, see Fees)
+	DescendantFees uint64 `json:"descendantfees,omitempty"`
+	// Number of in-mempool ancestor transactions (including this one)
+	AncestorCount uint32 `json:"ancestorcount"`
+	// Virtual transaction size of in-mempool ancestors (including this one)
+	AncestorSize uint32 `json:"ancestorsize"`
+	// Modified fees (see above) of in-mempool ancestors (including this one) in satoshis (removed in 23.0, see Fees)
+	AncestorFees uint64 `json:"ancestorfees,omitempty"`
+	// Hash of serialized transaction, including witness data
+	WTxId string `json:"wtxid"`
+	// Fees of the transaction and of its in-mempool ancestors and descendants
+	Fees MempoolFees `json:"fees"`
+	// Unconfirmed transactions used as inputs for this transaction
+	Depends []string `json:"depends"`
+	// Unconfirmed transactions spending outputs from this transaction
+	SpentBy []string `json:"spentby"`
+	// Whether this transaction signals BIP125 replaceability or has an
+	// unconfirmed ancestor signaling it
+	BIP125Replaceable bool `json:"bip125-replaceable"`
+	// Whether this transaction is currently unbroadcast (initial broadcast
+	// not yet acknowledged by any peers)
+	Unbroadcast bool `json:"unbroadcast"`
+}
+
+// MempoolInfo represents a response to "getmempoolinfo" call
+type MempoolInfo struct {
+	// True if the mempool is fully loaded
+	Loaded bool `json:"loaded"`
+	// Current transaction count
+	Size uint64 `json:"size"`
+	// Sum of all virtual transaction sizes as defined in BIP 141
+	Bytes uint64 `json:"bytes"`
+	// Total memory usage for the mempool
+	Usage uint64 `json:"usage"`
+	// Total fees for the mempool, ignoring modified fees
+	TotalFee Amount `json:"total_fee"`
+	// Maximum memory usage for the mempool
+	MaxMempool uint64 `json:"maxmempool"`
+	// Minimum fee rate per kvB for a transaction to be accepted
+	MempoolMinFee Amount `json:"mempoolminfee"`
+	// Current minimum relay fee per kvB for transactions
+	MinRelayTxFee Amount `json:"minrelaytxfee"`
+	// Minimum fee rate increment for mempool limiting or replacement per kvB
+	IncrementalRelayFee Amount `json:"incrementalrelayfee"`
+	// Current number of transactions that haven't passed initial broadcast yet
+	UnbroadcastCount uint64 `json:"unbroadcastcount"`
+	// True if the mempool accepts RBF without replaceability signaling inspection
+	FullRBF bool `json:"fullrbf"`
+}
+
+// MempoolSequence represents a response to "getrawmempool" call with
+// mempool_sequence set
+type MempoolSequence struct {
+	// Transaction ids in memory pool
+	Txids []string `json:"txids"`
+	// The mempool sequence value
+	MempoolSequence uint64 `json:"mempool_sequence"`
+}
+
+// GetMempoolInfo returns details on the active state of the TX memory pool
+func (b *Bitcoind) GetMempoolInfo() (info MempoolInfo, err error) {
+	r, err := b.client.call("getmempoolinfo", nil)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &info)
+	return
+}
+
+// GetMempoolEntry returns mempool data for the given transaction
+func (b *Bitcoind) GetMempoolEntry(txId string) (entry VerboseTx, err error) {
+	r, err := b.client.call("getmempoolentry", []string{txId})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &entry)
+	return
+}
+
+// GetMempoolAncestors returns the ids of all in-mempool ancestors of the
+// given transaction
+func (b *Bitcoind) GetMempoolAncestors(txId string) (txIds []string, err error) {
+	r, err := b.client.call("getmempoolancestors", []interface{}{txId, false})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &txIds)
+	return
+}
+
+// GetMempoolAncestorsVerbose returns all in-mempool ancestors of the given
+// transaction
+// map [TxId] => VerboseTx
+func (b *Bitcoind) GetMempoolAncestorsVerbose(txId string) (txs map[string]VerboseTx, err error) {
+	r, err := b.client.call("getmempoolancestors", []interface{}{txId, true})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &txs)
+	return
+}
+
+// GetMempoolDescendants returns the ids of all in-mempool descendants of the
+// given transaction
+func (b *Bitcoind) GetMempoolDescendants(txId string) (txIds []string, err error) {
+	r, err := b.client.call("getmempooldescendants", []interface{}{txId, false})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &txIds)
+	return
+}
+
+// GetMempoolDescendantsVerbose returns all in-mempool descendants of the
+// given transaction
+// map [TxId] => VerboseTx
+func (b *Bitcoind) GetMempoolDescendantsVerbose(txId string) (txs map[string]VerboseTx, err error) {
+	r, err := b.client.call("getmempooldescendants", []interface{}{txId, true})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &txs)
+	return
+}
+
+// GetRawMempoolWithSequence returns all transaction ids in memory pool along
+// with the mempool sequence value, to be used with the zmq sequence
+// notifications
+func (b *Bitcoind) GetRawMempoolWithSequence() (mempool MempoolSequence, err error) {
+	r, err := b.client.call("getrawmempool", []bool{false, true})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &mempool)
+	return
+}
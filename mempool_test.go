@@ -0,0 +1,187 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mempool", func() {
+	entryJSON := `{"vsize":141,"weight":561,"time":1700000000,"height":815000,"descendantcount":2,"descendantsize":282,"ancestorcount":1,"ancestorsize":141,"wtxid":"6bbe3c7a1e4c3b84dfa3ca29b07e2e06bc3d43d3fb8d9a9bd64cd06d13a6cd4a","fees":{"base":0.00002820,"modified":0.00002820,"ancestor":0.00002820,"descendant":0.00005640},"depends":[],"spentby":["f1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"],"bip125-replaceable":true,"unbroadcast":false}`
+	entry := VerboseTx{
+		Vsize:           141,
+		Weight:          561,
+		Time:            1700000000,
+		Height:          815000,
+		DescendantCount: 2,
+		DescendantSize:  282,
+		AncestorCount:   1,
+		AncestorSize:    141,
+		WTxId:           "6bbe3c7a1e4c3b84dfa3ca29b07e2e06bc3d43d3fb8d9a9bd64cd06d13a6cd4a",
+		Fees: MempoolFees{
+			Base:       2820,
+			Modified:   2820,
+			Ancestor:   2820,
+			Descendant: 5640,
+		},
+		Depends:           []string{},
+		SpentBy:           []string{"f1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"},
+		BIP125Replaceable: true,
+	}
+
+	Describe("Testing GetMempoolInfo", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"loaded":true,"size":41873,"bytes":20412334,"usage":116043888,"total_fee":1.80541234,"maxmempool":300000000,"mempoolminfee":0.00001000,"minrelaytxfee":0.00001000,"incrementalrelayfee":0.00001000,"unbroadcastcount":0,"fullrbf":false},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			info, err := bitcoindClient.GetMempoolInfo()
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return", func() {
+				Expect(info).To(Equal(MempoolInfo{
+					Loaded:              true,
+					Size:                41873,
+					Bytes:               20412334,
+					Usage:               116043888,
+					TotalFee:            180541234,
+					MaxMempool:          300000000,
+					MempoolMinFee:       1000,
+					MinRelayTxFee:       1000,
+					IncrementalRelayFee: 1000,
+				}))
+			})
+		})
+	})
+
+	Describe("Testing GetMempoolEntry", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":`+entryJSON+`,"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.GetMempoolEntry("e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return", func() {
+				Expect(result).To(Equal(entry))
+			})
+		})
+		Context("when node uses the legacy fee fields", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"size":141,"fee":0.00002820,"modifiedfee":0.00002820,"descendantfees":5640,"ancestorfees":2820},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.GetMempoolEntry("e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should decode them", func() {
+				Expect(result.Size).To(Equal(uint32(141)))
+				Expect(result.Fee).To(Equal(Amount(2820)))
+				Expect(result.AncestorFees).To(BeNumerically("==", 2820))
+			})
+		})
+	})
+
+	Describe("Testing GetMempoolAncestors", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req rpcRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				params = req.Params.([]interface{})
+				fmt.Fprintln(w, `{"result":["d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"],"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			txIds, err := bitcoindClient.GetMempoolAncestors("e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should not ask for verbose", func() {
+				Expect(params).To(Equal([]interface{}{"e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", false}))
+			})
+			It("should return", func() {
+				Expect(txIds).To(Equal([]string{"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"}))
+			})
+		})
+	})
+
+	Describe("Testing GetMempoolDescendantsVerbose", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req rpcRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				params = req.Params.([]interface{})
+				fmt.Fprintln(w, `{"result":{"f1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90":`+entryJSON+`},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			txs, err := bitcoindClient.GetMempoolDescendantsVerbose("e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should ask for verbose", func() {
+				Expect(params).To(Equal([]interface{}{"e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", true}))
+			})
+			It("should return", func() {
+				Expect(txs).To(Equal(map[string]VerboseTx{"f1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90": entry}))
+			})
+		})
+	})
+
+	Describe("Testing GetRawMempoolWithSequence", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"txids":["e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"],"mempool_sequence":4242},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			mempool, err := bitcoindClient.GetRawMempoolWithSequence()
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return", func() {
+				Expect(mempool).To(Equal(MempoolSequence{
+					Txids:           []string{"e1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"},
+					MempoolSequence: 4242,
+				}))
+			})
+		})
+	})
+})
@@ -0,0 +1,229 @@
+package bitcoind
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// maxProofTransactions is the maximum number of transactions a block can
+// hold: the maximum block weight divided by the minimum transaction weight
+const maxProofTransactions = 4000000 / 240
+
+// A MerkleBlock represents a proof that transactions are included in a block,
+// as returned by GetTxOutProof: the block header and a partial merkle tree
+// from the matched transactions to the merkle root.
+type MerkleBlock struct {
+	// The block header
+	Header RawBlockHeader
+
+	// The block hash
+	Hash string
+
+	// The number of transactions in the block
+	TxCount uint32
+
+	// The hashes of the partial merkle tree, in depth-first order
+	Hashes []string
+
+	// The flag bits of the partial merkle tree, in depth-first order
+	Flags []bool
+}
+
+// GetTxOutProof returns an hex encoded proof that the transactions [txids]
+// are included in a block. If [blockHash] is empty, the node looks for the
+// block in its UTXO set or transaction index.
+func (b *Bitcoind) GetTxOutProof(txids []string, blockHash string) (proof string, err error) {
+	params := []interface{}{txids}
+	if blockHash != "" {
+		params = append(params, blockHash)
+	}
+	r, err := b.client.call("gettxoutproof", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &proof)
+	return
+}
+
+// VerifyTxOutProof returns the transactions the proof commits to, or an
+// empty list if the proof does not match a block of the best chain.
+// The node is trusted, see ParseMerkleBlock to verify the proof locally.
+func (b *Bitcoind) VerifyTxOutProof(proof string) (txids []string, err error) {
+	r, err := b.client.call("verifytxoutproof", []interface{}{proof})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &txids)
+	return
+}
+
+// ParseMerkleBlock decodes an hex encoded proof, as returned by
+// GetTxOutProof
+func ParseMerkleBlock(proof string) (mb MerkleBlock, err error) {
+	data, err := hex.DecodeString(proof)
+	if err != nil {
+		return
+	}
+	return DecodeMerkleBlock(data)
+}
+
+// DecodeMerkleBlock decodes a serialized proof. The proof is not verified,
+// see Verify.
+func DecodeMerkleBlock(data []byte) (mb MerkleBlock, err error) {
+	if len(data) < BlockHeaderSize {
+		err = fmt.Errorf("Bad merkle block size: %d bytes", len(data))
+		return
+	}
+	if mb.Header, err = DecodeBlockHeader(data[:BlockHeaderSize]); err != nil {
+		return
+	}
+	if mb.Hash, err = mb.Header.Hash(); err != nil {
+		return
+	}
+	r := &txReader{data: data, pos: BlockHeaderSize}
+	if mb.TxCount, err = r.readUint32(); err != nil {
+		return
+	}
+	n, err := r.readCompactSize()
+	if err != nil {
+		return
+	}
+	mb.Hashes = make([]string, 0, minCount(n, r))
+	for i := uint64(0); i < n; i++ {
+		var hash []byte
+		if hash, err = r.read(32); err != nil {
+			return
+		}
+		mb.Hashes = append(mb.Hashes, hashToString(hash))
+	}
+	flags, err := r.readVarBytes()
+	if err != nil {
+		return
+	}
+	mb.Flags = make([]bool, len(flags)*8)
+	for i := range mb.Flags {
+		mb.Flags[i] = flags[i/8]&(1<<uint(i%8)) != 0
+	}
+	if r.pos != len(data) {
+		err = fmt.Errorf("Bad merkle block: %d trailing bytes", len(data)-r.pos)
+	}
+	return
+}
+
+// Verify checks the partial merkle tree of the proof hashes up to the merkle
+// root of its header and returns the matched transaction ids. Only the
+// header is authenticated: callers must check Hash is a block they trust,
+// for instance one of a header chain checked with ValidateHeaderChain.
+func (mb MerkleBlock) Verify() (txids []string, err error) {
+	txids, _, err = mb.ExtractMatches()
+	return
+}
+
+// ExtractMatches walks the partial merkle tree like bitcoind does and returns
+// the matched transaction ids with their position in the block. It fails if
+// the tree is malformed or its root does not match the header merkle root.
+func (mb MerkleBlock) ExtractMatches() (txids []string, indexes []uint32, err error) {
+	if mb.TxCount == 0 {
+		return nil, nil, errors.New("Bad merkle block: no transaction")
+	}
+	if mb.TxCount > maxProofTransactions {
+		return nil, nil, fmt.Errorf("Bad merkle block: %d transactions", mb.TxCount)
+	}
+	if uint32(len(mb.Hashes)) > mb.TxCount {
+		return nil, nil, errors.New("Bad merkle block: more hashes than transactions")
+	}
+	if len(mb.Flags) < len(mb.Hashes) {
+		return nil, nil, errors.New("Bad merkle block: fewer flags than hashes")
+	}
+	t := partialMerkleTree{txCount: mb.TxCount, flags: mb.Flags}
+	if t.hashes, err = hashesFromStrings(mb.Hashes); err != nil {
+		return
+	}
+	height := 0
+	for t.width(height) > 1 {
+		height++
+	}
+	root, err := t.traverse(height, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Bad merkle block: %v", err)
+	}
+	if (t.flagsUsed+7)/8 != (len(t.flags)+7)/8 {
+		return nil, nil, errors.New("Bad merkle block: unused flags")
+	}
+	if t.hashesUsed != len(t.hashes) {
+		return nil, nil, errors.New("Bad merkle block: unused hashes")
+	}
+	if rootHex := hashToString(root); rootHex != mb.Header.Merkleroot {
+		return nil, nil, fmt.Errorf("Block %s: merkle root %s does not match proof root %s", mb.Hash, mb.Header.Merkleroot, rootHex)
+	}
+	return t.matches, t.indexes, nil
+}
+
+// hashesFromStrings returns the internal byte order of hex hashes
+func hashesFromStrings(strs []string) ([][]byte, error) {
+	hashes := make([][]byte, len(strs))
+	for i, str := range strs {
+		var err error
+		if hashes[i], err = hashFromString(str); err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// A partialMerkleTree holds the state of a depth-first walk of a proof
+type partialMerkleTree struct {
+	txCount    uint32
+	hashes     [][]byte
+	flags      []bool
+	hashesUsed int
+	flagsUsed  int
+	matches    []string
+	indexes    []uint32
+}
+
+// width returns the number of nodes at height in the tree
+func (t *partialMerkleTree) width(height int) uint32 {
+	return uint32((uint64(t.txCount) + (1 << uint(height)) - 1) >> uint(height))
+}
+
+// traverse computes the hash of the node at height and pos, recording the
+// matched leaves
+func (t *partialMerkleTree) traverse(height int, pos uint32) ([]byte, error) {
+	if t.flagsUsed >= len(t.flags) {
+		return nil, errors.New("not enough flags")
+	}
+	parentOfMatch := t.flags[t.flagsUsed]
+	t.flagsUsed++
+	if height == 0 || !parentOfMatch {
+		if t.hashesUsed >= len(t.hashes) {
+			return nil, errors.New("not enough hashes")
+		}
+		hash := t.hashes[t.hashesUsed]
+		t.hashesUsed++
+		if height == 0 && parentOfMatch {
+			t.matches = append(t.matches, hashToString(hash))
+			t.indexes = append(t.indexes, pos)
+		}
+		return hash, nil
+	}
+	left, err := t.traverse(height-1, pos*2)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*2+1 < t.width(height-1) {
+		if right, err = t.traverse(height-1, pos*2+1); err != nil {
+			return nil, err
+		}
+		// Identical siblings would allow the same root for another tree
+		// (CVE-2012-2459)
+		if bytes.Equal(left, right) {
+			return nil, errors.New("duplicate hashes")
+		}
+	}
+	return doubleSha256(append(append([]byte{}, left...), right...)), nil
+}
@@ -0,0 +1,170 @@
+package bitcoind
+
+import (
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// genesisProofHex proves the coinbase of the genesis block
+const genesisProofHex = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c" +
+	"01000000" + "01" + "3ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a" + "01" + "01"
+
+// Leaves of a three transactions block, in internal byte order
+const (
+	proofLeafA = "1111111111111111111111111111111111111111111111111111111111111111"
+	proofLeafB = "2222222222222222222222222222222222222222222222222222222222222222"
+	proofLeafC = "3333333333333333333333333333333333333333333333333333333333333333"
+)
+
+// threeTxProof returns a proof of the second transaction of a three
+// transactions block, with the given flags byte
+func threeTxProof(flags string) string {
+	ab := hex.EncodeToString(sha256d(proofLeafA, proofLeafB))
+	cc := hex.EncodeToString(sha256d(proofLeafC, proofLeafC))
+	root := hex.EncodeToString(sha256d(ab, cc))
+	header := "00000020" + nullHash + root + "29ab5f49ffff7f2000000000"
+	return header + "03000000" + "03" + proofLeafA + proofLeafB + cc + "01" + flags
+}
+
+var _ = Describe("MerkleProof", func() {
+	Describe("Testing GetTxOutProof", func() {
+		Context("without block hash", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"gettxoutproof": `"` + genesisProofHex + `"`,
+			}, &calls)
+			defer done()
+			proof, err := bitcoindClient.GetTxOutProof([]string{"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"}, "")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should only send the txids", func() {
+				Expect(calls).To(Equal([]string{"gettxoutproof [[4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b]]"}))
+			})
+			It("should return the proof", func() {
+				Expect(proof).To(Equal(genesisProofHex))
+			})
+		})
+		Context("with a block hash", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"gettxoutproof": `"` + genesisProofHex + `"`,
+			}, &calls)
+			defer done()
+			_, err := bitcoindClient.GetTxOutProof([]string{"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"}, "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f")
+			It("should send the block hash", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls).To(Equal([]string{"gettxoutproof [[4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b] 000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f]"}))
+			})
+		})
+		Context("when the transaction is not found", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(nil, &calls, map[string]RPCError{
+				"gettxoutproof": {Code: -5, Message: "Transaction not yet in block"},
+			})
+			defer done()
+			_, err := bitcoindClient.GetTxOutProof([]string{proofLeafA}, "")
+			It("should return the RPC error", func() {
+				Expect(err).To(Equal(&RPCError{Code: -5, Message: "Transaction not yet in block"}))
+			})
+		})
+	})
+
+	Describe("Testing VerifyTxOutProof", func() {
+		var calls []string
+		bitcoindClient, done := newTestNode(map[string]string{
+			"verifytxoutproof": `["4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"]`,
+		}, &calls)
+		defer done()
+		txids, err := bitcoindClient.VerifyTxOutProof(genesisProofHex)
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should send the proof", func() {
+			Expect(calls).To(Equal([]string{"verifytxoutproof [" + genesisProofHex + "]"}))
+		})
+		It("should return the txids", func() {
+			Expect(txids).To(Equal([]string{"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"}))
+		})
+	})
+
+	Describe("Testing ParseMerkleBlock", func() {
+		Context("when the proof is for the genesis block", func() {
+			mb, err := ParseMerkleBlock(genesisProofHex)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should decode the proof", func() {
+				Expect(mb.Hash).To(Equal("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"))
+				Expect(mb.TxCount).To(Equal(uint32(1)))
+				Expect(mb.Hashes).To(Equal([]string{"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"}))
+				Expect(mb.Flags).To(HaveLen(8))
+			})
+			It("should verify", func() {
+				txids, err := mb.Verify()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(txids).To(Equal([]string{"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"}))
+			})
+		})
+
+		Context("when the proof has several levels", func() {
+			mb, err := ParseMerkleBlock(threeTxProof("0b"))
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should extract the matched transaction", func() {
+				txids, indexes, err := mb.ExtractMatches()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(txids).To(Equal([]string{internalHex(proofLeafB)}))
+				Expect(indexes).To(Equal([]uint32{1}))
+			})
+		})
+
+		Context("when the proof is invalid", func() {
+			It("should fail to decode", func() {
+				for _, proof := range []string{
+					"",
+					genesisProofHex[:160],
+					genesisProofHex[:len(genesisProofHex)-2],
+					genesisProofHex + "00",
+				} {
+					_, err := ParseMerkleBlock(proof)
+					Expect(err).To(HaveOccurred(), proof)
+				}
+			})
+			It("should fail to verify", func() {
+				for _, proof := range []string{
+					// Unused hashes
+					threeTxProof("01"),
+					// Too many flags bytes
+					threeTxProof("0b")[:len(threeTxProof("0b"))-4] + "020b00",
+					// Matches the last transaction: the root differs
+					threeTxProof("1f"),
+				} {
+					mb, err := ParseMerkleBlock(proof)
+					Expect(err).NotTo(HaveOccurred(), proof)
+					_, err = mb.Verify()
+					Expect(err).To(HaveOccurred(), proof)
+				}
+			})
+			It("should report a root mismatch", func() {
+				mb, err := ParseMerkleBlock(genesisProofHex)
+				Expect(err).NotTo(HaveOccurred())
+				mb.Header.Merkleroot = nullHash
+				_, err = mb.Verify()
+				Expect(err).To(MatchError(ContainSubstring("does not match proof root")))
+			})
+			It("should reject duplicate hashes", func() {
+				mb, err := ParseMerkleBlock(threeTxProof("0b"))
+				Expect(err).NotTo(HaveOccurred())
+				mb.TxCount = 2
+				mb.Hashes = []string{mb.Hashes[1], mb.Hashes[1]}
+				mb.Flags = []bool{true, false, true}
+				_, err = mb.Verify()
+				Expect(err).To(MatchError(ContainSubstring("duplicate hashes")))
+			})
+		})
+	})
+})
@@ -0,0 +1,358 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// A NetworkType is the name of a network a node can reach
+type NetworkType string
+
+// Network types
+const (
+	NetworkIPv4  NetworkType = "ipv4"
+	NetworkIPv6  NetworkType = "ipv6"
+	NetworkOnion NetworkType = "onion"
+	NetworkI2P   NetworkType = "i2p"
+	NetworkCJDNS NetworkType = "cjdns"
+
+	// Addresses of peers that are not publicly routable, as in getpeerinfo
+	NetworkNotPubliclyRoutable NetworkType = "not_publicly_routable"
+)
+
+// A Network represents the reachability of a network, as returned by
+// getnetworkinfo
+type Network struct {
+	// The network name
+	Name NetworkType `json:"name"`
+
+	// Whether the network is limited using -onlynet
+	Limited bool `json:"limited"`
+
+	// Whether the network is reachable
+	Reachable bool `json:"reachable"`
+
+	// The proxy used for this network, "host:port", empty if none
+	Proxy string `json:"proxy"`
+
+	// Whether randomized credentials are used
+	ProxyRandomizeCredentials bool `json:"proxy_randomize_credentials"`
+}
+
+// A LocalAddress represents an address the node advertises to its peers
+type LocalAddress struct {
+	// The network address
+	Address string `json:"address"`
+
+	// The network port
+	Port uint16 `json:"port"`
+
+	// The relative score
+	Score int `json:"score"`
+}
+
+// Warnings are the warnings of a node
+type Warnings []string
+
+// UnmarshalJSON decodes the warnings, given as a list since Core 28 and as a
+// single, possibly empty, string before
+func (w *Warnings) UnmarshalJSON(data []byte) error {
+	var warning string
+	if err := json.Unmarshal(data, &warning); err == nil {
+		*w = Warnings{}
+		if warning != "" {
+			*w = Warnings{warning}
+		}
+		return nil
+	}
+	var warnings []string
+	if err := json.Unmarshal(data, &warnings); err != nil {
+		return err
+	}
+	*w = warnings
+	return nil
+}
+
+// NetworkInfo represents a response to "getnetworkinfo" call
+type NetworkInfo struct {
+	// The server version
+	Version uint32 `json:"version"`
+
+	// The server subversion string
+	Subversion string `json:"subversion"`
+
+	// The protocol version
+	ProtocolVersion uint32 `json:"protocolversion"`
+
+	// The services we offer to the network, in hex
+	LocalServices string `json:"localservices"`
+
+	// The services we offer to the network, in human-readable form
+	LocalServicesNames []string `json:"localservicesnames"`
+
+	// Whether transaction relay is requested from peers
+	LocalRelay bool `json:"localrelay"`
+
+	// The time offset
+	TimeOffset int64 `json:"timeoffset"`
+
+	// The total number of connections
+	Connections uint32 `json:"connections"`
+
+	// The number of inbound connections
+	ConnectionsIn uint32 `json:"connections_in"`
+
+	// The number of outbound connections
+	ConnectionsOut uint32 `json:"connections_out"`
+
+	// Whether p2p networking is enabled
+	NetworkActive bool `json:"networkactive"`
+
+	// The reachability of each network
+	Networks []Network `json:"networks"`
+
+	// The minimum relay fee rate for transactions per kvB
+	RelayFee Amount `json:"relayfee"`
+
+	// The minimum fee rate increment per kvB for mempool limiting or
+	// replacement
+	IncrementalFee Amount `json:"incrementalfee"`
+
+	// The addresses advertised to peers
+	LocalAddresses []LocalAddress `json:"localaddresses"`
+
+	// Network and blockchain warnings
+	Warnings Warnings `json:"warnings"`
+}
+
+// Reachable tells whether the node can reach the network
+func (n NetworkInfo) Reachable(network NetworkType) bool {
+	for _, net := range n.Networks {
+		if net.Name == network {
+			return net.Reachable
+		}
+	}
+	return false
+}
+
+// UploadTarget represents the upload target state of a node
+type UploadTarget struct {
+	// The length of the measuring timeframe in seconds
+	Timeframe uint64 `json:"timeframe"`
+
+	// The target in bytes, 0 if there is none
+	Target uint64 `json:"target"`
+
+	// Whether the target is reached
+	TargetReached bool `json:"target_reached"`
+
+	// Whether historical blocks are still served
+	ServeHistoricalBlocks bool `json:"serve_historical_blocks"`
+
+	// The bytes left in the current time cycle
+	BytesLeftInCycle uint64 `json:"bytes_left_in_cycle"`
+
+	// The seconds left in the current time cycle
+	TimeLeftInCycle uint64 `json:"time_left_in_cycle"`
+}
+
+// NetTotals represents a response to "getnettotals" call
+type NetTotals struct {
+	// The total bytes received
+	TotalBytesRecv uint64 `json:"totalbytesrecv"`
+
+	// The total bytes sent
+	TotalBytesSent uint64 `json:"totalbytessent"`
+
+	// The current time in milliseconds since epoch (Jan 1 1970 GMT)
+	TimeMillis int64 `json:"timemillis"`
+
+	// The upload target
+	UploadTarget UploadTarget `json:"uploadtarget"`
+}
+
+// An AddedNodeAddress represents a connected address of an added node
+type AddedNodeAddress struct {
+	// The bitcoin server IP and port we're connected to
+	Address string `json:"address"`
+
+	// The connection direction, "inbound" or "outbound"
+	Connected string `json:"connected"`
+}
+
+// An AddedNode represents a node added with AddNode, as returned by
+// getaddednodeinfo
+type AddedNode struct {
+	// The node IP address or name, as provided to addnode
+	AddedNode string `json:"addednode"`
+
+	// Whether the node is connected
+	Connected bool `json:"connected"`
+
+	// The connected addresses, only when connected
+	Addresses []AddedNodeAddress `json:"addresses"`
+}
+
+// A BanEntry represents a banned subnet, as returned by listbanned
+type BanEntry struct {
+	// The banned subnet
+	Address string `json:"address"`
+
+	// The ban creation time in seconds since epoch (Jan 1 1970 GMT)
+	BanCreated int64 `json:"ban_created"`
+
+	// The ban expiration time in seconds since epoch (Jan 1 1970 GMT)
+	BannedUntil int64 `json:"banned_until"`
+
+	// The ban duration in seconds
+	BanDuration int64 `json:"ban_duration"`
+
+	// The time remaining until the ban expires, in seconds
+	TimeRemaining int64 `json:"time_remaining"`
+}
+
+// A NodeAddress represents a known address of the network, as returned by
+// getnodeaddresses
+type NodeAddress struct {
+	// The time in seconds since epoch (Jan 1 1970 GMT) the node was last seen
+	Time int64 `json:"time"`
+
+	// The services offered by the node
+	Services uint64 `json:"services"`
+
+	// The address of the node
+	Address string `json:"address"`
+
+	// The port of the node
+	Port uint16 `json:"port"`
+
+	// The network the node is on
+	Network NetworkType `json:"network"`
+}
+
+// GetNetworkInfo returns the state of the node P2P networking
+func (b *Bitcoind) GetNetworkInfo() (info NetworkInfo, err error) {
+	r, err := b.client.call("getnetworkinfo", nil)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &info)
+	return
+}
+
+// GetNetTotals returns information about network traffic, including bytes
+// in, bytes out and the upload target
+func (b *Bitcoind) GetNetTotals() (totals NetTotals, err error) {
+	r, err := b.client.call("getnettotals", nil)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &totals)
+	return
+}
+
+// AddNode attempts to add or remove a node from the addnode list, or to try
+// a connection to a node once.
+// [command] is "add", "remove" or "onetry".
+func (b *Bitcoind) AddNode(node, command string) error {
+	switch command {
+	case "add", "remove", "onetry":
+	default:
+		return errors.New("Bad parameters for AddNode: command must be add, remove or onetry")
+	}
+	r, err := b.client.call("addnode", []interface{}{node, command})
+	return handleError(err, &r)
+}
+
+// DisconnectNode immediately disconnects from the node at [address]
+func (b *Bitcoind) DisconnectNode(address string) error {
+	r, err := b.client.call("disconnectnode", []interface{}{address})
+	return handleError(err, &r)
+}
+
+// DisconnectNodeID immediately disconnects from the peer with the id
+// [nodeID], as returned by GetPeerInfo
+func (b *Bitcoind) DisconnectNodeID(nodeID int) error {
+	r, err := b.client.call("disconnectnode", []interface{}{"", nodeID})
+	return handleError(err, &r)
+}
+
+// GetAddedNodeInfo returns information about the given added node, or all
+// added nodes if [node] is empty
+func (b *Bitcoind) GetAddedNodeInfo(node string) (nodes []AddedNode, err error) {
+	var params []interface{}
+	if node != "" {
+		params = []interface{}{node}
+	}
+	r, err := b.client.call("getaddednodeinfo", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &nodes)
+	return
+}
+
+// SetBan adds or removes an IP or subnet from the banned list.
+// [command] is "add" or "remove". [banTime] is the ban duration in seconds,
+// or the ban expiration time if [absolute] is set; 0 uses the node default
+// (-bantime).
+func (b *Bitcoind) SetBan(subnet, command string, banTime int64, absolute bool) error {
+	if command != "add" && command != "remove" {
+		return errors.New("Bad parameters for SetBan: command must be add or remove")
+	}
+	params := []interface{}{subnet, command}
+	if banTime != 0 || absolute {
+		params = append(params, banTime, absolute)
+	}
+	r, err := b.client.call("setban", params)
+	return handleError(err, &r)
+}
+
+// ListBanned returns the banned IPs and subnets
+func (b *Bitcoind) ListBanned() (bans []BanEntry, err error) {
+	r, err := b.client.call("listbanned", nil)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &bans)
+	return
+}
+
+// ClearBanned clears all banned IPs
+func (b *Bitcoind) ClearBanned() error {
+	r, err := b.client.call("clearbanned", nil)
+	return handleError(err, &r)
+}
+
+// GetNodeAddresses returns up to [count] known addresses of the network,
+// 0 for all of them, from [network] or from all networks if empty
+func (b *Bitcoind) GetNodeAddresses(count int, network NetworkType) (addresses []NodeAddress, err error) {
+	params := []interface{}{count}
+	if network != "" {
+		params = append(params, network)
+	}
+	r, err := b.client.call("getnodeaddresses", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &addresses)
+	return
+}
+
+// SetNetworkActive disables or enables all P2P network activity and returns
+// the new state
+func (b *Bitcoind) SetNetworkActive(state bool) (active bool, err error) {
+	r, err := b.client.call("setnetworkactive", []interface{}{state})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &active)
+	return
+}
+
+// Ping requests that a ping be sent to all other nodes, to measure ping
+// time. Results are available in the ping times of GetPeerInfo.
+func (b *Bitcoind) Ping() error {
+	r, err := b.client.call("ping", nil)
+	return handleError(err, &r)
+}
@@ -0,0 +1,213 @@
+package bitcoind
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Network", func() {
+	Describe("Testing GetNetworkInfo", func() {
+		Context("when the node is recent", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getnetworkinfo": `{"version":280000,"subversion":"/Satoshi:28.0.0/","protocolversion":70016,"localservices":"0000000000000c09","localservicesnames":["NETWORK","WITNESS","NETWORK_LIMITED","P2P_V2"],"localrelay":true,"timeoffset":-1,"networkactive":true,"connections":12,"connections_in":2,"connections_out":10,"networks":[{"name":"ipv4","limited":false,"reachable":true,"proxy":"","proxy_randomize_credentials":false},{"name":"onion","limited":true,"reachable":false,"proxy":"127.0.0.1:9050","proxy_randomize_credentials":true},{"name":"cjdns","limited":true,"reachable":false,"proxy":"","proxy_randomize_credentials":false}],"relayfee":0.00001000,"incrementalfee":0.00001000,"localaddresses":[{"address":"203.0.113.7","port":8333,"score":4}],"warnings":["This is a pre-release test build"]}`,
+			}, &calls)
+			defer done()
+			info, err := bitcoindClient.GetNetworkInfo()
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls).To(Equal([]string{"getnetworkinfo []"}))
+			})
+			It("should return the node fields", func() {
+				Expect(info.Version).To(Equal(uint32(280000)))
+				Expect(info.Subversion).To(Equal("/Satoshi:28.0.0/"))
+				Expect(info.LocalServicesNames).To(ContainElement("P2P_V2"))
+				Expect(info.TimeOffset).To(Equal(int64(-1)))
+				Expect(info.ConnectionsIn).To(Equal(uint32(2)))
+				Expect(info.ConnectionsOut).To(Equal(uint32(10)))
+				Expect(info.RelayFee).To(Equal(Amount(1000)))
+				Expect(info.Warnings).To(Equal(Warnings{"This is a pre-release test build"}))
+			})
+			It("should return the networks", func() {
+				Expect(info.Networks).To(HaveLen(3))
+				Expect(info.Networks[1]).To(Equal(Network{Name: NetworkOnion, Limited: true, Proxy: "127.0.0.1:9050", ProxyRandomizeCredentials: true}))
+				Expect(info.Reachable(NetworkIPv4)).To(BeTrue())
+				Expect(info.Reachable(NetworkOnion)).To(BeFalse())
+				Expect(info.Reachable(NetworkI2P)).To(BeFalse())
+			})
+			It("should return the local addresses", func() {
+				Expect(info.LocalAddresses).To(Equal([]LocalAddress{{Address: "203.0.113.7", Port: 8333, Score: 4}}))
+			})
+		})
+		Context("when the node returns warnings as a string", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{
+				"getnetworkinfo": `{"version":250000,"networks":[],"localaddresses":[],"warnings":""}`,
+			}, &calls)
+			defer done()
+			info, err := bitcoindClient.GetNetworkInfo()
+			It("should return no warning", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Warnings).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Testing GetNetTotals", func() {
+		var calls []string
+		bitcoindClient, done := newTestNode(map[string]string{
+			"getnettotals": `{"totalbytesrecv":7291023,"totalbytessent":3301,"timemillis":1700000000123,"uploadtarget":{"timeframe":86400,"target":0,"target_reached":false,"serve_historical_blocks":true,"bytes_left_in_cycle":0,"time_left_in_cycle":0}}`,
+		}, &calls)
+		defer done()
+		totals, err := bitcoindClient.GetNetTotals()
+		It("should return the totals", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(totals).To(Equal(NetTotals{
+				TotalBytesRecv: 7291023,
+				TotalBytesSent: 3301,
+				TimeMillis:     1700000000123,
+				UploadTarget:   UploadTarget{Timeframe: 86400, ServeHistoricalBlocks: true},
+			}))
+		})
+	})
+
+	Describe("Testing AddNode", func() {
+		It("should send the node and command", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"addnode": "null"}, &calls)
+			defer done()
+			Expect(bitcoindClient.AddNode("192.0.2.1:8333", "onetry")).To(Succeed())
+			Expect(calls).To(Equal([]string{"addnode [192.0.2.1:8333 onetry]"}))
+		})
+		It("should reject unknown commands", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(nil, &calls)
+			defer done()
+			Expect(bitcoindClient.AddNode("192.0.2.1:8333", "connect")).To(MatchError(ContainSubstring("Bad parameters for AddNode")))
+			Expect(calls).To(BeEmpty())
+		})
+		It("should return the RPC error", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(nil, &calls, map[string]RPCError{
+				"addnode": {Code: -23, Message: "Error: Node already added"},
+			})
+			defer done()
+			Expect(bitcoindClient.AddNode("192.0.2.1:8333", "add")).To(Equal(&RPCError{Code: -23, Message: "Error: Node already added"}))
+		})
+	})
+
+	Describe("Testing DisconnectNode", func() {
+		It("should disconnect by address", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"disconnectnode": "null"}, &calls)
+			defer done()
+			Expect(bitcoindClient.DisconnectNode("192.0.2.1:8333")).To(Succeed())
+			Expect(calls).To(Equal([]string{"disconnectnode [192.0.2.1:8333]"}))
+		})
+		It("should disconnect by id", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"disconnectnode": "null"}, &calls)
+			defer done()
+			Expect(bitcoindClient.DisconnectNodeID(7)).To(Succeed())
+			Expect(calls).To(Equal([]string{"disconnectnode [ 7]"}))
+		})
+	})
+
+	Describe("Testing GetAddedNodeInfo", func() {
+		var calls []string
+		bitcoindClient, done := newTestNode(map[string]string{
+			"getaddednodeinfo": `[{"addednode":"192.0.2.1:8333","connected":true,"addresses":[{"address":"192.0.2.1:8333","connected":"outbound"}]},{"addednode":"node.example.com","connected":false,"addresses":[]}]`,
+		}, &calls)
+		defer done()
+		nodes, err := bitcoindClient.GetAddedNodeInfo("")
+		It("should not send a node", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal([]string{"getaddednodeinfo []"}))
+		})
+		It("should return the nodes", func() {
+			Expect(nodes).To(Equal([]AddedNode{
+				{AddedNode: "192.0.2.1:8333", Connected: true, Addresses: []AddedNodeAddress{{Address: "192.0.2.1:8333", Connected: "outbound"}}},
+				{AddedNode: "node.example.com", Addresses: []AddedNodeAddress{}},
+			}))
+		})
+	})
+
+	Describe("Testing SetBan", func() {
+		It("should use the default ban time", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"setban": "null"}, &calls)
+			defer done()
+			Expect(bitcoindClient.SetBan("192.0.2.0/24", "add", 0, false)).To(Succeed())
+			Expect(calls).To(Equal([]string{"setban [192.0.2.0/24 add]"}))
+		})
+		It("should send the ban time", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"setban": "null"}, &calls)
+			defer done()
+			Expect(bitcoindClient.SetBan("192.0.2.1", "add", 3600, false)).To(Succeed())
+			Expect(bitcoindClient.SetBan("192.0.2.1", "remove", 0, false)).To(Succeed())
+			Expect(calls).To(Equal([]string{"setban [192.0.2.1 add 3600 false]", "setban [192.0.2.1 remove]"}))
+		})
+		It("should reject unknown commands", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(nil, &calls)
+			defer done()
+			Expect(bitcoindClient.SetBan("192.0.2.1", "ban", 0, false)).To(MatchError(ContainSubstring("Bad parameters for SetBan")))
+			Expect(calls).To(BeEmpty())
+		})
+	})
+
+	Describe("Testing ListBanned and ClearBanned", func() {
+		var calls []string
+		bitcoindClient, done := newTestNode(map[string]string{
+			"listbanned":  `[{"address":"192.0.2.0/24","ban_created":1700000000,"banned_until":1700086400,"ban_duration":86400,"time_remaining":43200}]`,
+			"clearbanned": "null",
+		}, &calls)
+		defer done()
+		bans, err := bitcoindClient.ListBanned()
+		clearErr := bitcoindClient.ClearBanned()
+		It("should return the bans", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bans).To(Equal([]BanEntry{{Address: "192.0.2.0/24", BanCreated: 1700000000, BannedUntil: 1700086400, BanDuration: 86400, TimeRemaining: 43200}}))
+		})
+		It("should clear the bans", func() {
+			Expect(clearErr).NotTo(HaveOccurred())
+			Expect(calls).To(Equal([]string{"listbanned []", "clearbanned []"}))
+		})
+	})
+
+	Describe("Testing GetNodeAddresses", func() {
+		var calls []string
+		bitcoindClient, done := newTestNode(map[string]string{
+			"getnodeaddresses": `[{"time":1700000000,"services":3081,"address":"2001:db8::1","port":8333,"network":"ipv6"}]`,
+		}, &calls)
+		defer done()
+		addresses, err := bitcoindClient.GetNodeAddresses(2, NetworkIPv6)
+		It("should send the count and network", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal([]string{"getnodeaddresses [2 ipv6]"}))
+		})
+		It("should return the addresses", func() {
+			Expect(addresses).To(Equal([]NodeAddress{{Time: 1700000000, Services: 3081, Address: "2001:db8::1", Port: 8333, Network: NetworkIPv6}}))
+		})
+	})
+
+	Describe("Testing SetNetworkActive and Ping", func() {
+		var calls []string
+		bitcoindClient, done := newTestNode(map[string]string{
+			"setnetworkactive": "false",
+			"ping":             "null",
+		}, &calls)
+		defer done()
+		active, err := bitcoindClient.SetNetworkActive(false)
+		pingErr := bitcoindClient.Ping()
+		It("should return the new state", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(active).To(BeFalse())
+		})
+		It("should ping", func() {
+			Expect(pingErr).NotTo(HaveOccurred())
+			Expect(calls).To(Equal([]string{"setnetworkactive [false]", "ping []"}))
+		})
+	})
+})
@@ -1,21 +1,61 @@
 package bitcoind
 
+// Connection types of peers, as returned by getpeerinfo since Core 0.21
+const (
+	ConnectionOutboundFullRelay = "outbound-full-relay"
+	ConnectionBlockRelayOnly    = "block-relay-only"
+	ConnectionInbound           = "inbound"
+	ConnectionManual            = "manual"
+	ConnectionAddrFetch         = "addr-fetch"
+	ConnectionFeeler            = "feeler"
+)
+
+// A Peer represents a node connected to the server, as returned by
+// getpeerinfo. Fields a node version does not return are left to their zero
+// value.
 type Peer struct {
+	// The peer index
+	ID int `json:"id"`
+
 	// The ip address and port of the peer
 	Addr string `json:"addr"`
 
+	// Bind address of the connection to the peer
+	Addrbind string `json:"addrbind,omitempty"`
+
 	// Local address
 	Addrlocal string `json:"addrlocal"`
 
+	// The network of the peer (ipv4, ipv6, onion, i2p, cjdns or
+	// not_publicly_routable)
+	Network NetworkType `json:"network,omitempty"`
+
+	// The AS number used for diversifying peer selection, 0 if unmapped
+	MappedAS uint32 `json:"mapped_as,omitempty"`
+
 	// The services
 	Services string `json:"services"`
 
+	// The services, in human-readable form
+	ServicesNames []string `json:"servicesnames,omitempty"`
+
+	// Whether the peer asked us to relay transactions
+	Relaytxes bool `json:"relaytxes"`
+
 	// The time in seconds since epoch (Jan 1 1970 GMT) of the last send
 	Lastsend uint64 `json:"lastsend"`
 
 	// The time in seconds since epoch (Jan 1 1970 GMT) of the last receive
 	Lastrecv uint64 `json:"lastrecv"`
 
+	// The time in seconds since epoch (Jan 1 1970 GMT) of the last valid
+	// transaction received from this peer
+	LastTransaction uint64 `json:"last_transaction,omitempty"`
+
+	// The time in seconds since epoch (Jan 1 1970 GMT) of the last block
+	// received from this peer
+	LastBlock uint64 `json:"last_block,omitempty"`
+
 	// The total bytes sent
 	Bytessent uint64 `json:"bytessent"`
 
@@ -25,9 +65,15 @@ type Peer struct {
 	// The connection time in seconds since epoch (Jan 1 1970 GMT)
 	Conntime uint64 `json:"conntime"`
 
+	// The time offset in seconds
+	Timeoffset int64 `json:"timeoffset,omitempty"`
+
 	// Ping time
 	Pingtime float64 `json:"pingtime"`
 
+	// The minimum observed ping time in seconds
+	Minping float64 `json:"minping,omitempty"`
+
 	// Ping Wait
 	Pingwait float64 `json:"pingwait"`
 
@@ -40,12 +86,94 @@ type Peer struct {
 	// Inbound (true) or Outbound (false)
 	Inbound bool `json:"inbound"`
 
+	// Whether we selected the peer as compact blocks high-bandwidth peer
+	Bip152HbTo bool `json:"bip152_hb_to,omitempty"`
+
+	// Whether the peer selected us as compact blocks high-bandwidth peer
+	Bip152HbFrom bool `json:"bip152_hb_from,omitempty"`
+
 	//  The starting height (block) of the peer
 	Startingheight int32 `json:"startingheight"`
 
+	// The current height of header pre-synchronization with this peer, -1
+	// if none
+	PresyncedHeaders int64 `json:"presynced_headers,omitempty"`
+
+	// The last header we have in common with this peer
+	SyncedHeaders int64 `json:"synced_headers,omitempty"`
+
+	// The last block we have in common with this peer
+	SyncedBlocks int64 `json:"synced_blocks,omitempty"`
+
+	// The heights of blocks we are currently requesting from this peer
+	Inflight []int64 `json:"inflight,omitempty"`
+
+	// Whether we participate in address relay with this peer
+	AddrRelayEnabled bool `json:"addr_relay_enabled,omitempty"`
+
+	// The total number of addresses processed, excluding those dropped due
+	// to rate limiting
+	AddrProcessed uint64 `json:"addr_processed,omitempty"`
+
+	// The total number of addresses dropped due to rate limiting
+	AddrRateLimited uint64 `json:"addr_rate_limited,omitempty"`
+
+	// The permissions granted to the peer, such as "noban" or "relay"
+	Permissions []string `json:"permissions,omitempty"`
+
+	// The minimum fee rate per kvB of transactions this peer accepts
+	Minfeefilter Amount `json:"minfeefilter,omitempty"`
+
+	// The total bytes sent aggregated by message type
+	BytessentPerMsg map[string]uint64 `json:"bytessent_per_msg,omitempty"`
+
+	// The total bytes received aggregated by message type
+	BytesrecvPerMsg map[string]uint64 `json:"bytesrecv_per_msg,omitempty"`
+
+	// The type of connection, see the Connection constants
+	ConnectionType string `json:"connection_type,omitempty"`
+
+	// The transport protocol: "detecting", "v1" or "v2" (BIP 324)
+	TransportProtocolType string `json:"transport_protocol_type,omitempty"`
+
+	// The BIP 324 session id, empty for v1 connections
+	SessionID string `json:"session_id,omitempty"`
+
+	// Whether the connection was made with addnode, replaced by
+	// ConnectionType in Core 0.21
+	Addnode bool `json:"addnode,omitempty"`
+
 	// The ban score (stats.nMisbehavior)
+	//
+	// Deprecated: removed in Core 22, it is always 0 on recent nodes.
 	Banscore int32 `json:"banscore"`
 
 	// If sync node
+	//
+	// Deprecated: removed in Core 0.10, it is always false on recent nodes.
 	Syncnode bool `json:"syncnode"`
 }
+
+// HasPermission tells whether the peer was granted [permission]
+func (p Peer) HasPermission(permission string) bool {
+	for _, perm := range p.Permissions {
+		if perm == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Connection returns the connection type of the peer, derived from Inbound
+// and Addnode for nodes older than Core 0.21
+func (p Peer) Connection() string {
+	switch {
+	case p.ConnectionType != "":
+		return p.ConnectionType
+	case p.Inbound:
+		return ConnectionInbound
+	case p.Addnode:
+		return ConnectionManual
+	}
+	return ConnectionOutboundFullRelay
+}
@@ -0,0 +1,434 @@
+package bitcoind
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A PeerAction is what a PeerSupervisor does with the peers breaking a rule
+type PeerAction string
+
+// Peer actions
+const (
+	// Disconnect the peer, which may connect again
+	PeerDisconnect PeerAction = "disconnect"
+
+	// Ban the peer IP for the rule BanDuration, which disconnects it.
+	// Peers whose address can't be banned are disconnected instead.
+	PeerBan PeerAction = "ban"
+)
+
+// A PeerViolation is a peer breaking a rule, with the reason why
+type PeerViolation struct {
+	Peer   Peer
+	Reason string
+}
+
+// A PeerRule is a policy evaluated on the connected peers
+type PeerRule struct {
+	// The rule name, reported in the audit log
+	Name string
+
+	// What to do with the peers breaking the rule, PeerDisconnect if empty
+	Action PeerAction
+
+	// The ban duration for PeerBan, the node default (-bantime) if 0.
+	// It is rounded up to the second.
+	BanDuration time.Duration
+
+	// Match returns the peers breaking the rule among all connected peers
+	Match func(peers []Peer) []PeerViolation
+}
+
+// matchEach returns a Match function applying check to each peer
+func matchEach(check func(p Peer) (reason string, broken bool)) func(peers []Peer) []PeerViolation {
+	return func(peers []Peer) (violations []PeerViolation) {
+		for _, p := range peers {
+			if reason, broken := check(p); broken {
+				violations = append(violations, PeerViolation{Peer: p, Reason: reason})
+			}
+		}
+		return
+	}
+}
+
+// satoshiVersion matches the version of Bitcoin Core user agents
+var satoshiVersion = regexp.MustCompile(`^/Satoshi:(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseVersion returns the numbers of a dotted version, such as "0.21.1"
+func parseVersion(version string) ([]int, error) {
+	parts := strings.Split(version, ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("Bad version %q", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// compareVersions compares two dotted versions, missing numbers being 0
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// StaleSubversionRule returns a rule matching Bitcoin Core peers
+// ("/Satoshi:x.y.z/") older than [minVersion], such as "0.21.0" or "25.0".
+// Other user agents are left to UnknownUserAgentRule.
+func StaleSubversionRule(minVersion string) (PeerRule, error) {
+	min, err := parseVersion(minVersion)
+	if err != nil {
+		return PeerRule{}, err
+	}
+	return PeerRule{
+		Name: "stale-subversion",
+		Match: matchEach(func(p Peer) (string, bool) {
+			m := satoshiVersion.FindStringSubmatch(p.Subver)
+			if m == nil {
+				return "", false
+			}
+			version := m[1] + "." + m[2]
+			if m[3] != "" {
+				version += "." + m[3]
+			}
+			numbers, err := parseVersion(version)
+			if err != nil || compareVersions(numbers, min) >= 0 {
+				return "", false
+			}
+			return fmt.Sprintf("subversion %s is older than %s", p.Subver, minVersion), true
+		}),
+	}, nil
+}
+
+// ExcessivePingRule returns a rule matching peers whose last ping time, or
+// current ping wait, exceeds [max]
+func ExcessivePingRule(max time.Duration) PeerRule {
+	return PeerRule{
+		Name: "excessive-ping",
+		Match: matchEach(func(p Peer) (string, bool) {
+			ping := p.Pingtime
+			if p.Pingwait > ping {
+				ping = p.Pingwait
+			}
+			if time.Duration(ping*float64(time.Second)) <= max {
+				return "", false
+			}
+			return fmt.Sprintf("ping %.3fs exceeds %s", ping, max), true
+		}),
+	}
+}
+
+// InboundPerSubnetRule returns a rule matching inbound IPv4 peers beyond the
+// [max] oldest connections from the same /16 subnet.
+// Only peers of the ipv4 network are matched: inbound Tor and I2P peers
+// appear with a local address, and peers from nodes older than Core 22 have
+// no network.
+func InboundPerSubnetRule(max int) PeerRule {
+	return PeerRule{
+		Name: "inbound-per-subnet",
+		Match: func(peers []Peer) (violations []PeerViolation) {
+			subnets := make(map[string][]Peer)
+			var order []string
+			for _, p := range peers {
+				if p.Connection() != ConnectionInbound || p.Network != NetworkIPv4 {
+					continue
+				}
+				subnet, ok := subnet16(p.Addr)
+				if !ok {
+					continue
+				}
+				if _, seen := subnets[subnet]; !seen {
+					order = append(order, subnet)
+				}
+				subnets[subnet] = append(subnets[subnet], p)
+			}
+			for _, subnet := range order {
+				group := subnets[subnet]
+				if len(group) <= max {
+					continue
+				}
+				sort.SliceStable(group, func(i, j int) bool {
+					if group[i].Conntime != group[j].Conntime {
+						return group[i].Conntime < group[j].Conntime
+					}
+					return group[i].ID < group[j].ID
+				})
+				for _, p := range group[max:] {
+					violations = append(violations, PeerViolation{Peer: p, Reason: fmt.Sprintf("%d inbound peers from %s exceed %d", len(group), subnet, max)})
+				}
+			}
+			return
+		},
+	}
+}
+
+// subnet16 returns the /16 subnet of an IPv4 "host:port" address
+func subnet16(addr string) (string, bool) {
+	ip := net.ParseIP(peerHost(addr)).To4()
+	if ip == nil {
+		return "", false
+	}
+	return fmt.Sprintf("%d.%d.0.0/16", ip[0], ip[1]), true
+}
+
+// UnknownUserAgentRule returns a rule matching peers whose user agent starts
+// with none of [allowed], such as "/Satoshi:".
+// Peers which have not completed the version handshake, with a version of
+// 0, have no user agent yet and are not matched.
+func UnknownUserAgentRule(allowed ...string) PeerRule {
+	return PeerRule{
+		Name: "unknown-user-agent",
+		Match: matchEach(func(p Peer) (string, bool) {
+			if p.Version == 0 {
+				return "", false
+			}
+			for _, prefix := range allowed {
+				if strings.HasPrefix(p.Subver, prefix) {
+					return "", false
+				}
+			}
+			return fmt.Sprintf("unknown user agent %q", p.Subver), true
+		}),
+	}
+}
+
+// A PeerAuditEntry records an action taken, or that would have been taken in
+// dry run, by a PeerSupervisor
+type PeerAuditEntry struct {
+	// The time of the action
+	Time time.Time
+
+	// The peer
+	PeerID int
+	Addr   string
+	Subver string
+
+	// The broken rule and the reason
+	Rule   string
+	Reason string
+
+	// The action and, for bans, the banned subnet and ban duration (0 for
+	// the node default)
+	Action      PeerAction
+	Subnet      string
+	BanDuration time.Duration
+
+	// Whether the action was only logged
+	DryRun bool
+
+	// The error of the action, nil on success
+	Err error
+}
+
+// String returns the entry as an audit log line
+func (e PeerAuditEntry) String() string {
+	line := fmt.Sprintf("%s peer=%d addr=%s subver=%q rule=%s action=%s", e.Time.UTC().Format(time.RFC3339), e.PeerID, e.Addr, e.Subver, e.Rule, e.Action)
+	if e.Action == PeerBan {
+		line += fmt.Sprintf(" subnet=%s duration=%s", e.Subnet, e.BanDuration)
+	}
+	line += fmt.Sprintf(" reason=%q", e.Reason)
+	if e.DryRun {
+		line += " dryrun"
+	}
+	if e.Err != nil {
+		line += fmt.Sprintf(" error=%q", e.Err.Error())
+	}
+	return line
+}
+
+// A PeerSupervisor polls the peers of a node, evaluates rules on them and
+// disconnects or bans the peers breaking them.
+// Manual connections and peers with the "noban" permission are never acted
+// on. A peer breaking several rules is only acted on for the first one, and
+// peers sharing a banned IP are not acted on again.
+// Peers of the onion, i2p and not_publicly_routable networks, or with a
+// loopback address, such as inbound Tor peers, share their address with other
+// peers: they are never banned, only disconnected by id.
+type PeerSupervisor struct {
+	// The rules, evaluated in order
+	Rules []PeerRule
+
+	// The polling interval of Run, a minute if not positive
+	Interval time.Duration
+
+	// If set, actions are only audited
+	DryRun bool
+
+	// Audit is called for each action, if set
+	Audit func(entry PeerAuditEntry)
+
+	// OnError is called when Run fails to get the peers, if set
+	OnError func(err error)
+
+	b   *Bitcoind
+	now func() time.Time
+}
+
+// NewPeerSupervisor returns a supervisor of the peers of [b] polling every
+// minute
+func NewPeerSupervisor(b *Bitcoind, rules ...PeerRule) *PeerSupervisor {
+	return &PeerSupervisor{Rules: rules, Interval: time.Minute, b: b, now: time.Now}
+}
+
+// Check gets the peers, evaluates the rules and applies their actions once.
+// It returns the audit entries of the actions; errors of actions are
+// reported in the entries.
+func (s *PeerSupervisor) Check() ([]PeerAuditEntry, error) {
+	peers, err := s.b.GetPeerInfo()
+	if err != nil {
+		return nil, err
+	}
+	var candidates []Peer
+	for _, p := range peers {
+		if p.Connection() != ConnectionManual && !p.HasPermission("noban") {
+			candidates = append(candidates, p)
+		}
+	}
+
+	var entries []PeerAuditEntry
+	handled := make(map[int]bool)
+	banned := make(map[string]bool)
+	for _, rule := range s.Rules {
+		if rule.Match == nil {
+			continue
+		}
+		for _, v := range rule.Match(candidates) {
+			host := peerHost(v.Peer.Addr)
+			if handled[v.Peer.ID] || (!sharedAddress(v.Peer) && banned[host]) {
+				continue
+			}
+			handled[v.Peer.ID] = true
+			entry := s.apply(rule, v)
+			if entry.Action == PeerBan {
+				banned[host] = true
+			}
+			if s.Audit != nil {
+				s.Audit(entry)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// apply takes the action of rule on the peer of v
+func (s *PeerSupervisor) apply(rule PeerRule, v PeerViolation) PeerAuditEntry {
+	entry := PeerAuditEntry{
+		Time:   s.now(),
+		PeerID: v.Peer.ID,
+		Addr:   v.Peer.Addr,
+		Subver: v.Peer.Subver,
+		Rule:   rule.Name,
+		Reason: v.Reason,
+		Action: rule.Action,
+		DryRun: s.DryRun,
+	}
+	if entry.Action == "" {
+		entry.Action = PeerDisconnect
+	}
+	shared := sharedAddress(v.Peer)
+	if entry.Action == PeerBan {
+		if shared {
+			entry.Action = PeerDisconnect
+			entry.Reason += ", disconnected as its address is shared"
+		} else {
+			entry.Subnet = peerHost(v.Peer.Addr)
+			entry.BanDuration = banDuration(rule.BanDuration)
+		}
+	}
+	if s.DryRun {
+		return entry
+	}
+	switch entry.Action {
+	case PeerBan:
+		entry.Err = s.b.SetBan(entry.Subnet, "add", int64(entry.BanDuration/time.Second), false)
+	case PeerDisconnect:
+		if shared {
+			entry.Err = s.b.DisconnectNodeID(v.Peer.ID)
+		} else {
+			entry.Err = s.b.DisconnectNode(v.Peer.Addr)
+		}
+	default:
+		entry.Err = fmt.Errorf("Unknown peer action %q", entry.Action)
+	}
+	return entry
+}
+
+// Run calls Check every Interval until [stop] is closed
+func (s *PeerSupervisor) Run(stop <-chan struct{}) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Check(); err != nil && s.OnError != nil {
+			s.OnError(err)
+		}
+		// Stop first if both stop and a tick are ready
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// banDuration rounds a ban duration up to the second, as a sub-second
+// duration would be sent as 0, the node default
+func banDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return (d + time.Second - 1) / time.Second * time.Second
+}
+
+// sharedAddress tells whether the peer address may be shared with other
+// peers, and so must not be banned: peers of the onion, i2p and
+// not_publicly_routable networks, and peers with a loopback or non IP
+// address
+func sharedAddress(p Peer) bool {
+	switch p.Network {
+	case NetworkOnion, NetworkI2P, NetworkNotPubliclyRoutable:
+		return true
+	}
+	ip := net.ParseIP(peerHost(p.Addr))
+	return ip == nil || ip.IsLoopback()
+}
+
+// peerHost returns the host of a peer "host:port" address
+func peerHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,231 @@
+package bitcoind
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// supervisedPeers is a getpeerinfo response holding a peer for each rule,
+// inbound Tor peers sharing a loopback address, a peer which has not
+// completed the version handshake and a peer sharing the IP of another one
+const supervisedPeers = `[
+	{"id":1,"addr":"198.51.100.1:8333","network":"ipv4","version":70016,"subver":"/Satoshi:0.20.1/","inbound":false,"connection_type":"outbound-full-relay","pingtime":0.1,"conntime":100},
+	{"id":2,"addr":"198.51.100.2:8333","network":"ipv4","version":70016,"subver":"/Satoshi:27.0.0/","inbound":false,"connection_type":"block-relay-only","pingtime":4.5,"conntime":100},
+	{"id":3,"addr":"203.0.113.10:51000","network":"ipv4","version":70016,"subver":"/Satoshi:27.0.0/","inbound":true,"connection_type":"inbound","pingtime":0.1,"conntime":100},
+	{"id":4,"addr":"203.0.113.11:51001","network":"ipv4","version":70016,"subver":"/Satoshi:27.0.0/","inbound":true,"connection_type":"inbound","pingtime":0.1,"conntime":300},
+	{"id":5,"addr":"203.0.200.12:51002","network":"ipv4","version":70016,"subver":"/Satoshi:27.0.0/","inbound":true,"connection_type":"inbound","pingtime":0.1,"conntime":200},
+	{"id":6,"addr":"[2001:db8::6]:8333","network":"ipv6","version":70016,"subver":"/btcwire:0.5.0/","inbound":true,"connection_type":"inbound","pingtime":0.1,"conntime":100},
+	{"id":7,"addr":"192.0.2.7:8333","network":"ipv4","version":70016,"subver":"/Satoshi:0.15.0/","inbound":false,"connection_type":"manual","pingtime":9,"conntime":100},
+	{"id":8,"addr":"192.0.2.8:8333","network":"ipv4","version":70016,"subver":"/evil:1.0/","inbound":true,"connection_type":"inbound","permissions":["noban"],"pingtime":0.1,"conntime":100},
+	{"id":9,"addr":"127.0.0.1:50001","network":"onion","version":70016,"subver":"/Satoshi:0.20.1/","inbound":true,"connection_type":"inbound","pingtime":0.1,"conntime":100},
+	{"id":10,"addr":"127.0.0.1:50002","network":"onion","version":70016,"subver":"/Satoshi:27.0.0/","inbound":true,"connection_type":"inbound","pingtime":0.1,"conntime":200},
+	{"id":11,"addr":"100.64.0.11:51011","network":"ipv4","version":0,"subver":"","inbound":true,"connection_type":"inbound","pingtime":0,"conntime":400},
+	{"id":12,"addr":"198.51.100.1:51012","network":"ipv4","version":70016,"subver":"/Satoshi:0.20.1/","inbound":true,"connection_type":"inbound","pingtime":0.1,"conntime":100}
+]`
+
+// supervisedResults are the results of a node with supervisedPeers accepting
+// bans and disconnections
+var supervisedResults = map[string]string{
+	"getpeerinfo":    supervisedPeers,
+	"setban":         "null",
+	"disconnectnode": "null",
+}
+
+var _ = Describe("PeerSupervisor", func() {
+	Describe("Testing rules", func() {
+		var peers []Peer
+		BeforeEach(func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(supervisedResults, &calls)
+			defer done()
+			var err error
+			peers, err = bitcoindClient.GetPeerInfo()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		matchedIDs := func(rule PeerRule) (ids []int) {
+			for _, v := range rule.Match(peers) {
+				ids = append(ids, v.Peer.ID)
+			}
+			return
+		}
+		It("should match stale subversions", func() {
+			rule, err := StaleSubversionRule("0.21")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matchedIDs(rule)).To(Equal([]int{1, 7, 9, 12}))
+			rule, err = StaleSubversionRule("27.0.1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matchedIDs(rule)).To(Equal([]int{1, 2, 3, 4, 5, 7, 9, 10, 12}))
+			_, err = StaleSubversionRule("v27")
+			Expect(err).To(HaveOccurred())
+		})
+		It("should match excessive pings", func() {
+			Expect(matchedIDs(ExcessivePingRule(2 * time.Second))).To(Equal([]int{2, 7}))
+		})
+		It("should match inbound IPv4 peers beyond the limit of a /16", func() {
+			Expect(matchedIDs(InboundPerSubnetRule(1))).To(Equal([]int{5, 4}))
+			Expect(InboundPerSubnetRule(1).Match(peers)[0].Reason).To(Equal("3 inbound peers from 203.0.0.0/16 exceed 1"))
+			Expect(matchedIDs(InboundPerSubnetRule(3))).To(BeEmpty())
+		})
+		It("should match unknown user agents of peers past the handshake", func() {
+			Expect(matchedIDs(UnknownUserAgentRule("/Satoshi:"))).To(Equal([]int{6, 8}))
+		})
+	})
+
+	Describe("Testing Check", func() {
+		Context("when peers break rules", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(supervisedResults, &calls)
+			defer done()
+			stale, _ := StaleSubversionRule("0.21")
+			stale.Action = PeerBan
+			stale.BanDuration = 24 * time.Hour
+			var audited []PeerAuditEntry
+			s := NewPeerSupervisor(bitcoindClient, stale, ExcessivePingRule(2*time.Second), UnknownUserAgentRule("/Satoshi:"))
+			s.now = func() time.Time { return time.Unix(1700000000, 0) }
+			s.Audit = func(entry PeerAuditEntry) { audited = append(audited, entry) }
+			entries, err := s.Check()
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should ban and disconnect the peers", func() {
+				Expect(calls).To(Equal([]string{
+					"getpeerinfo []",
+					"setban [198.51.100.1 add 86400 false]",
+					"disconnectnode [ 9]",
+					"disconnectnode [198.51.100.2:8333]",
+					"disconnectnode [[2001:db8::6]:8333]",
+				}))
+			})
+			It("should audit the actions", func() {
+				Expect(audited).To(Equal(entries))
+				Expect(entries).To(HaveLen(4))
+				Expect(entries[0]).To(Equal(PeerAuditEntry{
+					Time:        time.Unix(1700000000, 0),
+					PeerID:      1,
+					Addr:        "198.51.100.1:8333",
+					Subver:      "/Satoshi:0.20.1/",
+					Rule:        "stale-subversion",
+					Reason:      "subversion /Satoshi:0.20.1/ is older than 0.21",
+					Action:      PeerBan,
+					Subnet:      "198.51.100.1",
+					BanDuration: 24 * time.Hour,
+				}))
+				Expect(entries[0].String()).To(Equal(`2023-11-14T22:13:20Z peer=1 addr=198.51.100.1:8333 subver="/Satoshi:0.20.1/" rule=stale-subversion action=ban subnet=198.51.100.1 duration=24h0m0s reason="subversion /Satoshi:0.20.1/ is older than 0.21"`))
+				Expect(entries[2].Action).To(Equal(PeerDisconnect))
+				Expect(entries[2].Rule).To(Equal("excessive-ping"))
+			})
+			It("should only disconnect peers with a shared address", func() {
+				Expect(entries[1].PeerID).To(Equal(9))
+				Expect(entries[1].Action).To(Equal(PeerDisconnect))
+				Expect(entries[1].Subnet).To(BeEmpty())
+				Expect(entries[1].Reason).To(Equal("subversion /Satoshi:0.20.1/ is older than 0.21, disconnected as its address is shared"))
+			})
+			It("should spare manual and noban peers", func() {
+				for _, entry := range entries {
+					Expect(entry.PeerID).NotTo(BeElementOf(7, 8))
+				}
+			})
+			It("should not ban an IP twice", func() {
+				for _, entry := range entries {
+					Expect(entry.PeerID).NotTo(Equal(12))
+				}
+			})
+		})
+
+		Context("when the ban duration is below a second", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(supervisedResults, &calls)
+			defer done()
+			stale, _ := StaleSubversionRule("0.21")
+			stale.Action = PeerBan
+			stale.BanDuration = 100 * time.Millisecond
+			entries, err := NewPeerSupervisor(bitcoindClient, stale).Check()
+			It("should round it up to a second", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls).To(ContainElement("setban [198.51.100.1 add 1 false]"))
+				Expect(entries[0].BanDuration).To(Equal(time.Second))
+			})
+		})
+
+		Context("when in dry run", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(supervisedResults, &calls)
+			defer done()
+			s := NewPeerSupervisor(bitcoindClient, ExcessivePingRule(2*time.Second))
+			s.DryRun = true
+			entries, err := s.Check()
+			It("should only audit", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls).To(Equal([]string{"getpeerinfo []"}))
+				Expect(entries).To(HaveLen(1))
+				Expect(entries[0].DryRun).To(BeTrue())
+				Expect(entries[0].String()).To(HaveSuffix(" dryrun"))
+			})
+		})
+
+		Context("when an action fails", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(map[string]string{"getpeerinfo": supervisedPeers}, &calls, map[string]RPCError{
+				"disconnectnode": {Code: -29, Message: "Node not found in connected nodes"},
+			})
+			defer done()
+			entries, err := NewPeerSupervisor(bitcoindClient, ExcessivePingRule(2*time.Second)).Check()
+			It("should report the error in the audit entry", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(HaveLen(1))
+				Expect(entries[0].Err).To(Equal(&RPCError{Code: -29, Message: "Node not found in connected nodes"}))
+			})
+		})
+
+		Context("when the peers cannot be listed", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(nil, &calls, map[string]RPCError{
+				"getpeerinfo": {Code: -28, Message: "Loading block index..."},
+			})
+			defer done()
+			_, err := NewPeerSupervisor(bitcoindClient, ExcessivePingRule(2*time.Second)).Check()
+			It("should return the error", func() {
+				Expect(err).To(Equal(&RPCError{Code: -28, Message: "Loading block index..."}))
+			})
+		})
+	})
+
+	Describe("Testing Run", func() {
+		It("should poll until stopped", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(supervisedResults, &calls)
+			defer done()
+			s := NewPeerSupervisor(bitcoindClient)
+			s.Interval = time.Millisecond
+			stop := make(chan struct{})
+			stopped := make(chan struct{})
+			polls := 0
+			s.Rules = []PeerRule{{Name: "count", Match: func(peers []Peer) []PeerViolation {
+				if polls++; polls == 3 {
+					close(stop)
+				}
+				return nil
+			}}}
+			go func() {
+				s.Run(stop)
+				close(stopped)
+			}()
+			Eventually(stopped).Should(BeClosed())
+			Expect(polls).To(Equal(3))
+		})
+		It("should fall back to the default interval", func() {
+			var calls []string
+			bitcoindClient, done := newTestNode(supervisedResults, &calls)
+			defer done()
+			stop := make(chan struct{})
+			s := NewPeerSupervisor(bitcoindClient, PeerRule{Name: "stop", Match: func(peers []Peer) []PeerViolation {
+				close(stop)
+				return nil
+			}})
+			s.Interval = 0
+			Expect(func() { s.Run(stop) }).NotTo(Panic())
+		})
+	})
+})
@@ -0,0 +1,154 @@
+// Package pow provides conversions between the representations of proof of
+// work used by bitcoind: compact bits, targets, difficulty, work and chainwork.
+package pow
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// DifficultyOneBits is the compact target of difficulty 1 on mainnet
+const DifficultyOneBits uint32 = 0x1d00ffff
+
+var (
+	// difficultyOneTarget is the target of difficulty 1
+	difficultyOneTarget, _ = CompactToBig(DifficultyOneBits)
+
+	// oneLsh256 is 2^256
+	oneLsh256 = new(big.Int).Lsh(big.NewInt(1), 256)
+)
+
+// CompactToBig converts a compact representation of a target (as found in the
+// bits field of a header) to a big.Int
+func CompactToBig(bits uint32) (*big.Int, error) {
+	mantissa := bits & 0x007fffff
+	exponent := uint(bits >> 24)
+	if mantissa != 0 && bits&0x00800000 != 0 {
+		return nil, fmt.Errorf("Negative target in bits %08x", bits)
+	}
+	var target *big.Int
+	if exponent <= 3 {
+		target = big.NewInt(int64(mantissa >> (8 * (3 - exponent))))
+	} else {
+		target = new(big.Int).Lsh(big.NewInt(int64(mantissa)), 8*(exponent-3))
+	}
+	if target.BitLen() > 256 {
+		return nil, fmt.Errorf("Target overflow in bits %08x", bits)
+	}
+	return target, nil
+}
+
+// BigToCompact converts a non negative target to its compact representation
+func BigToCompact(target *big.Int) uint32 {
+	if target.Sign() <= 0 {
+		return 0
+	}
+	exponent := uint((target.BitLen() + 7) / 8)
+	var mantissa uint32
+	if exponent <= 3 {
+		mantissa = uint32(target.Uint64() << (8 * (3 - exponent)))
+	} else {
+		mantissa = uint32(new(big.Int).Rsh(target, 8*(exponent-3)).Uint64())
+	}
+	// The sign bit is set, shift the mantissa to keep the target positive
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+	return uint32(exponent<<24) | mantissa
+}
+
+// ParseCompact parses the hexadecimal bits string returned by getblock
+func ParseCompact(bits string) (uint32, error) {
+	compact, err := strconv.ParseUint(bits, 16, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(compact), nil
+}
+
+// FormatCompact formats bits as the hexadecimal string returned by getblock
+func FormatCompact(bits uint32) string {
+	return fmt.Sprintf("%08x", bits)
+}
+
+// TargetToDifficulty returns the difficulty of target, as a multiple of the
+// difficulty 1 target
+func TargetToDifficulty(target *big.Int) (float64, error) {
+	if target.Sign() <= 0 {
+		return 0, errors.New("Target must be positive")
+	}
+	difficulty, _ := new(big.Rat).SetFrac(difficultyOneTarget, target).Float64()
+	return difficulty, nil
+}
+
+// CompactToDifficulty returns the difficulty of the compact target bits
+func CompactToDifficulty(bits uint32) (float64, error) {
+	target, err := CompactToBig(bits)
+	if err != nil {
+		return 0, err
+	}
+	return TargetToDifficulty(target)
+}
+
+// Work returns the expected number of hashes needed to find a block below
+// target, that is 2^256 / (target + 1).
+// Like bitcoind, it returns 0 for an invalid target: zero, negative or above
+// 256 bits.
+func Work(target *big.Int) *big.Int {
+	if target.Sign() <= 0 || target.BitLen() > 256 {
+		return new(big.Int)
+	}
+	return new(big.Int).Div(oneLsh256, new(big.Int).Add(target, big.NewInt(1)))
+}
+
+// CompactToWork returns the work of a block with compact target bits
+func CompactToWork(bits uint32) (*big.Int, error) {
+	target, err := CompactToBig(bits)
+	if err != nil {
+		return nil, err
+	}
+	return Work(target), nil
+}
+
+// ParseChainwork parses the hexadecimal chainwork string returned by
+// getblock and getblockheader
+func ParseChainwork(chainwork string) (*big.Int, error) {
+	work, ok := new(big.Int).SetString(chainwork, 16)
+	if !ok || work.Sign() < 0 {
+		return nil, fmt.Errorf("Bad chainwork: %s", chainwork)
+	}
+	return work, nil
+}
+
+// FormatChainwork formats work as the 64 characters hexadecimal string
+// returned by getblock and getblockheader
+func FormatChainwork(work *big.Int) string {
+	return fmt.Sprintf("%064x", work)
+}
+
+// CompareChainwork compares two hexadecimal chainworks and returns -1, 0 or
+// +1 when a is respectively lower than, equal to or greater than b
+func CompareChainwork(a, b string) (int, error) {
+	workA, err := ParseChainwork(a)
+	if err != nil {
+		return 0, err
+	}
+	workB, err := ParseChainwork(b)
+	if err != nil {
+		return 0, err
+	}
+	return workA.Cmp(workB), nil
+}
+
+// Hashrate returns the network hashrate, in hashes per second, expected to
+// find blocks of the given difficulty every interval
+func Hashrate(difficulty float64, interval time.Duration) float64 {
+	if interval <= 0 {
+		return 0
+	}
+	return difficulty * (1 << 32) / interval.Seconds()
+}
@@ -0,0 +1,13 @@
+package pow
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPow(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pow Suite")
+}
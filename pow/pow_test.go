@@ -0,0 +1,126 @@
+package pow
+
+import (
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func mustBig(str string) *big.Int {
+	n, ok := new(big.Int).SetString(str, 16)
+	if !ok {
+		panic(str)
+	}
+	return n
+}
+
+var _ = Describe("Pow", func() {
+	Describe("Compact bits", func() {
+		Context("when bits are the difficulty 1 target", func() {
+			target, err := CompactToBig(DifficultyOneBits)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should decode the target", func() {
+				Expect(target).To(Equal(mustBig("00000000ffff0000000000000000000000000000000000000000000000000000")))
+			})
+			It("should encode back to the same bits", func() {
+				Expect(BigToCompact(target)).To(Equal(DifficultyOneBits))
+			})
+		})
+		Context("when bits have a small exponent", func() {
+			target, err := CompactToBig(0x01123456)
+			It("should drop the shifted bytes", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(target.Int64()).To(Equal(int64(0x12)))
+				Expect(BigToCompact(target)).To(Equal(uint32(0x01120000)))
+			})
+		})
+		Context("when the target high bit would be the sign bit", func() {
+			It("should shift the mantissa", func() {
+				Expect(BigToCompact(big.NewInt(0x80))).To(Equal(uint32(0x02008000)))
+			})
+		})
+		Context("when bits encode a negative target", func() {
+			_, err := CompactToBig(0x04923456)
+			It("error should occured", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("when bits overflow 256 bits", func() {
+			_, err := CompactToBig(0xff123456)
+			It("error should occured", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("when bits come from getblock", func() {
+			It("should parse and format the hex string", func() {
+				bits, err := ParseCompact("1b0404cb")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bits).To(Equal(uint32(0x1b0404cb)))
+				Expect(FormatCompact(bits)).To(Equal("1b0404cb"))
+			})
+		})
+	})
+
+	Describe("Difficulty", func() {
+		It("should be 1 for the difficulty 1 target", func() {
+			Expect(CompactToDifficulty(DifficultyOneBits)).To(Equal(1.0))
+		})
+		It("should match bitcoind for bits 1b0404cb", func() {
+			difficulty, err := CompactToDifficulty(0x1b0404cb)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(difficulty).To(BeNumerically("~", 16307.420938523983, 1e-9))
+		})
+		It("should refuse a zero target", func() {
+			_, err := TargetToDifficulty(new(big.Int))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Work", func() {
+		It("should be 0x100010001 for the difficulty 1 target", func() {
+			work, err := CompactToWork(DifficultyOneBits)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(work).To(Equal(mustBig("100010001")))
+		})
+		It("should sum up to the chainwork of block 1", func() {
+			work, _ := CompactToWork(DifficultyOneBits)
+			chainwork, err := ParseChainwork("0000000000000000000000000000000000000000000000000000000200020002")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(new(big.Int).Add(work, work)).To(Equal(chainwork))
+			Expect(FormatChainwork(chainwork)).To(Equal("0000000000000000000000000000000000000000000000000000000200020002"))
+		})
+		It("should be 0 for an invalid target", func() {
+			Expect(Work(new(big.Int)).Sign()).To(Equal(0))
+			Expect(Work(big.NewInt(-1)).Sign()).To(Equal(0))
+			Expect(Work(new(big.Int).Lsh(big.NewInt(1), 256)).Sign()).To(Equal(0))
+			work, err := CompactToWork(0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(work.Sign()).To(Equal(0))
+		})
+	})
+
+	Describe("Compare chainwork", func() {
+		It("should order chainworks", func() {
+			Expect(CompareChainwork("0200020002", "0000000000000000000000000000000000000000000000000000000100010001")).To(Equal(1))
+			Expect(CompareChainwork("0100010001", "0200020002")).To(Equal(-1))
+			Expect(CompareChainwork("0100010001", "000100010001")).To(Equal(0))
+		})
+		It("should refuse a bad chainwork", func() {
+			_, err := CompareChainwork("xyz", "01")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Hashrate", func() {
+		It("should be 2^32 hashes per block interval at difficulty 1", func() {
+			Expect(Hashrate(1, 10*time.Minute)).To(BeNumerically("~", 7158278.826666667, 1e-6))
+		})
+		It("should be 0 for a null interval", func() {
+			Expect(Hashrate(1, 0)).To(BeZero())
+		})
+	})
+})
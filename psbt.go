@@ -0,0 +1,327 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// PsbtResult represents a response to "walletprocesspsbt" call
+type PsbtResult struct {
+	// The base64-encoded partially signed transaction
+	Psbt string `json:"psbt"`
+	// If the transaction has a complete set of signatures
+	Complete bool `json:"complete"`
+	// The hex-encoded network transaction if complete
+	Hex string `json:"hex,omitempty"`
+}
+
+// FinalizedPsbt represents a response to "finalizepsbt" call
+type FinalizedPsbt struct {
+	// The base64-encoded partially signed transaction if not extracted
+	Psbt string `json:"psbt,omitempty"`
+	// The hex-encoded network transaction if extracted
+	Hex string `json:"hex,omitempty"`
+	// If the transaction has a complete set of signatures
+	Complete bool `json:"complete"`
+}
+
+// WalletCreateFundedPsbtResult represents a response to
+// "walletcreatefundedpsbt" call
+type WalletCreateFundedPsbtResult struct {
+	// The resulting raw transaction (base64-encoded string)
+	Psbt string `json:"psbt"`
+	// Fee the resulting transaction pays
+	Fee Amount `json:"fee"`
+	// The position of the added change output, or -1
+	ChangePos int `json:"changepos"`
+}
+
+// PsbtBip32Deriv represents a BIP32 derivation of a public key in a PSBT
+type PsbtBip32Deriv struct {
+	// The public key with the derivation path as the value
+	PubKey string `json:"pubkey"`
+	// The fingerprint of the master key
+	MasterFingerprint string `json:"master_fingerprint"`
+	// The path
+	Path string `json:"path"`
+}
+
+// PsbtTaprootBip32Deriv represents a BIP32 derivation of a taproot public key
+// in a PSBT
+type PsbtTaprootBip32Deriv struct {
+	// The x-only public key
+	PubKey string `json:"pubkey"`
+	// The fingerprint of the master key
+	MasterFingerprint string `json:"master_fingerprint"`
+	// The path
+	Path string `json:"path"`
+	// The hashes of the leaves this pubkey appears in
+	LeafHashes []string `json:"leaf_hashes"`
+}
+
+// PsbtScript represents a script found in a PSBT input or output
+type PsbtScript struct {
+	Asm  string `json:"asm"`
+	Hex  string `json:"hex"`
+	Type string `json:"type"`
+}
+
+// PsbtXpub represents a global extended public key of a PSBT
+type PsbtXpub struct {
+	// The extended public key this path corresponds to
+	Xpub string `json:"xpub"`
+	// The fingerprint of the master key
+	MasterFingerprint string `json:"master_fingerprint"`
+	// The path
+	Path string `json:"path"`
+}
+
+// PsbtProprietary represents a proprietary key-value pair of a PSBT
+type PsbtProprietary struct {
+	// The hex string for the proprietary identifier
+	Identifier string `json:"identifier"`
+	// The number for the subtype
+	Subtype int `json:"subtype"`
+	// The hex for the key
+	Key string `json:"key"`
+	// The hex for the value
+	Value string `json:"value"`
+}
+
+// PsbtWitnessUtxo represents the output spent by a segwit PSBT input
+type PsbtWitnessUtxo struct {
+	Amount       Amount       `json:"amount"`
+	ScriptPubKey ScriptPubKey `json:"scriptPubKey"`
+}
+
+// DecodedPsbtInput represents an input of a decoded PSBT
+type DecodedPsbtInput struct {
+	NonWitnessUtxo     *RawTransaction         `json:"non_witness_utxo,omitempty"`
+	WitnessUtxo        *PsbtWitnessUtxo        `json:"witness_utxo,omitempty"`
+	PartialSignatures  map[string]string       `json:"partial_signatures,omitempty"`
+	Sighash            string                  `json:"sighash,omitempty"`
+	RedeemScript       *PsbtScript             `json:"redeem_script,omitempty"`
+	WitnessScript      *PsbtScript             `json:"witness_script,omitempty"`
+	Bip32Derivs        []PsbtBip32Deriv        `json:"bip32_derivs,omitempty"`
+	FinalScriptSig     *ScriptSig              `json:"final_scriptSig,omitempty"`
+	FinalScriptWitness []string                `json:"final_scriptwitness,omitempty"`
+	TaprootKeyPathSig  string                  `json:"taproot_key_path_sig,omitempty"`
+	TaprootBip32Derivs []PsbtTaprootBip32Deriv `json:"taproot_bip32_derivs,omitempty"`
+	TaprootInternalKey string                  `json:"taproot_internal_key,omitempty"`
+	TaprootMerkleRoot  string                  `json:"taproot_merkle_root,omitempty"`
+	Proprietary        []PsbtProprietary       `json:"proprietary,omitempty"`
+	Unknown            map[string]string       `json:"unknown,omitempty"`
+}
+
+// DecodedPsbtOutput represents an output of a decoded PSBT
+type DecodedPsbtOutput struct {
+	RedeemScript       *PsbtScript             `json:"redeem_script,omitempty"`
+	WitnessScript      *PsbtScript             `json:"witness_script,omitempty"`
+	Bip32Derivs        []PsbtBip32Deriv        `json:"bip32_derivs,omitempty"`
+	TaprootInternalKey string                  `json:"taproot_internal_key,omitempty"`
+	TaprootBip32Derivs []PsbtTaprootBip32Deriv `json:"taproot_bip32_derivs,omitempty"`
+	Proprietary        []PsbtProprietary       `json:"proprietary,omitempty"`
+	Unknown            map[string]string       `json:"unknown,omitempty"`
+}
+
+// DecodedPsbt represents a response to "decodepsbt" call
+type DecodedPsbt struct {
+	// The decoded network-serialized unsigned transaction
+	Tx RawTransaction `json:"tx"`
+	// The global extended public keys
+	GlobalXpubs []PsbtXpub `json:"global_xpubs,omitempty"`
+	// The PSBT version number
+	PsbtVersion uint32 `json:"psbt_version"`
+	// The global proprietary map
+	Proprietary []PsbtProprietary `json:"proprietary,omitempty"`
+	// The unknown global fields
+	Unknown map[string]string `json:"unknown,omitempty"`
+	// The inputs
+	Inputs []DecodedPsbtInput `json:"inputs"`
+	// The outputs
+	Outputs []DecodedPsbtOutput `json:"outputs"`
+	// The transaction fee paid if all UTXOs slots in the PSBT have been filled
+	Fee Amount `json:"fee,omitempty"`
+}
+
+// PSBT roles, as returned by "analyzepsbt" call
+const (
+	PSBT_ROLE_UPDATER   string = "updater"
+	PSBT_ROLE_SIGNER    string = "signer"
+	PSBT_ROLE_FINALIZER string = "finalizer"
+	PSBT_ROLE_EXTRACTOR string = "extractor"
+)
+
+// PsbtInputMissing represents the data missing to sign a PSBT input
+type PsbtInputMissing struct {
+	Pubkeys       []string `json:"pubkeys,omitempty"`
+	Signatures    []string `json:"signatures,omitempty"`
+	RedeemScript  string   `json:"redeemscript,omitempty"`
+	WitnessScript string   `json:"witnessscript,omitempty"`
+}
+
+// PsbtInputAnalysis represents the analysis of a PSBT input
+type PsbtInputAnalysis struct {
+	// Whether a UTXO is provided
+	HasUtxo bool `json:"has_utxo"`
+	// Whether the input is finalized
+	IsFinal bool `json:"is_final"`
+	// Things that are missing that are required to complete this input
+	Missing *PsbtInputMissing `json:"missing,omitempty"`
+	// Role of the next person that this input needs to go to
+	Next string `json:"next,omitempty"`
+}
+
+// PsbtAnalysis represents a response to "analyzepsbt" call
+type PsbtAnalysis struct {
+	Inputs []PsbtInputAnalysis `json:"inputs,omitempty"`
+	// Estimated vsize of the final signed transaction
+	EstimatedVsize uint64 `json:"estimated_vsize,omitempty"`
+	// Estimated feerate of the final signed transaction per kvB
+	EstimatedFeeRate Amount `json:"estimated_feerate,omitempty"`
+	// The transaction fee paid
+	Fee Amount `json:"fee,omitempty"`
+	// Role of the next person that this psbt needs to go to
+	Next string `json:"next"`
+	// Error message if there is one
+	Error string `json:"error,omitempty"`
+}
+
+// UtxoUpdatePsbtDescriptor represents a descriptor used by "utxoupdatepsbt"
+// call, with an optional derivation range
+type UtxoUpdatePsbtDescriptor struct {
+	Desc  string `json:"desc"`
+	Range []int  `json:"range,omitempty"`
+}
+
+// CreatePsbt creates a transaction in the Partially Signed Transaction
+// format, and returns it base64 encoded.
+func (b *Bitcoind) CreatePsbt(inputs []TxInput, outputs []TxOutput, lockTime uint32, replaceable bool) (psbt string, err error) {
+	if len(outputs) == 0 {
+		err = errors.New("Bad parameters for CreatePsbt: you must set at least one output")
+		return
+	}
+	if inputs == nil {
+		inputs = []TxInput{}
+	}
+	r, err := b.client.call("createpsbt", []interface{}{inputs, outputs, lockTime, replaceable})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &psbt)
+	return
+}
+
+// WalletCreateFundedPsbt creates and funds a transaction in the Partially
+// Signed Transaction format, using the wallet inputs if [inputs] are not
+// enough.
+// [options] are the same as FundRawTransaction ones and may be nil, [bip32Derivs] includes BIP 32 derivation paths for
+// public keys if known.
+func (b *Bitcoind) WalletCreateFundedPsbt(inputs []TxInput, outputs []TxOutput, lockTime uint32, options *FundRawTransactionOptions, bip32Derivs bool) (result WalletCreateFundedPsbtResult, err error) {
+	if len(outputs) == 0 {
+		err = errors.New("Bad parameters for WalletCreateFundedPsbt: you must set at least one output")
+		return
+	}
+	if inputs == nil {
+		inputs = []TxInput{}
+	}
+	if options == nil {
+		options = &FundRawTransactionOptions{}
+	}
+	r, err := b.client.call("walletcreatefundedpsbt", []interface{}{inputs, outputs, lockTime, options, bip32Derivs})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// WalletProcessPsbt updates a PSBT with input information from the wallet
+// and then signs inputs if [sign] is set.
+// [sigHashType] may be "" for the node default one.
+func (b *Bitcoind) WalletProcessPsbt(psbt string, sign bool, sigHashType string, bip32Derivs bool) (result PsbtResult, err error) {
+	r, err := b.client.call("walletprocesspsbt", []interface{}{psbt, sign, optionalString(sigHashType), bip32Derivs})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// FinalizePsbt finalizes the inputs of a PSBT. If the transaction is fully
+// signed and [extract] is set, the network serialized transaction is returned
+// in Hex, otherwise the updated PSBT is returned in Psbt.
+func (b *Bitcoind) FinalizePsbt(psbt string, extract bool) (result FinalizedPsbt, err error) {
+	r, err := b.client.call("finalizepsbt", []interface{}{psbt, extract})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// CombinePsbt combines multiple PSBTs of the same transaction into one
+func (b *Bitcoind) CombinePsbt(psbts []string) (psbt string, err error) {
+	if len(psbts) == 0 {
+		err = errors.New("Bad parameters for CombinePsbt: you must set at least one psbt")
+		return
+	}
+	r, err := b.client.call("combinepsbt", []interface{}{psbts})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &psbt)
+	return
+}
+
+// JoinPsbts joins multiple distinct PSBTs with different inputs and outputs
+// into one PSBT with inputs and outputs from all of them
+func (b *Bitcoind) JoinPsbts(psbts []string) (psbt string, err error) {
+	if len(psbts) < 2 {
+		err = errors.New("Bad parameters for JoinPsbts: you must set at least two psbts")
+		return
+	}
+	r, err := b.client.call("joinpsbts", []interface{}{psbts})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &psbt)
+	return
+}
+
+// UtxoUpdatePsbt updates all segwit inputs of a PSBT with data from output
+// descriptors, the UTXO set or the mempool.
+// [descriptors] may be nil.
+func (b *Bitcoind) UtxoUpdatePsbt(psbt string, descriptors []UtxoUpdatePsbtDescriptor) (updated string, err error) {
+	params := []interface{}{psbt}
+	if descriptors != nil {
+		params = append(params, descriptors)
+	}
+	r, err := b.client.call("utxoupdatepsbt", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &updated)
+	return
+}
+
+// DecodePsbt returns the decoded representation of a base64 encoded PSBT
+func (b *Bitcoind) DecodePsbt(psbt string) (decoded DecodedPsbt, err error) {
+	r, err := b.client.call("decodepsbt", []string{psbt})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &decoded)
+	return
+}
+
+// AnalyzePsbt analyzes a PSBT and provides information about its current
+// status and the next role expected to process it
+func (b *Bitcoind) AnalyzePsbt(psbt string) (analysis PsbtAnalysis, err error) {
+	r, err := b.client.call("analyzepsbt", []string{psbt})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &analysis)
+	return
+}
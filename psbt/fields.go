@@ -0,0 +1,453 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Global key types
+const (
+	PSBT_GLOBAL_UNSIGNED_TX       byte = 0x00
+	PSBT_GLOBAL_XPUB              byte = 0x01
+	PSBT_GLOBAL_TX_VERSION        byte = 0x02
+	PSBT_GLOBAL_FALLBACK_LOCKTIME byte = 0x03
+	PSBT_GLOBAL_INPUT_COUNT       byte = 0x04
+	PSBT_GLOBAL_OUTPUT_COUNT      byte = 0x05
+	PSBT_GLOBAL_TX_MODIFIABLE     byte = 0x06
+	PSBT_GLOBAL_VERSION           byte = 0xfb
+	PSBT_GLOBAL_PROPRIETARY       byte = 0xfc
+)
+
+// Input key types
+const (
+	PSBT_IN_NON_WITNESS_UTXO         byte = 0x00
+	PSBT_IN_WITNESS_UTXO             byte = 0x01
+	PSBT_IN_PARTIAL_SIG              byte = 0x02
+	PSBT_IN_SIGHASH_TYPE             byte = 0x03
+	PSBT_IN_REDEEM_SCRIPT            byte = 0x04
+	PSBT_IN_WITNESS_SCRIPT           byte = 0x05
+	PSBT_IN_BIP32_DERIVATION         byte = 0x06
+	PSBT_IN_FINAL_SCRIPTSIG          byte = 0x07
+	PSBT_IN_FINAL_SCRIPTWITNESS      byte = 0x08
+	PSBT_IN_POR_COMMITMENT           byte = 0x09
+	PSBT_IN_RIPEMD160                byte = 0x0a
+	PSBT_IN_SHA256                   byte = 0x0b
+	PSBT_IN_HASH160                  byte = 0x0c
+	PSBT_IN_HASH256                  byte = 0x0d
+	PSBT_IN_PREVIOUS_TXID            byte = 0x0e
+	PSBT_IN_OUTPUT_INDEX             byte = 0x0f
+	PSBT_IN_SEQUENCE                 byte = 0x10
+	PSBT_IN_REQUIRED_TIME_LOCKTIME   byte = 0x11
+	PSBT_IN_REQUIRED_HEIGHT_LOCKTIME byte = 0x12
+	PSBT_IN_TAP_KEY_SIG              byte = 0x13
+	PSBT_IN_TAP_SCRIPT_SIG           byte = 0x14
+	PSBT_IN_TAP_LEAF_SCRIPT          byte = 0x15
+	PSBT_IN_TAP_BIP32_DERIVATION     byte = 0x16
+	PSBT_IN_TAP_INTERNAL_KEY         byte = 0x17
+	PSBT_IN_TAP_MERKLE_ROOT          byte = 0x18
+	PSBT_IN_PROPRIETARY              byte = 0xfc
+)
+
+// Output key types
+const (
+	PSBT_OUT_REDEEM_SCRIPT        byte = 0x00
+	PSBT_OUT_WITNESS_SCRIPT       byte = 0x01
+	PSBT_OUT_BIP32_DERIVATION     byte = 0x02
+	PSBT_OUT_AMOUNT               byte = 0x03
+	PSBT_OUT_SCRIPT               byte = 0x04
+	PSBT_OUT_TAP_INTERNAL_KEY     byte = 0x05
+	PSBT_OUT_TAP_TREE             byte = 0x06
+	PSBT_OUT_TAP_BIP32_DERIVATION byte = 0x07
+	PSBT_OUT_PROPRIETARY          byte = 0xfc
+)
+
+// A fieldRule describes a known key type: the accepted key data lengths
+// (nil when the key data must be empty, -1 for any length) and the PSBT
+// versions it is allowed in
+type fieldRule struct {
+	keyDataLens []int
+	v0, v2      bool
+}
+
+var (
+	anyLen     = []int{-1}
+	pubKeyLens = []int{33, 65}
+	xOnlyLen   = []int{32}
+	both       = fieldRule{v0: true, v2: true}
+)
+
+// globalRules are the known global key types
+var globalRules = map[byte]fieldRule{
+	PSBT_GLOBAL_UNSIGNED_TX:       {v0: true},
+	PSBT_GLOBAL_XPUB:              {keyDataLens: []int{78}, v0: true, v2: true},
+	PSBT_GLOBAL_TX_VERSION:        {v2: true},
+	PSBT_GLOBAL_FALLBACK_LOCKTIME: {v2: true},
+	PSBT_GLOBAL_INPUT_COUNT:       {v2: true},
+	PSBT_GLOBAL_OUTPUT_COUNT:      {v2: true},
+	PSBT_GLOBAL_TX_MODIFIABLE:     {v2: true},
+	PSBT_GLOBAL_VERSION:           both,
+	PSBT_GLOBAL_PROPRIETARY:       {keyDataLens: anyLen, v0: true, v2: true},
+}
+
+// inputRules are the known input key types
+var inputRules = map[byte]fieldRule{
+	PSBT_IN_NON_WITNESS_UTXO:         both,
+	PSBT_IN_WITNESS_UTXO:             both,
+	PSBT_IN_PARTIAL_SIG:              {keyDataLens: pubKeyLens, v0: true, v2: true},
+	PSBT_IN_SIGHASH_TYPE:             both,
+	PSBT_IN_REDEEM_SCRIPT:            both,
+	PSBT_IN_WITNESS_SCRIPT:           both,
+	PSBT_IN_BIP32_DERIVATION:         {keyDataLens: pubKeyLens, v0: true, v2: true},
+	PSBT_IN_FINAL_SCRIPTSIG:          both,
+	PSBT_IN_FINAL_SCRIPTWITNESS:      both,
+	PSBT_IN_POR_COMMITMENT:           both,
+	PSBT_IN_RIPEMD160:                {keyDataLens: []int{20}, v0: true, v2: true},
+	PSBT_IN_SHA256:                   {keyDataLens: []int{32}, v0: true, v2: true},
+	PSBT_IN_HASH160:                  {keyDataLens: []int{20}, v0: true, v2: true},
+	PSBT_IN_HASH256:                  {keyDataLens: []int{32}, v0: true, v2: true},
+	PSBT_IN_PREVIOUS_TXID:            {v2: true},
+	PSBT_IN_OUTPUT_INDEX:             {v2: true},
+	PSBT_IN_SEQUENCE:                 {v2: true},
+	PSBT_IN_REQUIRED_TIME_LOCKTIME:   {v2: true},
+	PSBT_IN_REQUIRED_HEIGHT_LOCKTIME: {v2: true},
+	PSBT_IN_TAP_KEY_SIG:              both,
+	PSBT_IN_TAP_SCRIPT_SIG:           {keyDataLens: []int{64}, v0: true, v2: true},
+	PSBT_IN_TAP_LEAF_SCRIPT:          {keyDataLens: anyLen, v0: true, v2: true},
+	PSBT_IN_TAP_BIP32_DERIVATION:     {keyDataLens: xOnlyLen, v0: true, v2: true},
+	PSBT_IN_TAP_INTERNAL_KEY:         both,
+	PSBT_IN_TAP_MERKLE_ROOT:          both,
+	PSBT_IN_PROPRIETARY:              {keyDataLens: anyLen, v0: true, v2: true},
+}
+
+// outputRules are the known output key types
+var outputRules = map[byte]fieldRule{
+	PSBT_OUT_REDEEM_SCRIPT:        both,
+	PSBT_OUT_WITNESS_SCRIPT:       both,
+	PSBT_OUT_BIP32_DERIVATION:     {keyDataLens: pubKeyLens, v0: true, v2: true},
+	PSBT_OUT_AMOUNT:               {v2: true},
+	PSBT_OUT_SCRIPT:               {v2: true},
+	PSBT_OUT_TAP_INTERNAL_KEY:     both,
+	PSBT_OUT_TAP_TREE:             both,
+	PSBT_OUT_TAP_BIP32_DERIVATION: {keyDataLens: xOnlyLen, v0: true, v2: true},
+	PSBT_OUT_PROPRIETARY:          {keyDataLens: anyLen, v0: true, v2: true},
+}
+
+// fixedValueLens are the value lengths of fixed size fields, by map
+var (
+	globalValueLens = map[byte]int{
+		PSBT_GLOBAL_TX_VERSION:        4,
+		PSBT_GLOBAL_FALLBACK_LOCKTIME: 4,
+		PSBT_GLOBAL_TX_MODIFIABLE:     1,
+		PSBT_GLOBAL_VERSION:           4,
+	}
+	inputValueLens = map[byte]int{
+		PSBT_IN_SIGHASH_TYPE:             4,
+		PSBT_IN_PREVIOUS_TXID:            32,
+		PSBT_IN_OUTPUT_INDEX:             4,
+		PSBT_IN_SEQUENCE:                 4,
+		PSBT_IN_REQUIRED_TIME_LOCKTIME:   4,
+		PSBT_IN_REQUIRED_HEIGHT_LOCKTIME: 4,
+		PSBT_IN_TAP_INTERNAL_KEY:         32,
+		PSBT_IN_TAP_MERKLE_ROOT:          32,
+	}
+	outputValueLens = map[byte]int{
+		PSBT_OUT_AMOUNT:           8,
+		PSBT_OUT_TAP_INTERNAL_KEY: 32,
+	}
+)
+
+// A Bip32Derivation represents a BIP 32 derivation path of a public key, in
+// the same format as decodepsbt bip32_derivs entries
+type Bip32Derivation struct {
+	PubKey            string
+	MasterFingerprint string
+	Path              string
+}
+
+// An Input represents an input map of a PSBT
+type Input struct {
+	Map
+}
+
+// An Output represents an output map of a PSBT
+type Output struct {
+	Map
+}
+
+// PartialSignatures returns the partial signatures of the input, hex encoded
+// and indexed by hex public key as in decodepsbt output
+func (in Input) PartialSignatures() map[string]string {
+	sigs := make(map[string]string)
+	for _, kv := range in.GetAll(PSBT_IN_PARTIAL_SIG) {
+		sigs[hex.EncodeToString(kv.KeyData)] = hex.EncodeToString(kv.Value)
+	}
+	return sigs
+}
+
+// Bip32Derivations returns the BIP 32 derivation paths of the input public keys
+func (in Input) Bip32Derivations() ([]Bip32Derivation, error) {
+	return bip32Derivations(in.GetAll(PSBT_IN_BIP32_DERIVATION))
+}
+
+// NonWitnessUtxo returns the serialized transaction spent by the input
+func (in Input) NonWitnessUtxo() []byte {
+	value, _ := in.Get(PSBT_IN_NON_WITNESS_UTXO, nil)
+	return value
+}
+
+// WitnessUtxo returns the amount in satoshis and the scriptPubKey of the
+// output spent by a segwit input
+func (in Input) WitnessUtxo() (amount int64, scriptPubKey []byte, ok bool, err error) {
+	value, ok := in.Get(PSBT_IN_WITNESS_UTXO, nil)
+	if !ok {
+		return
+	}
+	amount, scriptPubKey, err = decodeTxOut(value)
+	return
+}
+
+// PreviousOutpoint returns the previous transaction id, in display order,
+// and output index spent by the input of a version 2 PSBT
+func (in Input) PreviousOutpoint() (txid string, vout uint32, ok bool) {
+	prevTxid, okTxid := in.Get(PSBT_IN_PREVIOUS_TXID, nil)
+	index, okIndex := in.Get(PSBT_IN_OUTPUT_INDEX, nil)
+	if !okTxid || !okIndex || len(prevTxid) != 32 || len(index) != 4 {
+		return "", 0, false
+	}
+	return hashToString(prevTxid), binary.LittleEndian.Uint32(index), true
+}
+
+// Unknown returns the pairs of the input with an unknown key type, hex
+// encoded as in decodepsbt output
+func (in Input) Unknown() map[string]string {
+	return unknown(in.Map, inputRules)
+}
+
+// Bip32Derivations returns the BIP 32 derivation paths of the output public keys
+func (out Output) Bip32Derivations() ([]Bip32Derivation, error) {
+	return bip32Derivations(out.GetAll(PSBT_OUT_BIP32_DERIVATION))
+}
+
+// Amount returns the amount in satoshis of the output of a version 2 PSBT
+func (out Output) Amount() (amount int64, ok bool) {
+	value, ok := out.Get(PSBT_OUT_AMOUNT, nil)
+	if !ok || len(value) != 8 {
+		return 0, false
+	}
+	return int64(binary.LittleEndian.Uint64(value)), true
+}
+
+// Script returns the scriptPubKey of the output of a version 2 PSBT
+func (out Output) Script() []byte {
+	value, _ := out.Get(PSBT_OUT_SCRIPT, nil)
+	return value
+}
+
+// Unknown returns the pairs of the output with an unknown key type, hex
+// encoded as in decodepsbt output
+func (out Output) Unknown() map[string]string {
+	return unknown(out.Map, outputRules)
+}
+
+// Unknown returns the pairs of the global map with an unknown key type, hex
+// encoded as in decodepsbt output
+func (p *Packet) Unknown() map[string]string {
+	return unknown(p.Global, globalRules)
+}
+
+// Validate checks the PSBT is well formed for its version: required fields
+// are present, known fields have valid key and value lengths and are allowed
+// in the version, and the non witness UTXOs match the outpoints spent.
+func (p *Packet) Validate() error {
+	if value, ok := p.Global.Get(PSBT_GLOBAL_VERSION, nil); ok && len(value) != 4 {
+		return errors.New("Global map: bad version length")
+	}
+	version := p.Version()
+	if version != 0 && version != 2 {
+		return fmt.Errorf("Unsupported PSBT version %d", version)
+	}
+	if err := checkMap(p.Global, globalRules, globalValueLens, version); err != nil {
+		return fmt.Errorf("Global map: %v", err)
+	}
+	inputs, outputs, err := p.counts()
+	if err != nil {
+		return err
+	}
+	if len(p.Inputs) != inputs || len(p.Outputs) != outputs {
+		return fmt.Errorf("PSBT has %d inputs and %d outputs, expecting %d and %d", len(p.Inputs), len(p.Outputs), inputs, outputs)
+	}
+
+	var prevouts []txIn
+	if version == 0 {
+		tx, _ := parseUnsignedTx(p.UnsignedTx())
+		for i, in := range tx.inputs {
+			if in.scriptSigLen != 0 {
+				return fmt.Errorf("Unsigned transaction input %d has a scriptSig", i)
+			}
+		}
+		prevouts = tx.inputs
+	} else {
+		if _, ok := p.Global.Get(PSBT_GLOBAL_TX_VERSION, nil); !ok {
+			return errors.New("Global map: missing transaction version in version 2 PSBT")
+		}
+	}
+
+	for i, in := range p.Inputs {
+		if err := checkMap(in.Map, inputRules, inputValueLens, version); err != nil {
+			return fmt.Errorf("Input %d: %v", i, err)
+		}
+		var prevout txIn
+		if version == 0 {
+			prevout = prevouts[i]
+		} else {
+			prevTxid, okTxid := in.Get(PSBT_IN_PREVIOUS_TXID, nil)
+			index, okIndex := in.Get(PSBT_IN_OUTPUT_INDEX, nil)
+			if !okTxid || !okIndex {
+				return fmt.Errorf("Input %d: missing previous outpoint in version 2 PSBT", i)
+			}
+			prevout = txIn{prevTxid: prevTxid, vout: binary.LittleEndian.Uint32(index)}
+		}
+		if utxo := in.NonWitnessUtxo(); utxo != nil {
+			tx, err := parseTx(utxo)
+			if err != nil {
+				return fmt.Errorf("Input %d: non witness utxo: %v", i, err)
+			}
+			if !bytes.Equal(tx.txid, prevout.prevTxid) {
+				return fmt.Errorf("Input %d: non witness utxo txid %s does not match the spent outpoint %s", i, hashToString(tx.txid), hashToString(prevout.prevTxid))
+			}
+			if int(prevout.vout) >= tx.outputs {
+				return fmt.Errorf("Input %d: non witness utxo has no output %d", i, prevout.vout)
+			}
+		}
+		if _, _, _, err := in.WitnessUtxo(); err != nil {
+			return fmt.Errorf("Input %d: witness utxo: %v", i, err)
+		}
+		if _, err := in.Bip32Derivations(); err != nil {
+			return fmt.Errorf("Input %d: %v", i, err)
+		}
+	}
+
+	for i, out := range p.Outputs {
+		if err := checkMap(out.Map, outputRules, outputValueLens, version); err != nil {
+			return fmt.Errorf("Output %d: %v", i, err)
+		}
+		if version == 2 {
+			if _, ok := out.Amount(); !ok {
+				return fmt.Errorf("Output %d: missing amount in version 2 PSBT", i)
+			}
+			if _, ok := out.Get(PSBT_OUT_SCRIPT, nil); !ok {
+				return fmt.Errorf("Output %d: missing script in version 2 PSBT", i)
+			}
+		}
+		if _, err := out.Bip32Derivations(); err != nil {
+			return fmt.Errorf("Output %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// checkMap checks the known fields of a map are allowed in version and have
+// valid key data and value lengths
+func checkMap(m Map, rules map[byte]fieldRule, valueLens map[byte]int, version uint32) error {
+	for _, kv := range m {
+		rule, known := rules[kv.KeyType]
+		if !known {
+			continue
+		}
+		if (version == 0 && !rule.v0) || (version == 2 && !rule.v2) {
+			return fmt.Errorf("key type 0x%02x is not allowed in version %d", kv.KeyType, version)
+		}
+		if !validKeyDataLen(rule.keyDataLens, len(kv.KeyData)) {
+			return fmt.Errorf("bad key data length %d for key type 0x%02x", len(kv.KeyData), kv.KeyType)
+		}
+		if expected, fixed := valueLens[kv.KeyType]; fixed && len(kv.Value) != expected {
+			return fmt.Errorf("bad value length %d for key type 0x%02x, expecting %d", len(kv.Value), kv.KeyType, expected)
+		}
+	}
+	return nil
+}
+
+// validKeyDataLen checks n is one of the accepted key data lengths
+func validKeyDataLen(lens []int, n int) bool {
+	if lens == nil {
+		return n == 0
+	}
+	for _, l := range lens {
+		if l == -1 || l == n {
+			return true
+		}
+	}
+	return false
+}
+
+// unknown returns the pairs of m with an unknown key type, hex encoded
+func unknown(m Map, rules map[byte]fieldRule) map[string]string {
+	pairs := make(map[string]string)
+	for _, kv := range m {
+		if _, known := rules[kv.KeyType]; !known {
+			pairs[hex.EncodeToString(kv.Key())] = hex.EncodeToString(kv.Value)
+		}
+	}
+	return pairs
+}
+
+// bip32Derivations decodes BIP 32 derivation pairs
+func bip32Derivations(pairs []KeyValue) ([]Bip32Derivation, error) {
+	var derivs []Bip32Derivation
+	for _, kv := range pairs {
+		if len(kv.Value) < 4 || len(kv.Value)%4 != 0 {
+			return nil, fmt.Errorf("bad BIP 32 derivation length %d", len(kv.Value))
+		}
+		path := []string{"m"}
+		for i := 4; i < len(kv.Value); i += 4 {
+			index := binary.LittleEndian.Uint32(kv.Value[i : i+4])
+			if index >= 0x80000000 {
+				path = append(path, strconv.FormatUint(uint64(index-0x80000000), 10)+"'")
+			} else {
+				path = append(path, strconv.FormatUint(uint64(index), 10))
+			}
+		}
+		derivs = append(derivs, Bip32Derivation{
+			PubKey:            hex.EncodeToString(kv.KeyData),
+			MasterFingerprint: hex.EncodeToString(kv.Value[:4]),
+			Path:              strings.Join(path, "/"),
+		})
+	}
+	return derivs, nil
+}
+
+// decodeTxOut decodes a serialized transaction output
+func decodeTxOut(data []byte) (amount int64, scriptPubKey []byte, err error) {
+	r := bytes.NewReader(data)
+	value, err := readBytes(r, 8)
+	if err != nil {
+		return
+	}
+	scriptLen, err := readCompactSize(r)
+	if err != nil {
+		return
+	}
+	if scriptPubKey, err = readBytes(r, scriptLen); err != nil {
+		return
+	}
+	if r.Len() != 0 {
+		err = errors.New("trailing bytes after output")
+		return
+	}
+	amount = int64(binary.LittleEndian.Uint64(value))
+	return
+}
+
+// hashToString returns the hex representation of an internal byte order hash
+func hashToString(hash []byte) string {
+	reversed := make([]byte, len(hash))
+	for i, b := range hash {
+		reversed[len(hash)-1-i] = b
+	}
+	return hex.EncodeToString(reversed)
+}
@@ -0,0 +1,396 @@
+// Package psbt is a pure Go encoder and decoder of Partially Signed Bitcoin
+// Transactions as defined in BIP 174 (version 0) and BIP 370 (version 2).
+//
+// A Packet keeps every key-value pair of its global, input and output maps,
+// known or not, so that parsing and serializing a PSBT round-trips
+// byte-for-byte.
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// magic is the prefix of every serialized PSBT
+var magic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// maxMapEntries bounds the number of pairs decoded in a single map, as a
+// protection against malformed inputs
+const maxMapEntries = 10000
+
+// A KeyValue represents a key-value pair of a PSBT map. The serialized key is
+// KeyType followed by KeyData.
+type KeyValue struct {
+	KeyType byte
+	KeyData []byte
+	Value   []byte
+}
+
+// Key returns the serialized key of the pair
+func (kv KeyValue) Key() []byte {
+	return append([]byte{kv.KeyType}, kv.KeyData...)
+}
+
+// A Map represents an ordered PSBT map
+type Map []KeyValue
+
+// Get returns the value of the pair having the given key type and data
+func (m Map) Get(keyType byte, keyData []byte) (value []byte, ok bool) {
+	for _, kv := range m {
+		if kv.KeyType == keyType && bytes.Equal(kv.KeyData, keyData) {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// GetAll returns all the pairs having the given key type
+func (m Map) GetAll(keyType byte) []KeyValue {
+	var pairs []KeyValue
+	for _, kv := range m {
+		if kv.KeyType == keyType {
+			pairs = append(pairs, kv)
+		}
+	}
+	return pairs
+}
+
+// Set adds the pair to the map, replacing the value of an existing pair with
+// the same key
+func (m *Map) Set(keyType byte, keyData, value []byte) {
+	for i, kv := range *m {
+		if kv.KeyType == keyType && bytes.Equal(kv.KeyData, keyData) {
+			(*m)[i].Value = value
+			return
+		}
+	}
+	*m = append(*m, KeyValue{KeyType: keyType, KeyData: keyData, Value: value})
+}
+
+// A Packet represents a PSBT
+type Packet struct {
+	Global  Map
+	Inputs  []Input
+	Outputs []Output
+}
+
+// Parse decodes a binary serialized PSBT
+func Parse(data []byte) (*Packet, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return nil, errors.New("Bad PSBT magic bytes")
+	}
+	r := bytes.NewReader(data[len(magic):])
+	p := &Packet{}
+	var err error
+	if p.Global, err = readMap(r); err != nil {
+		return nil, fmt.Errorf("Global map: %v", err)
+	}
+	inputs, outputs, err := p.counts()
+	if err != nil {
+		return nil, err
+	}
+	p.Inputs = make([]Input, inputs)
+	for i := range p.Inputs {
+		if p.Inputs[i].Map, err = readMap(r); err != nil {
+			return nil, fmt.Errorf("Input %d: %v", i, err)
+		}
+	}
+	p.Outputs = make([]Output, outputs)
+	for i := range p.Outputs {
+		if p.Outputs[i].Map, err = readMap(r); err != nil {
+			return nil, fmt.Errorf("Output %d: %v", i, err)
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after PSBT", r.Len())
+	}
+	return p, nil
+}
+
+// ParseBase64 decodes a base64 encoded PSBT, as used by bitcoind RPCs
+func ParseBase64(str string) (*Packet, error) {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// NewV0 returns a version 0 PSBT for the given unsigned transaction, with
+// empty input and output maps
+func NewV0(unsignedTx []byte) (*Packet, error) {
+	tx, err := parseUnsignedTx(unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+	p := &Packet{
+		Global:  Map{{KeyType: PSBT_GLOBAL_UNSIGNED_TX, Value: unsignedTx}},
+		Inputs:  make([]Input, len(tx.inputs)),
+		Outputs: make([]Output, tx.outputs),
+	}
+	return p, p.Validate()
+}
+
+// Serialize returns the binary serialization of the PSBT
+func (p *Packet) Serialize() []byte {
+	var buf bytes.Buffer
+	buf.Write(magic)
+	writeMap(&buf, p.Global)
+	for _, input := range p.Inputs {
+		writeMap(&buf, input.Map)
+	}
+	for _, output := range p.Outputs {
+		writeMap(&buf, output.Map)
+	}
+	return buf.Bytes()
+}
+
+// Base64 returns the base64 encoded serialization of the PSBT
+func (p *Packet) Base64() string {
+	return base64.StdEncoding.EncodeToString(p.Serialize())
+}
+
+// Version returns the PSBT version, 0 when the version field is absent
+func (p *Packet) Version() uint32 {
+	value, ok := p.Global.Get(PSBT_GLOBAL_VERSION, nil)
+	if !ok || len(value) != 4 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(value)
+}
+
+// UnsignedTx returns the serialized unsigned transaction of a version 0 PSBT
+func (p *Packet) UnsignedTx() []byte {
+	value, _ := p.Global.Get(PSBT_GLOBAL_UNSIGNED_TX, nil)
+	return value
+}
+
+// counts returns the number of inputs and outputs of the PSBT, from the
+// unsigned transaction in version 0 and from the count fields in version 2
+func (p *Packet) counts() (inputs, outputs int, err error) {
+	switch version := p.Version(); version {
+	case 0:
+		unsignedTx, ok := p.Global.Get(PSBT_GLOBAL_UNSIGNED_TX, nil)
+		if !ok {
+			return 0, 0, errors.New("Missing unsigned transaction in version 0 PSBT")
+		}
+		tx, err := parseUnsignedTx(unsignedTx)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Unsigned transaction: %v", err)
+		}
+		return len(tx.inputs), tx.outputs, nil
+	case 2:
+		in, err := p.compactField(PSBT_GLOBAL_INPUT_COUNT)
+		if err != nil {
+			return 0, 0, err
+		}
+		out, err := p.compactField(PSBT_GLOBAL_OUTPUT_COUNT)
+		if err != nil {
+			return 0, 0, err
+		}
+		return int(in), int(out), nil
+	default:
+		return 0, 0, fmt.Errorf("Unsupported PSBT version %d", version)
+	}
+}
+
+// compactField returns the compact size value of a required global field
+func (p *Packet) compactField(keyType byte) (uint64, error) {
+	value, ok := p.Global.Get(keyType, nil)
+	if !ok {
+		return 0, fmt.Errorf("Missing global field 0x%02x in version 2 PSBT", keyType)
+	}
+	r := bytes.NewReader(value)
+	n, err := readCompactSize(r)
+	if err != nil || r.Len() != 0 {
+		return 0, fmt.Errorf("Bad compact size in global field 0x%02x", keyType)
+	}
+	if n > maxMapEntries {
+		return 0, fmt.Errorf("Too many entries in global field 0x%02x: %d", keyType, n)
+	}
+	return n, nil
+}
+
+// readMap reads a map up to its 0x00 separator
+func readMap(r *bytes.Reader) (Map, error) {
+	m := Map{}
+	seen := make(map[string]bool)
+	for {
+		keyLen, err := readCompactSize(r)
+		if err != nil {
+			return nil, err
+		}
+		if keyLen == 0 {
+			return m, nil
+		}
+		if len(m) >= maxMapEntries {
+			return nil, errors.New("Too many entries in map")
+		}
+		key, err := readBytes(r, keyLen)
+		if err != nil {
+			return nil, err
+		}
+		valueLen, err := readCompactSize(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readBytes(r, valueLen)
+		if err != nil {
+			return nil, err
+		}
+		if seen[string(key)] {
+			return nil, fmt.Errorf("Duplicate key %s", hex.EncodeToString(key))
+		}
+		seen[string(key)] = true
+		m = append(m, KeyValue{KeyType: key[0], KeyData: key[1:], Value: value})
+	}
+}
+
+// writeMap writes a map followed by its 0x00 separator
+func writeMap(w *bytes.Buffer, m Map) {
+	for _, kv := range m {
+		writeCompactSize(w, uint64(1+len(kv.KeyData)))
+		w.WriteByte(kv.KeyType)
+		w.Write(kv.KeyData)
+		writeCompactSize(w, uint64(len(kv.Value)))
+		w.Write(kv.Value)
+	}
+	w.WriteByte(0x00)
+}
+
+// readBytes reads n bytes, checking first enough bytes remain
+func readBytes(r *bytes.Reader, n uint64) ([]byte, error) {
+	if n > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, n)
+	_, err := io.ReadFull(r, data)
+	return data, err
+}
+
+// readCompactSize reads a Bitcoin variable length integer
+func readCompactSize(r io.ByteReader) (uint64, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, io.ErrUnexpectedEOF
+	}
+	var size int
+	switch first {
+	case 0xfd:
+		size = 2
+	case 0xfe:
+		size = 4
+	case 0xff:
+		size = 8
+	default:
+		return uint64(first), nil
+	}
+	var n uint64
+	for i := 0; i < size; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+		n |= uint64(b) << (8 * uint(i))
+	}
+	if (size == 2 && n < 0xfd) || (size == 4 && n <= 0xffff) || (size == 8 && n <= 0xffffffff) {
+		return 0, errors.New("Non canonical compact size")
+	}
+	return n, nil
+}
+
+// writeCompactSize writes a Bitcoin variable length integer
+func writeCompactSize(w *bytes.Buffer, n uint64) {
+	switch {
+	case n < 0xfd:
+		w.WriteByte(byte(n))
+	case n <= 0xffff:
+		w.WriteByte(0xfd)
+		binary.Write(w, binary.LittleEndian, uint16(n))
+	case n <= 0xffffffff:
+		w.WriteByte(0xfe)
+		binary.Write(w, binary.LittleEndian, uint32(n))
+	default:
+		w.WriteByte(0xff)
+		binary.Write(w, binary.LittleEndian, n)
+	}
+}
+
+// Combine merges the pairs of PSBTs describing the same transaction, as the
+// "combinepsbt" RPC does. When several packets have a pair with the same key,
+// the value of the first one is kept.
+func Combine(packets ...*Packet) (*Packet, error) {
+	if len(packets) == 0 {
+		return nil, errors.New("Bad parameters for Combine: you must set at least one PSBT")
+	}
+	first := packets[0]
+	combined := &Packet{
+		Global:  merge(nil, first.Global),
+		Inputs:  make([]Input, len(first.Inputs)),
+		Outputs: make([]Output, len(first.Outputs)),
+	}
+	for i, in := range first.Inputs {
+		combined.Inputs[i].Map = merge(nil, in.Map)
+	}
+	for i, out := range first.Outputs {
+		combined.Outputs[i].Map = merge(nil, out.Map)
+	}
+	for n, p := range packets[1:] {
+		if !sameTx(first, p) {
+			return nil, fmt.Errorf("PSBT %d does not describe the same transaction", n+1)
+		}
+		combined.Global = merge(combined.Global, p.Global)
+		for i, in := range p.Inputs {
+			combined.Inputs[i].Map = merge(combined.Inputs[i].Map, in.Map)
+		}
+		for i, out := range p.Outputs {
+			combined.Outputs[i].Map = merge(combined.Outputs[i].Map, out.Map)
+		}
+	}
+	return combined, combined.Validate()
+}
+
+// merge adds to dst the pairs of src whose key is not already in dst
+func merge(dst, src Map) Map {
+	for _, kv := range src {
+		if _, ok := dst.Get(kv.KeyType, kv.KeyData); !ok {
+			dst = append(dst, kv)
+		}
+	}
+	return dst
+}
+
+// sameTx checks two PSBTs describe the same transaction: same unsigned
+// transaction in version 0, same outpoints and outputs in version 2
+func sameTx(a, b *Packet) bool {
+	if a.Version() != b.Version() || len(a.Inputs) != len(b.Inputs) || len(a.Outputs) != len(b.Outputs) {
+		return false
+	}
+	if a.Version() == 0 {
+		return bytes.Equal(a.UnsignedTx(), b.UnsignedTx())
+	}
+	for i := range a.Inputs {
+		for _, keyType := range []byte{PSBT_IN_PREVIOUS_TXID, PSBT_IN_OUTPUT_INDEX} {
+			va, _ := a.Inputs[i].Get(keyType, nil)
+			vb, _ := b.Inputs[i].Get(keyType, nil)
+			if !bytes.Equal(va, vb) {
+				return false
+			}
+		}
+	}
+	for i := range a.Outputs {
+		for _, keyType := range []byte{PSBT_OUT_AMOUNT, PSBT_OUT_SCRIPT} {
+			va, _ := a.Outputs[i].Get(keyType, nil)
+			vb, _ := b.Outputs[i].Get(keyType, nil)
+			if !bytes.Equal(va, vb) {
+				return false
+			}
+		}
+	}
+	return true
+}
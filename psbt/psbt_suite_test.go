@@ -0,0 +1,13 @@
+package psbt
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPsbt(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Psbt Suite")
+}
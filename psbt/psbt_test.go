@@ -0,0 +1,206 @@
+package psbt
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// bip174Vector is the BIP 174 "one P2PKH input" valid test vector, a version
+// 0 PSBT with a non witness UTXO
+const bip174Vector = "cHNidP8BAHUCAAAAASaBcTce3/KF6Tet7qSze3gADAVmy7OtZGQXE8pCFxv2AAAAAAD+////AtPf9QUAAAAAGXapFNDFmQPFusKGh2DpD9UhpGZap2UgiKwA4fUFAAAAABepFDVF5uM7gyxHBQ8k0+65PJwDlIvHh7MuEwAAAQD9pQEBAAAAAAECiaPHHqtNIOA3G7ukzGmPopXJRjr6Ljl/hTPMti+VZ+UBAAAAFxYAFL4Y0VKpsBIDna89p95PUzSe7LmF/////4b4qkOnHf8USIk6UwpyN+9rRgi7st0tAXHmOuxqSJC0AQAAABcWABT+Pp7xp0XpdNkCxDVZQ6vLNL1TU/////8CAMLrCwAAAAAZdqkUhc/xCX/Z4Ai7NK9wnGIZeziXikiIrHL++E4sAAAAF6kUM5cluiHv1irHU6m80GfWx6ajnQWHAkcwRAIgJxK+IuAnDzlPVoMR3HyppolwuAJf3TskAinwf4pfOiQCIAGLONfc0xTnNMkna9b7QPZzMlvEuqFEyADS8vAtsnZcASED0uFWdJQbrUqZY3LLh+GFbTZSYG2YVi/jnF6efkE/IQUCSDBFAiEA0SuFLYXc2WHS9fSrZgZU327tzHlMDDPOXMMJ/7X85Y0CIGczio4OFyXBl/saiK9Z9R5E5CVbIBZ8hoQDHAXR8lkqASECI7cr7vCWXRC+B3jv7NYfysb3mk6haTkzgHNEZPhPKrMAAAAAAAAA"
+
+// unsignedTxHex is the unsigned transaction of bip174Vector
+const unsignedTxHex = "0200000001268171371edff285e937adeea4b37b78000c0566cbb3ad64641713ca42171bf60000000000feffffff02d3dff505000000001976a914d0c59903c5bac2868760e90fd521a4665aa7652088ac00e1f5050000000017a9143545e6e33b832c47050f24d3eeb93c9c03948bc787b32e1300"
+
+func mustDecodeHex(str string) []byte {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func le32(n uint32) []byte {
+	return []byte{byte(n), byte(n >> 8), byte(n >> 16), byte(n >> 24)}
+}
+
+// newV2 returns a version 2 PSBT with one input and one output
+func newV2() *Packet {
+	return &Packet{
+		Global: Map{
+			{KeyType: PSBT_GLOBAL_TX_VERSION, Value: le32(2)},
+			{KeyType: PSBT_GLOBAL_INPUT_COUNT, Value: []byte{1}},
+			{KeyType: PSBT_GLOBAL_OUTPUT_COUNT, Value: []byte{1}},
+			{KeyType: PSBT_GLOBAL_VERSION, Value: le32(2)},
+		},
+		Inputs: []Input{{Map{
+			{KeyType: PSBT_IN_PREVIOUS_TXID, Value: mustDecodeHex("268171371edff285e937adeea4b37b78000c0566cbb3ad64641713ca42171bf6")},
+			{KeyType: PSBT_IN_OUTPUT_INDEX, Value: le32(0)},
+		}}},
+		Outputs: []Output{{Map{
+			{KeyType: PSBT_OUT_AMOUNT, Value: []byte{0x00, 0xe1, 0xf5, 0x05, 0, 0, 0, 0}},
+			{KeyType: PSBT_OUT_SCRIPT, Value: mustDecodeHex("a9143545e6e33b832c47050f24d3eeb93c9c03948bc787")},
+		}}},
+	}
+}
+
+var _ = Describe("Psbt", func() {
+	Describe("Parsing a BIP 174 test vector", func() {
+		p, err := ParseBase64(bip174Vector)
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should be a version 0 PSBT", func() {
+			Expect(p.Version()).To(Equal(uint32(0)))
+			Expect(hex.EncodeToString(p.UnsignedTx())).To(Equal(unsignedTxHex))
+		})
+		It("should have one input and two outputs", func() {
+			Expect(p.Inputs).To(HaveLen(1))
+			Expect(p.Outputs).To(HaveLen(2))
+		})
+		It("should validate", func() {
+			Expect(p.Validate()).To(Succeed())
+		})
+		It("should serialize back to the same bytes", func() {
+			Expect(p.Base64()).To(Equal(bip174Vector))
+		})
+		It("should return the non witness utxo", func() {
+			tx, err := parseTx(p.Inputs[0].NonWitnessUtxo())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hashToString(tx.txid)).To(Equal("f61b1742ca13176464adb3cb66050c00787bb3a4eead37e985f2df1e37718126"))
+		})
+		It("should have no unknown pairs", func() {
+			Expect(p.Unknown()).To(BeEmpty())
+			Expect(p.Inputs[0].Unknown()).To(BeEmpty())
+		})
+	})
+
+	Describe("Unknown and BIP 32 pairs", func() {
+		p, _ := NewV0(mustDecodeHex(unsignedTxHex))
+		pubKey := mustDecodeHex("029583bf39ae0a609747ad199addd634fa6108559d6c5cd39b4c2183f1ab96e07f")
+		p.Inputs[0].Set(0x42, []byte{0x01, 0x02}, []byte{0xab})
+		p.Outputs[0].Set(PSBT_OUT_BIP32_DERIVATION, pubKey, mustDecodeHex("d90c6a4f"+"00000080"+"01000000"))
+		parsed, err := Parse(p.Serialize())
+		It("should round-trip", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.Serialize()).To(Equal(p.Serialize()))
+		})
+		It("should report unknown pairs as decodepsbt", func() {
+			Expect(parsed.Inputs[0].Unknown()).To(Equal(map[string]string{"420102": "ab"}))
+		})
+		It("should decode BIP 32 derivations", func() {
+			derivs, err := parsed.Outputs[0].Bip32Derivations()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(derivs).To(Equal([]Bip32Derivation{{
+				PubKey:            hex.EncodeToString(pubKey),
+				MasterFingerprint: "d90c6a4f",
+				Path:              "m/0'/1",
+			}}))
+		})
+	})
+
+	Describe("Version 2 PSBT", func() {
+		p := newV2()
+		It("should validate", func() {
+			Expect(p.Validate()).To(Succeed())
+		})
+		It("should round-trip", func() {
+			parsed, err := Parse(p.Serialize())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed.Serialize()).To(Equal(p.Serialize()))
+		})
+		It("should return the previous outpoint and output fields", func() {
+			txid, vout, ok := p.Inputs[0].PreviousOutpoint()
+			Expect(ok).To(BeTrue())
+			Expect(txid).To(Equal("f61b1742ca13176464adb3cb66050c00787bb3a4eead37e985f2df1e37718126"))
+			Expect(vout).To(Equal(uint32(0)))
+			amount, ok := p.Outputs[0].Amount()
+			Expect(ok).To(BeTrue())
+			Expect(amount).To(Equal(int64(100000000)))
+		})
+		Context("when an output misses its amount", func() {
+			p := newV2()
+			p.Outputs[0].Map = p.Outputs[0].Map[1:]
+			It("should not validate", func() {
+				Expect(p.Validate()).To(MatchError("Output 0: missing amount in version 2 PSBT"))
+			})
+		})
+		Context("when it has an unsigned transaction", func() {
+			p := newV2()
+			p.Global.Set(PSBT_GLOBAL_UNSIGNED_TX, nil, mustDecodeHex(unsignedTxHex))
+			It("should not validate", func() {
+				Expect(p.Validate()).To(MatchError("Global map: key type 0x00 is not allowed in version 2"))
+			})
+		})
+	})
+
+	Describe("Combine", func() {
+		a, _ := NewV0(mustDecodeHex(unsignedTxHex))
+		b, _ := NewV0(mustDecodeHex(unsignedTxHex))
+		pubKey := mustDecodeHex("029583bf39ae0a609747ad199addd634fa6108559d6c5cd39b4c2183f1ab96e07f")
+		a.Inputs[0].Set(PSBT_IN_SIGHASH_TYPE, nil, le32(1))
+		b.Inputs[0].Set(PSBT_IN_SIGHASH_TYPE, nil, le32(2))
+		b.Inputs[0].Set(PSBT_IN_PARTIAL_SIG, pubKey, []byte{0x30, 0x01})
+		combined, err := Combine(a, b)
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should merge the pairs, keeping the first value", func() {
+			value, _ := combined.Inputs[0].Get(PSBT_IN_SIGHASH_TYPE, nil)
+			Expect(value).To(Equal(le32(1)))
+			Expect(combined.Inputs[0].PartialSignatures()).To(Equal(map[string]string{hex.EncodeToString(pubKey): "3001"}))
+		})
+		It("should not modify its arguments", func() {
+			Expect(a.Inputs[0].Map).To(HaveLen(1))
+		})
+		Context("when transactions differ", func() {
+			_, err := Combine(a, newV2())
+			It("should error", func() {
+				Expect(err).To(MatchError("PSBT 1 does not describe the same transaction"))
+			})
+		})
+	})
+
+	Describe("Invalid PSBTs", func() {
+		valid, _ := base64.StdEncoding.DecodeString(bip174Vector)
+		Context("when magic bytes are wrong", func() {
+			_, err := Parse(append([]byte("psbu\xff"), valid[5:]...))
+			It("should error", func() {
+				Expect(err).To(MatchError("Bad PSBT magic bytes"))
+			})
+		})
+		Context("when a key is duplicated", func() {
+			data := append([]byte{}, valid[:5]...)
+			data = append(data, 0x01, 0xfb, 0x04, 0, 0, 0, 0, 0x01, 0xfb, 0x04, 0, 0, 0, 0, 0x00)
+			_, err := Parse(data)
+			It("should error", func() {
+				Expect(err).To(MatchError("Global map: Duplicate key fb"))
+			})
+		})
+		Context("when the unsigned transaction has a scriptSig", func() {
+			tx := mustDecodeHex(unsignedTxHex)
+			tx = append(append(append([]byte{}, tx[:41]...), 0x01, 0x51), tx[42:]...)
+			_, err := NewV0(tx)
+			It("should error", func() {
+				Expect(err).To(MatchError("Unsigned transaction input 0 has a scriptSig"))
+			})
+		})
+		Context("when data is truncated", func() {
+			_, err := Parse(valid[:len(valid)-1])
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("when the version is not supported", func() {
+			p := newV2()
+			p.Global.Set(PSBT_GLOBAL_VERSION, nil, le32(1))
+			_, err := Parse(p.Serialize())
+			It("should error", func() {
+				Expect(err).To(MatchError("Unsupported PSBT version 1"))
+			})
+		})
+	})
+})
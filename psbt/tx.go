@@ -0,0 +1,144 @@
+package psbt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// maxTxEntries bounds the number of inputs, outputs and witness items decoded
+// in a transaction, as a protection against malformed inputs
+const maxTxEntries = 100000
+
+// txIn represents the parts of a transaction input a PSBT needs
+type txIn struct {
+	// The previous transaction id, in internal byte order
+	prevTxid []byte
+	// The previous output index
+	vout uint32
+	// The size of the scriptSig
+	scriptSigLen uint64
+}
+
+// tx represents the parts of a transaction a PSBT needs
+type tx struct {
+	inputs     []txIn
+	outputs    int
+	hasWitness bool
+	// The transaction id, in internal byte order
+	txid []byte
+}
+
+// parseTx decodes a serialized transaction, with or without witness data
+func parseTx(data []byte) (*tx, error) {
+	return decodeTx(data, true)
+}
+
+// parseUnsignedTx decodes a serialized transaction without witness data
+func parseUnsignedTx(data []byte) (*tx, error) {
+	return decodeTx(data, false)
+}
+
+// decodeTx decodes a serialized transaction, witness data is only accepted if
+// allowWitness is set
+func decodeTx(data []byte, allowWitness bool) (*tx, error) {
+	r := bytes.NewReader(data)
+	t := &tx{}
+	if _, err := readBytes(r, 4); err != nil {
+		return nil, err
+	}
+	bodyStart := len(data) - r.Len()
+	if allowWitness && r.Len() >= 2 && data[bodyStart] == 0x00 && data[bodyStart+1] == 0x01 {
+		t.hasWitness = true
+		r.Seek(2, 1)
+		bodyStart += 2
+	}
+	inputs, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if inputs > maxTxEntries {
+		return nil, fmt.Errorf("Too many inputs: %d", inputs)
+	}
+	for i := uint64(0); i < inputs; i++ {
+		prevTxid, err := readBytes(r, 32)
+		if err != nil {
+			return nil, err
+		}
+		vout, err := readBytes(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		scriptSigLen, err := readCompactSize(r)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = readBytes(r, scriptSigLen); err != nil {
+			return nil, err
+		}
+		if _, err = readBytes(r, 4); err != nil {
+			return nil, err
+		}
+		t.inputs = append(t.inputs, txIn{prevTxid: prevTxid, vout: binary.LittleEndian.Uint32(vout), scriptSigLen: scriptSigLen})
+	}
+	outputs, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if outputs > maxTxEntries {
+		return nil, fmt.Errorf("Too many outputs: %d", outputs)
+	}
+	for i := uint64(0); i < outputs; i++ {
+		if _, err = readBytes(r, 8); err != nil {
+			return nil, err
+		}
+		scriptLen, err := readCompactSize(r)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = readBytes(r, scriptLen); err != nil {
+			return nil, err
+		}
+	}
+	t.outputs = int(outputs)
+	bodyEnd := len(data) - r.Len()
+	if t.hasWitness {
+		for range t.inputs {
+			items, err := readCompactSize(r)
+			if err != nil {
+				return nil, err
+			}
+			if items > maxTxEntries {
+				return nil, fmt.Errorf("Too many witness items: %d", items)
+			}
+			for j := uint64(0); j < items; j++ {
+				itemLen, err := readCompactSize(r)
+				if err != nil {
+					return nil, err
+				}
+				if _, err = readBytes(r, itemLen); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	lockTime, err := readBytes(r, 4)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("Trailing bytes after transaction")
+	}
+
+	// The txid is computed on the serialization without witness
+	var stripped bytes.Buffer
+	stripped.Write(data[:4])
+	stripped.Write(data[bodyStart:bodyEnd])
+	stripped.Write(lockTime)
+	first := sha256.Sum256(stripped.Bytes())
+	second := sha256.Sum256(first[:])
+	t.txid = second[:]
+	return t, nil
+}
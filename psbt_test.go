@@ -0,0 +1,289 @@
+package bitcoind
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Psbt", func() {
+	Describe("Testing CreatePsbt", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":"cHNidP8BAFICAAAAAdGiw9Tl9gcYKTpLXG1+j5ChssPU5fYHGCk6S1xtfo+QAQAAAAD9////AQDh9QUAAAAAFgAUeh7h4eWwvUwNTA5ta9DostA9C2oAAAAAAAAA","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			psbt, err := bitcoindClient.CreatePsbt(
+				[]TxInput{{Txid: "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", Vout: 1}},
+				[]TxOutput{{Address: "bc1q0g0wrc09kz75crfvpekkh58gktgrmzm2qpsl5w", Amount: 1}},
+				0, true)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send inputs and outputs", func() {
+				Expect(params).To(Equal([]interface{}{
+					[]interface{}{map[string]interface{}{"txid": "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", "vout": float64(1)}},
+					[]interface{}{map[string]interface{}{"bc1q0g0wrc09kz75crfvpekkh58gktgrmzm2qpsl5w": float64(1)}},
+					float64(0),
+					true,
+				}))
+			})
+			It("should return the psbt", func() {
+				Expect(psbt).To(HavePrefix("cHNidP8B"))
+			})
+		})
+		Context("when no output is set", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.CreatePsbt(nil, nil, 0, false)
+			It("error should occured", func() {
+				Expect(err).To(MatchError("Bad parameters for CreatePsbt: you must set at least one output"))
+			})
+		})
+	})
+
+	Describe("Testing WalletCreateFundedPsbt", func() {
+		Context("when success without options", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"psbt":"cHNidP8BAHECAAAAAQ==","fee":0.00000141,"changepos":-1},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.WalletCreateFundedPsbt(nil, []TxOutput{{Address: "bc1q0g0wrc09kz75crfvpekkh58gktgrmzm2qpsl5w", Amount: 0.5}}, 0, nil, true)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send empty inputs and options", func() {
+				Expect(params).To(Equal([]interface{}{
+					[]interface{}{},
+					[]interface{}{map[string]interface{}{"bc1q0g0wrc09kz75crfvpekkh58gktgrmzm2qpsl5w": 0.5}},
+					float64(0),
+					map[string]interface{}{},
+					true,
+				}))
+			})
+			It("should return", func() {
+				Expect(result).To(Equal(WalletCreateFundedPsbtResult{Psbt: "cHNidP8BAHECAAAAAQ==", Fee: 141, ChangePos: -1}))
+			})
+		})
+		Context("when no output is set", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.WalletCreateFundedPsbt(nil, []TxOutput{}, 0, nil, false)
+			It("error should occured", func() {
+				Expect(err).To(MatchError("Bad parameters for WalletCreateFundedPsbt: you must set at least one output"))
+			})
+		})
+	})
+
+	Describe("Testing WalletProcessPsbt", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"psbt":"cHNidP8BAHECAAAAAQ==","complete":true,"hex":"02000000000101"},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.WalletProcessPsbt("cHNidP8BAHECAAAAAQ==", true, "", false)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should leave the sighash type to the node default", func() {
+				Expect(params).To(Equal([]interface{}{"cHNidP8BAHECAAAAAQ==", true, nil, false}))
+			})
+			It("should return", func() {
+				Expect(result).To(Equal(PsbtResult{Psbt: "cHNidP8BAHECAAAAAQ==", Complete: true, Hex: "02000000000101"}))
+			})
+		})
+		Context("when a sighash type is set", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"psbt":"cHNidP8BAHECAAAAAQ==","complete":false},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			_, err = bitcoindClient.WalletProcessPsbt("cHNidP8BAHECAAAAAQ==", true, "ALL|ANYONECANPAY", true)
+			It("should send the sighash type", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(params).To(Equal([]interface{}{"cHNidP8BAHECAAAAAQ==", true, "ALL|ANYONECANPAY", true}))
+			})
+		})
+	})
+
+	Describe("Testing FinalizePsbt", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"hex":"02000000000101","complete":true},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.FinalizePsbt("cHNidP8BAHECAAAAAQ==", true)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return the extracted transaction", func() {
+				Expect(result).To(Equal(FinalizedPsbt{Hex: "02000000000101", Complete: true}))
+			})
+		})
+	})
+
+	Describe("Testing CombinePsbt", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":"cHNidP8BAHECAAAAAg==","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			psbt, err := bitcoindClient.CombinePsbt([]string{"cHNidP8BAHECAAAAAQ==", "cHNidP8BAHECAAAAAw=="})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the psbts as an array", func() {
+				Expect(params).To(Equal([]interface{}{[]interface{}{"cHNidP8BAHECAAAAAQ==", "cHNidP8BAHECAAAAAw=="}}))
+			})
+			It("should return the combined psbt", func() {
+				Expect(psbt).To(Equal("cHNidP8BAHECAAAAAg=="))
+			})
+		})
+	})
+
+	Describe("Testing JoinPsbts", func() {
+		Context("when only one psbt is given", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.JoinPsbts([]string{"cHNidP8BAHECAAAAAQ=="})
+			It("error should occured", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Testing UtxoUpdatePsbt", func() {
+		Context("when success with descriptors", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":"cHNidP8BAHECAAAAAw==","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			psbt, err := bitcoindClient.UtxoUpdatePsbt("cHNidP8BAHECAAAAAQ==", []UtxoUpdatePsbtDescriptor{{Desc: "wpkh(tpubD6NzVbkrYhZ4XgiXtGrdW5XDAPFCL9h7we1vwNCpn8tGbBcgfVYjXyhWo4E1xkh56hjod1RhGjxbaTLV3X4FyWuejifB9jusQ46QzG87VKp/0/*)", Range: []int{0, 100}}})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the descriptors", func() {
+				Expect(params).To(Equal([]interface{}{
+					"cHNidP8BAHECAAAAAQ==",
+					[]interface{}{map[string]interface{}{"desc": "wpkh(tpubD6NzVbkrYhZ4XgiXtGrdW5XDAPFCL9h7we1vwNCpn8tGbBcgfVYjXyhWo4E1xkh56hjod1RhGjxbaTLV3X4FyWuejifB9jusQ46QzG87VKp/0/*)", "range": []interface{}{float64(0), float64(100)}}},
+				}))
+			})
+			It("should return the updated psbt", func() {
+				Expect(psbt).To(Equal("cHNidP8BAHECAAAAAw=="))
+			})
+		})
+	})
+
+	Describe("Testing DecodePsbt", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"tx":{"txid":"82efd652d7ab1197f01a5f4d9a30cb4c68bb79ab6fec58dfa1bf112291d1617b","hash":"82efd652d7ab1197f01a5f4d9a30cb4c68bb79ab6fec58dfa1bf112291d1617b","version":2,"size":82,"vsize":82,"weight":328,"locktime":0,"vin":[{"txid":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","vout":1,"scriptSig":{"asm":"","hex":""},"sequence":4294967293}],"vout":[{"value":0.99990000,"n":0,"scriptPubKey":{"asm":"0 7a1ee1e1e5b0bd4c0d4c0e6d6bd0e8b2d03d0b6a","hex":"00147a1ee1e1e5b0bd4c0d4c0e6d6bd0e8b2d03d0b6a","address":"bc1q0g0wrc09kz75crfvpekkh58gktgrmzm2qpsl5w","type":"witness_v0_keyhash"}}]},"global_xpubs":[],"psbt_version":0,"proprietary":[],"unknown":{},"inputs":[{"witness_utxo":{"amount":1.00000000,"scriptPubKey":{"asm":"0 e5344f52ecc92c279028a851c9d8ed57bb5dfc60","desc":"addr(bc1qu56y75hveykz0ypg4pgunk8d2ld4mlrqp4xw7s)#f7mstyuc","hex":"0014e5344f52ecc92c279028a851c9d8ed57bb5dfc60","address":"bc1qu56y75hveykz0ypg4pgunk8d2ld4mlrqp4xw7s","type":"witness_v0_keyhash"}},"partial_signatures":{"03bfde0ae35e6aabc875f937ea708934583aa33f47b5653b12acae191a4ad5ff5e":"3044022032771ee953e3c7809f57179dfb8d58eb4e47fb8721441174325a3e3008c11c8102200b436200ab9d006aab87c8595749da9c03e4490179401de9b5c281cfa5e658c401"},"bip32_derivs":[{"pubkey":"03bfde0ae35e6aabc875f937ea708934583aa33f47b5653b12acae191a4ad5ff5e","master_fingerprint":"d34db33f","path":"m/84'/0'/0'/0/1"}]}],"outputs":[{"bip32_derivs":[{"pubkey":"02e2c2ac3b1a6b8a0d9fa5dbd57fba2e0d4f8c36ad8bb63c1b2bb5d0b2a3d8c3f1","master_fingerprint":"d34db33f","path":"m/84'/0'/0'/1/0"}]}],"fee":0.00010000},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			decoded, err := bitcoindClient.DecodePsbt("cHNidP8BAFICAAAAAQ==")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should decode the unsigned transaction", func() {
+				Expect(decoded.Tx.Txid).To(Equal("82efd652d7ab1197f01a5f4d9a30cb4c68bb79ab6fec58dfa1bf112291d1617b"))
+				Expect(decoded.Tx.Vout[0].ScriptPubKey.Address).To(Equal("bc1q0g0wrc09kz75crfvpekkh58gktgrmzm2qpsl5w"))
+			})
+			It("should decode the inputs", func() {
+				Expect(decoded.Inputs).To(HaveLen(1))
+				Expect(decoded.Inputs[0].WitnessUtxo.Amount).To(Equal(Amount(100000000)))
+				Expect(decoded.Inputs[0].PartialSignatures).To(HaveKey("03bfde0ae35e6aabc875f937ea708934583aa33f47b5653b12acae191a4ad5ff5e"))
+				Expect(decoded.Inputs[0].Bip32Derivs).To(Equal([]PsbtBip32Deriv{{
+					PubKey:            "03bfde0ae35e6aabc875f937ea708934583aa33f47b5653b12acae191a4ad5ff5e",
+					MasterFingerprint: "d34db33f",
+					Path:              "m/84'/0'/0'/0/1",
+				}}))
+			})
+			It("should decode the outputs and the fee", func() {
+				Expect(decoded.Outputs[0].Bip32Derivs[0].Path).To(Equal("m/84'/0'/0'/1/0"))
+				Expect(decoded.Fee).To(Equal(Amount(10000)))
+			})
+		})
+	})
+
+	Describe("Testing AnalyzePsbt", func() {
+		Context("when signatures are missing", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"inputs":[{"has_utxo":true,"is_final":false,"missing":{"signatures":["e5344f52ecc92c279028a851c9d8ed57bb5dfc60"]},"next":"signer"}],"estimated_vsize":110,"estimated_feerate":0.00090909,"fee":0.00010000,"next":"signer"},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			analysis, err := bitcoindClient.AnalyzePsbt("cHNidP8BAFICAAAAAQ==")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return", func() {
+				Expect(analysis).To(Equal(PsbtAnalysis{
+					Inputs: []PsbtInputAnalysis{{
+						HasUtxo: true,
+						Missing: &PsbtInputMissing{Signatures: []string{"e5344f52ecc92c279028a851c9d8ed57bb5dfc60"}},
+						Next:    PSBT_ROLE_SIGNER,
+					}},
+					EstimatedVsize:   110,
+					EstimatedFeeRate: 90909,
+					Fee:              10000,
+					Next:             PSBT_ROLE_SIGNER,
+				}))
+			})
+		})
+	})
+})
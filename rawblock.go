@@ -0,0 +1,227 @@
+package bitcoind
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// witnessCommitmentHeader is the start of the coinbase output script holding
+// the BIP 141 witness commitment
+var witnessCommitmentHeader = []byte{0x6a, 0x24, 0xaa, 0x21, 0xa9, 0xed}
+
+// A RawBlock represents a block decoded from its serialized form, as returned
+// by GetRawBlock
+type RawBlock struct {
+	// The block header
+	Header RawBlockHeader
+
+	// The block hash
+	Hash string
+
+	// The block size
+	Size uint64
+
+	// The block size excluding witness data
+	StrippedSize uint64
+
+	// The block weight as defined in BIP 141
+	Weight uint64
+
+	// The transactions of the block, the coinbase first
+	Tx []RawTransaction
+}
+
+// ParseBlock decodes an hex encoded serialized block, as returned by
+// GetRawBlock
+func ParseBlock(rawHex string) (block RawBlock, err error) {
+	data, err := hex.DecodeString(rawHex)
+	if err != nil {
+		return
+	}
+	return DecodeBlock(data)
+}
+
+// DecodeBlock decodes a serialized block into its header and transactions.
+// The block is not validated, see Validate.
+func DecodeBlock(data []byte) (block RawBlock, err error) {
+	if len(data) < BlockHeaderSize {
+		err = fmt.Errorf("Bad block size: %d bytes", len(data))
+		return
+	}
+	if block.Header, err = DecodeBlockHeader(data[:BlockHeaderSize]); err != nil {
+		return
+	}
+	if block.Hash, err = block.Header.Hash(); err != nil {
+		return
+	}
+	r := &txReader{data: data, pos: BlockHeaderSize}
+	n, err := r.readCompactSize()
+	if err != nil {
+		return
+	}
+	strippedSize := r.pos
+	block.Tx = make([]RawTransaction, 0, minCount(n, r))
+	for i := uint64(0); i < n; i++ {
+		var tx RawTransaction
+		if tx, err = readTransaction(r); err != nil {
+			err = fmt.Errorf("Bad transaction %d: %v", i, err)
+			return
+		}
+		strippedSize += int((tx.Weight - tx.Size) / (witnessScaleFactor - 1))
+		block.Tx = append(block.Tx, tx)
+	}
+	if r.pos != len(data) {
+		err = fmt.Errorf("Bad block: %d trailing bytes", len(data)-r.pos)
+		return
+	}
+	block.Size = uint64(len(data))
+	block.StrippedSize = uint64(strippedSize)
+	block.Weight = block.StrippedSize*(witnessScaleFactor-1) + block.Size
+	return
+}
+
+// GetRawBlockDecoded returns the block with the given hash, decoded locally
+// from its serialized form
+func (b *Bitcoind) GetRawBlockDecoded(blockHash string) (block RawBlock, err error) {
+	rawHex, err := b.GetRawBlock(blockHash)
+	if err != nil {
+		return
+	}
+	return ParseBlock(rawHex)
+}
+
+// Validate checks the merkle root of the header and the witness commitment
+// of the coinbase match the transactions of the block
+func (b RawBlock) Validate() error {
+	if err := b.CheckMerkleRoot(); err != nil {
+		return err
+	}
+	return b.CheckWitnessCommitment()
+}
+
+// MerkleRoot computes the merkle root of the transactions of the block
+func (b RawBlock) MerkleRoot() (string, error) {
+	root, _, err := b.merkleRoot(false)
+	return root, err
+}
+
+// CheckMerkleRoot checks the merkle root of the header matches the
+// transactions, and that the transaction list is not mutated by duplicating
+// transactions (CVE-2012-2459)
+func (b RawBlock) CheckMerkleRoot() error {
+	root, mutated, err := b.merkleRoot(false)
+	if err != nil {
+		return err
+	}
+	if root != b.Header.Merkleroot {
+		return fmt.Errorf("Block %s: merkle root %s does not match transactions root %s", b.Hash, b.Header.Merkleroot, root)
+	}
+	if mutated {
+		return fmt.Errorf("Block %s: duplicate transactions", b.Hash)
+	}
+	return nil
+}
+
+// CheckWitnessCommitment checks the BIP 141 witness commitment of the
+// coinbase matches the witness data of the transactions. Blocks without
+// commitment must not have witness data.
+func (b RawBlock) CheckWitnessCommitment() error {
+	if len(b.Tx) == 0 {
+		return fmt.Errorf("Block %s: no coinbase", b.Hash)
+	}
+	coinbase := b.Tx[0]
+	commitment, err := witnessCommitment(coinbase)
+	if err != nil {
+		return fmt.Errorf("Block %s: %v", b.Hash, err)
+	}
+	if commitment == nil {
+		for _, tx := range b.Tx {
+			if hasWitness(tx) {
+				return fmt.Errorf("Block %s: unexpected witness data in transaction %s", b.Hash, tx.Txid)
+			}
+		}
+		return nil
+	}
+	if len(coinbase.Vin) != 1 || len(coinbase.Vin[0].Txinwitness) != 1 || len(coinbase.Vin[0].Txinwitness[0]) != 64 {
+		return fmt.Errorf("Block %s: bad witness reserved value size", b.Hash)
+	}
+	reserved, err := hex.DecodeString(coinbase.Vin[0].Txinwitness[0])
+	if err != nil {
+		return fmt.Errorf("Block %s: %v", b.Hash, err)
+	}
+	root, _, err := b.merkleRoot(true)
+	if err != nil {
+		return err
+	}
+	rootHash, _ := hashFromString(root)
+	if expected := doubleSha256(append(rootHash, reserved...)); !bytes.Equal(commitment, expected) {
+		return fmt.Errorf("Block %s: witness commitment %x does not match %x", b.Hash, commitment, expected)
+	}
+	return nil
+}
+
+// witnessCommitment returns the witness commitment of the last coinbase
+// output holding one, nil if there is none
+func witnessCommitment(coinbase RawTransaction) ([]byte, error) {
+	var commitment []byte
+	for _, out := range coinbase.Vout {
+		script, err := hex.DecodeString(out.ScriptPubKey.Hex)
+		if err != nil {
+			return nil, err
+		}
+		if len(script) >= 38 && bytes.HasPrefix(script, witnessCommitmentHeader) {
+			commitment = script[6:38]
+		}
+	}
+	return commitment, nil
+}
+
+// merkleRoot computes the merkle root of the txids or, for the witness
+// root, of the wtxids of the transactions, the coinbase wtxid being 0. It
+// also tells whether the tree is mutated by duplicate hashes.
+func (b RawBlock) merkleRoot(witness bool) (root string, mutated bool, err error) {
+	if len(b.Tx) == 0 {
+		return "", false, errors.New("Block has no transaction")
+	}
+	hashes := make([][]byte, len(b.Tx))
+	for i, tx := range b.Tx {
+		id := tx.Txid
+		if witness {
+			id = tx.Hash
+		}
+		if witness && i == 0 {
+			hashes[i] = make([]byte, 32)
+			continue
+		}
+		if hashes[i], err = hashFromString(id); err != nil {
+			return
+		}
+	}
+	rootHash, mutated := computeMerkleRoot(hashes)
+	return hashToString(rootHash), mutated, nil
+}
+
+// computeMerkleRoot returns the merkle root of hashes, in internal byte
+// order, as bitcoind computes it: the last hash of a level with an odd number
+// of hashes is paired with itself. mutated tells whether two identical hashes
+// were paired, which means the same root matches another list of hashes.
+func computeMerkleRoot(hashes [][]byte) (root []byte, mutated bool) {
+	level := hashes
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+				if bytes.Equal(level[i], right) {
+					mutated = true
+				}
+			}
+			next = append(next, doubleSha256(append(append([]byte{}, level[i]...), right...)))
+		}
+		level = next
+	}
+	return level[0], mutated
+}
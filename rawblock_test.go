@@ -0,0 +1,170 @@
+package bitcoind
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// genesisBlockHex is the serialized genesis block
+const genesisBlockHex = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c01" + genesisCoinbaseHex
+
+// sha256d returns the double sha256 of the concatenation of hex strings
+func sha256d(parts ...string) []byte {
+	var data []byte
+	for _, part := range parts {
+		b, err := hex.DecodeString(part)
+		if err != nil {
+			panic(err)
+		}
+		data = append(data, b...)
+	}
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+// reverseHex returns the byte reversed hex encoding of data
+func reverseHex(data []byte) string {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+	return hex.EncodeToString(reversed)
+}
+
+// segwitBlock returns a block holding a coinbase committing to the witness
+// of segwitTxHex with [commitment], or the right commitment if empty
+func segwitBlock(commitment string) string {
+	segwitTx, err := ParseTransaction(segwitTxHex)
+	if err != nil {
+		panic(err)
+	}
+	reserved := "0000000000000000000000000000000000000000000000000000000000000000"
+	if commitment == "" {
+		witnessRoot := sha256d(reserved, hex.EncodeToString(sha256d(segwitTxHex)))
+		commitment = hex.EncodeToString(sha256d(hex.EncodeToString(witnessRoot), reserved))
+	}
+	coinbase := RawTransaction{
+		Version: 1,
+		Vin:     []Vin{{Coinbase: "03a08601", Sequence: 0xffffffff, Txinwitness: []string{reserved}}},
+		Vout: []Vout{
+			{Value: 625000000, ScriptPubKey: ScriptPubKey{Hex: "0014751e76e8199196d454941c45d1b3a323f1433bd6"}},
+			{Value: 0, ScriptPubKey: ScriptPubKey{Hex: "6a24aa21a9ed" + commitment}},
+		},
+	}
+	coinbaseData, err := coinbase.Serialize()
+	if err != nil {
+		panic(err)
+	}
+	coinbaseTx, err := DecodeTransaction(coinbaseData)
+	if err != nil {
+		panic(err)
+	}
+	root := sha256d(internalHex(coinbaseTx.Txid), internalHex(segwitTx.Txid))
+	header := "00000020" + "0000000000000000000000000000000000000000000000000000000000000000" + hex.EncodeToString(root) + "29ab5f49ffff7f2000000000"
+	return header + "02" + coinbaseTx.Hex + segwitTxHex
+}
+
+// internalHex returns the internal byte order hex of a displayed hash
+func internalHex(hash string) string {
+	data, err := hex.DecodeString(hash)
+	if err != nil {
+		panic(err)
+	}
+	return reverseHex(data)
+}
+
+var _ = Describe("RawBlock", func() {
+	Describe("Testing ParseBlock", func() {
+		Context("when the block is the genesis block", func() {
+			block, err := ParseBlock(genesisBlockHex)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should decode the header", func() {
+				Expect(block.Hash).To(Equal("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"))
+				Expect(block.Header.Merkleroot).To(Equal("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"))
+				Expect(block.Header.Nonce).To(Equal(uint32(2083236893)))
+			})
+			It("should decode the transactions", func() {
+				Expect(block.Tx).To(HaveLen(1))
+				Expect(block.Tx[0].Txid).To(Equal("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"))
+			})
+			It("should compute the sizes", func() {
+				Expect(block.Size).To(Equal(uint64(285)))
+				Expect(block.StrippedSize).To(Equal(uint64(285)))
+				Expect(block.Weight).To(Equal(uint64(1140)))
+			})
+			It("should be valid", func() {
+				Expect(block.Validate()).To(Succeed())
+				Expect(block.MerkleRoot()).To(Equal(block.Header.Merkleroot))
+			})
+		})
+
+		Context("when the block has witness data", func() {
+			block, err := ParseBlock(segwitBlock(""))
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should compute the sizes", func() {
+				Expect(block.Tx).To(HaveLen(2))
+				Expect(block.Size).To(BeNumerically(">", block.StrippedSize))
+				Expect(block.Weight).To(Equal(block.StrippedSize*3 + block.Size))
+			})
+			It("should be valid", func() {
+				Expect(block.Validate()).To(Succeed())
+			})
+		})
+
+		Context("when the block is invalid", func() {
+			It("should error", func() {
+				for _, rawHex := range []string{
+					"",
+					genesisBlockHex[:160],
+					genesisBlockHex[:len(genesisBlockHex)-2],
+					genesisBlockHex + "00",
+				} {
+					_, err := ParseBlock(rawHex)
+					Expect(err).To(HaveOccurred(), rawHex)
+				}
+			})
+		})
+	})
+
+	Describe("Testing Validate", func() {
+		It("should report a bad merkle root", func() {
+			block, err := ParseBlock(genesisBlockHex)
+			Expect(err).NotTo(HaveOccurred())
+			block.Header.Merkleroot = "0000000000000000000000000000000000000000000000000000000000000000"
+			Expect(block.Validate()).To(MatchError(ContainSubstring("does not match transactions root")))
+		})
+		It("should report duplicate transactions", func() {
+			block, err := ParseBlock(segwitBlock(""))
+			Expect(err).NotTo(HaveOccurred())
+			// Duplicating the last transaction of a level with an odd
+			// number of transactions keeps the merkle root
+			coinbase, tx := block.Tx[0], block.Tx[1]
+			block.Tx = []RawTransaction{coinbase, tx, tx}
+			root, err := block.MerkleRoot()
+			Expect(err).NotTo(HaveOccurred())
+			block.Tx = append(block.Tx, tx)
+			block.Header.Merkleroot = root
+			Expect(block.CheckMerkleRoot()).To(MatchError(ContainSubstring("duplicate transactions")))
+		})
+		It("should report a bad witness commitment", func() {
+			block, err := ParseBlock(segwitBlock("1111111111111111111111111111111111111111111111111111111111111111"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(block.CheckMerkleRoot()).To(Succeed())
+			Expect(block.Validate()).To(MatchError(ContainSubstring("witness commitment")))
+		})
+		It("should report witness data without commitment", func() {
+			block, err := ParseBlock(segwitBlock(""))
+			Expect(err).NotTo(HaveOccurred())
+			block.Tx[0].Vout = block.Tx[0].Vout[:1]
+			Expect(block.CheckWitnessCommitment()).To(MatchError(ContainSubstring("unexpected witness data")))
+		})
+	})
+})
@@ -0,0 +1,319 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// TxInput represents an input of a transaction to create
+type TxInput struct {
+	// The transaction id
+	Txid string `json:"txid"`
+	// The output number
+	Vout uint32 `json:"vout"`
+	// The sequence number, nil to use the default one
+	Sequence *uint32 `json:"sequence,omitempty"`
+}
+
+// TxOutput represents an output of a transaction to create, either a payment
+// of Amount, or AmountSat, to Address or, when Data is set, a nulldata output
+type TxOutput struct {
+	// The destination address
+	Address string
+	// The amount in BTC
+	Amount float64
+	// The exact amount, used instead of Amount when not 0
+	AmountSat Amount
+	// Hex encoded data for an OP_RETURN output
+	Data string
+}
+
+// MarshalJSON encodes the output as expected by bitcoind,
+// {"address": amount} or {"data": "hex"}
+func (o TxOutput) MarshalJSON() ([]byte, error) {
+	if o.Data != "" {
+		return json.Marshal(map[string]string{"data": o.Data})
+	}
+	if o.AmountSat != 0 {
+		return json.Marshal(map[string]Amount{o.Address: o.AmountSat})
+	}
+	return json.Marshal(map[string]float64{o.Address: o.Amount})
+}
+
+// PrevTx represents a previous output spent by a transaction to sign, for
+// outputs the node does not know about
+type PrevTx struct {
+	Txid          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	ScriptPubKey  string  `json:"scriptPubKey"`
+	RedeemScript  string  `json:"redeemScript,omitempty"`
+	WitnessScript string  `json:"witnessScript,omitempty"`
+	Amount        float64 `json:"amount,omitempty"`
+	// The exact amount, used instead of Amount when not 0
+	AmountSat Amount `json:"-"`
+}
+
+// MarshalJSON encodes the previous output, with AmountSat as its amount when
+// not 0
+func (p PrevTx) MarshalJSON() ([]byte, error) {
+	type prevTx PrevTx
+	if p.AmountSat == 0 {
+		return json.Marshal(prevTx(p))
+	}
+	return json.Marshal(struct {
+		prevTx
+		Amount Amount `json:"amount"`
+	}{prevTx(p), p.AmountSat})
+}
+
+// DecodedScriptSegwit represents the P2WSH/P2WPKH form of a decoded script
+type DecodedScriptSegwit struct {
+	Asm        string `json:"asm"`
+	Hex        string `json:"hex"`
+	Type       string `json:"type"`
+	Address    string `json:"address,omitempty"`
+	Desc       string `json:"desc,omitempty"`
+	P2SHSegwit string `json:"p2sh-segwit,omitempty"`
+}
+
+// DecodedScript represents a response to "decodescript" call
+type DecodedScript struct {
+	Asm     string               `json:"asm"`
+	Desc    string               `json:"desc,omitempty"`
+	Type    string               `json:"type"`
+	Address string               `json:"address,omitempty"`
+	P2SH    string               `json:"p2sh,omitempty"`
+	Segwit  *DecodedScriptSegwit `json:"segwit,omitempty"`
+}
+
+// FundRawTransactionOptions represents the options of "fundrawtransaction"
+// call, nil fields are left to the node defaults
+type FundRawTransactionOptions struct {
+	AddInputs              *bool    `json:"add_inputs,omitempty"`
+	IncludeUnsafe          *bool    `json:"include_unsafe,omitempty"`
+	ChangeAddress          string   `json:"changeAddress,omitempty"`
+	ChangePosition         *int     `json:"changePosition,omitempty"`
+	ChangeType             string   `json:"change_type,omitempty"`
+	IncludeWatching        *bool    `json:"includeWatching,omitempty"`
+	LockUnspents           *bool    `json:"lockUnspents,omitempty"`
+	FeeRate                *float64 `json:"fee_rate,omitempty"`
+	FeeRateBTC             *float64 `json:"feeRate,omitempty"`
+	SubtractFeeFromOutputs []int    `json:"subtractFeeFromOutputs,omitempty"`
+	Replaceable            *bool    `json:"replaceable,omitempty"`
+	ConfTarget             *int     `json:"conf_target,omitempty"`
+	EstimateMode           string   `json:"estimate_mode,omitempty"`
+}
+
+// FundRawTransactionResult represents a response to "fundrawtransaction" call
+type FundRawTransactionResult struct {
+	// The resulting raw transaction (hex-encoded string)
+	Hex string `json:"hex"`
+	// Fee the resulting transaction pays
+	Fee Amount `json:"fee"`
+	// The position of the added change output, or -1
+	ChangePos int `json:"changepos"`
+}
+
+// SignRawTransactionError represents an error on an input of a signed
+// transaction
+type SignRawTransactionError struct {
+	Txid      string   `json:"txid"`
+	Vout      int      `json:"vout"`
+	Witness   []string `json:"witness"`
+	ScriptSig string   `json:"scriptSig"`
+	Sequence  uint32   `json:"sequence"`
+	Error     string   `json:"error"`
+}
+
+// SignRawTransactionResult represents a response to
+// "signrawtransactionwithwallet" and "signrawtransactionwithkey" calls
+type SignRawTransactionResult struct {
+	// The hex-encoded raw transaction with signature(s)
+	Hex string `json:"hex"`
+	// If the transaction has a complete set of signatures
+	Complete bool `json:"complete"`
+	// Script verification errors (if there are any)
+	Errors []SignRawTransactionError `json:"errors,omitempty"`
+}
+
+// MempoolAcceptFees represents the fees of a transaction tested with
+// testmempoolaccept
+type MempoolAcceptFees struct {
+	// Transaction fee
+	Base Amount `json:"base"`
+	// The effective feerate per kvB
+	EffectiveFeeRate Amount `json:"effective-feerate,omitempty"`
+	// The wtxids of the transactions whose fees and vsizes are included in
+	// effective-feerate
+	EffectiveIncludes []string `json:"effective-includes,omitempty"`
+}
+
+// MempoolAcceptResult represents the result of "testmempoolaccept" call for a
+// transaction
+type MempoolAcceptResult struct {
+	// The transaction hash in hex
+	Txid string `json:"txid"`
+	// The transaction witness hash in hex
+	Wtxid string `json:"wtxid"`
+	// Package validation error, if any
+	PackageError string `json:"package-error,omitempty"`
+	// Whether this tx would be accepted to the mempool and pass client-specified maxfeerate
+	Allowed bool `json:"allowed"`
+	// Virtual transaction size as defined in BIP 141
+	Vsize uint64 `json:"vsize,omitempty"`
+	// Transaction fees (only present if allowed is true)
+	Fees *MempoolAcceptFees `json:"fees,omitempty"`
+	// Rejection string (only present when allowed is false)
+	RejectReason string `json:"reject-reason,omitempty"`
+}
+
+// CreateRawTransaction creates a transaction spending the given inputs and
+// creating new outputs, and returns it hex encoded.
+// The transaction is neither signed nor stored in the wallet or transmitted
+// to the network.
+func (b *Bitcoind) CreateRawTransaction(inputs []TxInput, outputs []TxOutput, lockTime uint32, replaceable bool) (hexTx string, err error) {
+	if len(outputs) == 0 {
+		err = errors.New("Bad parameters for CreateRawTransaction: you must set at least one output")
+		return
+	}
+	if inputs == nil {
+		inputs = []TxInput{}
+	}
+	r, err := b.client.call("createrawtransaction", []interface{}{inputs, outputs, lockTime, replaceable})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &hexTx)
+	return
+}
+
+// DecodeRawTransaction returns the decoded representation of an hex encoded
+// transaction
+func (b *Bitcoind) DecodeRawTransaction(hexTx string) (rawTx RawTransaction, err error) {
+	r, err := b.client.call("decoderawtransaction", []string{hexTx})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &rawTx)
+	return
+}
+
+// DecodeScript returns the decoded representation of an hex encoded script
+func (b *Bitcoind) DecodeScript(hexScript string) (script DecodedScript, err error) {
+	r, err := b.client.call("decodescript", []string{hexScript})
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &script)
+	return
+}
+
+// FundRawTransaction adds inputs from the wallet to a transaction until it
+// pays its outputs and fee, and adds a change output if needed.
+// [options] may be nil.
+func (b *Bitcoind) FundRawTransaction(hexTx string, options *FundRawTransactionOptions) (result FundRawTransactionResult, err error) {
+	params := []interface{}{hexTx}
+	if options != nil {
+		params = append(params, options)
+	}
+	r, err := b.client.call("fundrawtransaction", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// SignRawTransactionWithWallet signs the inputs of a transaction with the
+// keys of the wallet.
+// [prevTxs] describes outputs the node does not know about and may be nil,
+// [sigHashType] may be "" for the default one.
+func (b *Bitcoind) SignRawTransactionWithWallet(hexTx string, prevTxs []PrevTx, sigHashType string) (result SignRawTransactionResult, err error) {
+	params := []interface{}{hexTx}
+	if prevTxs != nil || sigHashType != "" {
+		params = append(params, prevTxsParam(prevTxs))
+	}
+	if sigHashType != "" {
+		params = append(params, sigHashType)
+	}
+	r, err := b.client.call("signrawtransactionwithwallet", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// SignRawTransactionWithKey signs the inputs of a transaction with the given
+// base58-encoded private keys.
+// [prevTxs] describes outputs the node does not know about and may be nil,
+// [sigHashType] may be "" for the default one.
+func (b *Bitcoind) SignRawTransactionWithKey(hexTx string, privKeys []string, prevTxs []PrevTx, sigHashType string) (result SignRawTransactionResult, err error) {
+	if len(privKeys) == 0 {
+		err = errors.New("Bad parameters for SignRawTransactionWithKey: you must set at least one private key")
+		return
+	}
+	params := []interface{}{hexTx, privKeys}
+	if prevTxs != nil || sigHashType != "" {
+		params = append(params, prevTxsParam(prevTxs))
+	}
+	if sigHashType != "" {
+		params = append(params, sigHashType)
+	}
+	r, err := b.client.call("signrawtransactionwithkey", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// SendRawTransaction submits a hex encoded transaction to the local node and
+// network, and returns its txid.
+// [maxFeeRate] (0 or 1 value) is the maximum fee rate in BTC/kvB accepted,
+// 0 means no limit. The node default is used if it is not set.
+func (b *Bitcoind) SendRawTransaction(hexTx string, maxFeeRate ...float64) (txID string, err error) {
+	if len(maxFeeRate) > 1 {
+		err = errors.New("Bad parameters for SendRawTransaction: you can set 0 or 1 maxFeeRate")
+		return
+	}
+	params := []interface{}{hexTx}
+	if len(maxFeeRate) == 1 {
+		params = append(params, maxFeeRate[0])
+	}
+	r, err := b.client.call("sendrawtransaction", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &txID)
+	return
+}
+
+// TestMempoolAccept returns the results of mempool acceptance tests of hex
+// encoded transactions, without submitting them.
+// [maxFeeRate] (0 or 1 value) is the maximum fee rate in BTC/kvB accepted,
+// 0 means no limit. The node default is used if it is not set.
+func (b *Bitcoind) TestMempoolAccept(hexTxs []string, maxFeeRate ...float64) (results []MempoolAcceptResult, err error) {
+	if len(maxFeeRate) > 1 {
+		err = errors.New("Bad parameters for TestMempoolAccept: you can set 0 or 1 maxFeeRate")
+		return
+	}
+	params := []interface{}{hexTxs}
+	if len(maxFeeRate) == 1 {
+		params = append(params, maxFeeRate[0])
+	}
+	r, err := b.client.call("testmempoolaccept", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &results)
+	return
+}
+
+// prevTxsParam returns prevTxs as a non null JSON array
+func prevTxsParam(prevTxs []PrevTx) []PrevTx {
+	if prevTxs == nil {
+		return []PrevTx{}
+	}
+	return prevTxs
+}
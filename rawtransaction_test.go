@@ -0,0 +1,274 @@
+package bitcoind
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RawTransaction", func() {
+	Describe("Testing CreateRawTransaction", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":"0200000001d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f900100000000fdffffff0200e1f505000000001600147a1ee1e1e5b0bd4c0d4c0e6d6bd0e8b2d03d0b6a0000000000000000066a0468656c6c6f00000000","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			sequence := uint32(0xfffffffd)
+			hexTx, err := bitcoindClient.CreateRawTransaction(
+				[]TxInput{{Txid: "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", Vout: 1, Sequence: &sequence}},
+				[]TxOutput{{Address: "bc1q0g0wrc09kz75crfvpekkh58gktgrmzm2qpsl5w", Amount: 1}, {Data: "68656c6c6f"}},
+				0, true)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send inputs and outputs", func() {
+				Expect(params).To(Equal([]interface{}{
+					[]interface{}{map[string]interface{}{"txid": "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", "vout": float64(1), "sequence": float64(4294967293)}},
+					[]interface{}{map[string]interface{}{"bc1q0g0wrc09kz75crfvpekkh58gktgrmzm2qpsl5w": float64(1)}, map[string]interface{}{"data": "68656c6c6f"}},
+					float64(0),
+					true,
+				}))
+			})
+			It("should return the hex transaction", func() {
+				Expect(hexTx).To(HavePrefix("0200000001d1a2"))
+			})
+		})
+		Context("when no output is set", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.CreateRawTransaction(nil, nil, 0, false)
+			It("error should occured", func() {
+				Expect(err).To(MatchError("Bad parameters for CreateRawTransaction: you must set at least one output"))
+			})
+		})
+	})
+
+	Describe("Testing DecodeScript", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":{"asm":"OP_DUP OP_HASH160 e5344f52ecc92c279028a851c9d8ed57bb5dfc60 OP_EQUALVERIFY OP_CHECKSIG","desc":"addr(1MtvQUx6A8y9tQVfQ2VEFFNVPSUuQ7gfzG)#u6vvpjvz","address":"1MtvQUx6A8y9tQVfQ2VEFFNVPSUuQ7gfzG","type":"pubkeyhash","p2sh":"3LUvWM3YmWNKUQGKsHQyBBWvrQnDJe9L8q","segwit":{"asm":"0 e5344f52ecc92c279028a851c9d8ed57bb5dfc60","hex":"0014e5344f52ecc92c279028a851c9d8ed57bb5dfc60","address":"bc1qu56y75hveykz0ypg4pgunk8d2ld4mlrqp4xw7s","type":"witness_v0_keyhash","desc":"addr(bc1qu56y75hveykz0ypg4pgunk8d2ld4mlrqp4xw7s)#f7mstyuc","p2sh-segwit":"3GDw5V3Pm2u1bm3uKHdoDrJsBTkgRsVi4U"}},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			script, err := bitcoindClient.DecodeScript("76a914e5344f52ecc92c279028a851c9d8ed57bb5dfc6088ac")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return", func() {
+				Expect(script.Type).To(Equal("pubkeyhash"))
+				Expect(script.Address).To(Equal("1MtvQUx6A8y9tQVfQ2VEFFNVPSUuQ7gfzG"))
+				Expect(script.Segwit).To(Equal(&DecodedScriptSegwit{
+					Asm:        "0 e5344f52ecc92c279028a851c9d8ed57bb5dfc60",
+					Hex:        "0014e5344f52ecc92c279028a851c9d8ed57bb5dfc60",
+					Type:       "witness_v0_keyhash",
+					Address:    "bc1qu56y75hveykz0ypg4pgunk8d2ld4mlrqp4xw7s",
+					Desc:       "addr(bc1qu56y75hveykz0ypg4pgunk8d2ld4mlrqp4xw7s)#f7mstyuc",
+					P2SHSegwit: "3GDw5V3Pm2u1bm3uKHdoDrJsBTkgRsVi4U",
+				}))
+			})
+		})
+	})
+
+	Describe("Testing FundRawTransaction", func() {
+		Context("when success with options", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"hex":"02000000000100","fee":0.00001410,"changepos":1},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			feeRate := 10.0
+			replaceable := true
+			result, err := bitcoindClient.FundRawTransaction("0200000000010000000000", &FundRawTransactionOptions{
+				FeeRate:                &feeRate,
+				Replaceable:            &replaceable,
+				ChangeType:             "bech32",
+				SubtractFeeFromOutputs: []int{0},
+			})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send only the set options", func() {
+				Expect(params).To(Equal([]interface{}{
+					"0200000000010000000000",
+					map[string]interface{}{"fee_rate": float64(10), "replaceable": true, "change_type": "bech32", "subtractFeeFromOutputs": []interface{}{float64(0)}},
+				}))
+			})
+			It("should return", func() {
+				Expect(result).To(Equal(FundRawTransactionResult{Hex: "02000000000100", Fee: 1410, ChangePos: 1}))
+			})
+		})
+	})
+
+	Describe("Testing SignRawTransactionWithWallet", func() {
+		Context("when signature is incomplete", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"hex":"0200000001d1a2","complete":false,"errors":[{"txid":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","vout":1,"witness":[],"scriptSig":"","sequence":4294967293,"error":"Input not found or already spent"}]},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.SignRawTransactionWithWallet("0200000001d1a2", nil, "ALL")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send an empty prevtxs array before the sighash type", func() {
+				Expect(params).To(Equal([]interface{}{"0200000001d1a2", []interface{}{}, "ALL"}))
+			})
+			It("should return the errors", func() {
+				Expect(result.Complete).To(BeFalse())
+				Expect(result.Errors).To(Equal([]SignRawTransactionError{{
+					Txid:     "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+					Vout:     1,
+					Witness:  []string{},
+					Sequence: 4294967293,
+					Error:    "Input not found or already spent",
+				}}))
+			})
+		})
+	})
+
+	Describe("Testing SignRawTransactionWithKey", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"hex":"0200000001d1a2ff","complete":true},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.SignRawTransactionWithKey("0200000001d1a2", []string{"K7boEpon3igLpbVv6xebaR4bHALHPeLQSHhUJGiZ9S1U85ERWWi9"}, []PrevTx{{
+				Txid:         "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+				Vout:         1,
+				ScriptPubKey: "0014e5344f52ecc92c279028a851c9d8ed57bb5dfc60",
+				Amount:       0.5,
+			}}, "")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send keys and prevtxs", func() {
+				Expect(params).To(Equal([]interface{}{
+					"0200000001d1a2",
+					[]interface{}{"K7boEpon3igLpbVv6xebaR4bHALHPeLQSHhUJGiZ9S1U85ERWWi9"},
+					[]interface{}{map[string]interface{}{"txid": "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", "vout": float64(1), "scriptPubKey": "0014e5344f52ecc92c279028a851c9d8ed57bb5dfc60", "amount": 0.5}},
+				}))
+			})
+			It("should return", func() {
+				Expect(result).To(Equal(SignRawTransactionResult{Hex: "0200000001d1a2ff", Complete: true}))
+			})
+		})
+		Context("when no key is given", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.SignRawTransactionWithKey("0200000001d1a2", nil, nil, "")
+			It("error should occured", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Testing SendRawTransaction", func() {
+		Context("when success with maxfeerate", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":"00010589f7c108a4fd546df03a17bf485ede3baf52b35ddd5b83e974ec360abf","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			txID, err := bitcoindClient.SendRawTransaction("0200000001d1a2ff", 0)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the maxfeerate", func() {
+				Expect(params).To(Equal([]interface{}{"0200000001d1a2ff", float64(0)}))
+			})
+			It("should return the txid", func() {
+				Expect(txID).To(Equal("00010589f7c108a4fd546df03a17bf485ede3baf52b35ddd5b83e974ec360abf"))
+			})
+		})
+		Context("when transaction is rejected", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":null,"error":{"code":-26,"message":"min relay fee not met, 100 < 141"},"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			_, err = bitcoindClient.SendRawTransaction("0200000001d1a2ff")
+			It("error should be the RPC error", func() {
+				Expect(err).Should(MatchError("-26: min relay fee not met, 100 < 141"))
+			})
+		})
+	})
+
+	Describe("Testing TestMempoolAccept", func() {
+		Context("when success", func() {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":[{"txid":"00010589f7c108a4fd546df03a17bf485ede3baf52b35ddd5b83e974ec360abf","wtxid":"6bbe3c7a1e4c3b84dfa3ca29b07e2e06bc3d43d3fb8d9a9bd64cd06d13a6cd4a","allowed":true,"vsize":141,"fees":{"base":0.00001410,"effective-feerate":0.00010000,"effective-includes":["6bbe3c7a1e4c3b84dfa3ca29b07e2e06bc3d43d3fb8d9a9bd64cd06d13a6cd4a"]}},{"txid":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","wtxid":"d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90","allowed":false,"reject-reason":"missing-inputs"}],"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			results, err := bitcoindClient.TestMempoolAccept([]string{"0200000001d1a2ff", "0200000001e1a2ff"})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should return", func() {
+				Expect(results).To(Equal([]MempoolAcceptResult{
+					{
+						Txid:    "00010589f7c108a4fd546df03a17bf485ede3baf52b35ddd5b83e974ec360abf",
+						Wtxid:   "6bbe3c7a1e4c3b84dfa3ca29b07e2e06bc3d43d3fb8d9a9bd64cd06d13a6cd4a",
+						Allowed: true,
+						Vsize:   141,
+						Fees: &MempoolAcceptFees{
+							Base:              1410,
+							EffectiveFeeRate:  10000,
+							EffectiveIncludes: []string{"6bbe3c7a1e4c3b84dfa3ca29b07e2e06bc3d43d3fb8d9a9bd64cd06d13a6cd4a"},
+						},
+					},
+					{
+						Txid:         "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+						Wtxid:        "d1a2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+						RejectReason: "missing-inputs",
+					},
+				}))
+			})
+		})
+	})
+})
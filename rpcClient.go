@@ -98,8 +98,8 @@ func (c *rpcClient) doTimeoutRequest(timer *time.Timer, req *http.Request) (*htt
 	}
 }
 
-// call prepare & exec the request
-func (c *rpcClient) call(method string, params interface{}) (rr rpcResponse, err error) {
+// do prepare & exec the request, the caller must close the response body
+func (c *rpcClient) do(method string, params interface{}) (resp *http.Response, err error) {
 	connectTimer := time.NewTimer(time.Duration(c.timeout) * time.Second)
 	rpcR := rpcRequest{method, params, time.Now().UnixNano(), "1.0"}
 	payloadBuffer := &bytes.Buffer{}
@@ -120,7 +120,12 @@ func (c *rpcClient) call(method string, params interface{}) (rr rpcResponse, err
 		req.SetBasicAuth(c.user, c.passwd)
 	}
 
-	resp, err := c.doTimeoutRequest(connectTimer, req)
+	return c.doTimeoutRequest(connectTimer, req)
+}
+
+// call prepare & exec the request
+func (c *rpcClient) call(method string, params interface{}) (rr rpcResponse, err error) {
+	resp, err := c.do(method, params)
 	if err != nil {
 		return
 	}
@@ -133,4 +138,83 @@ func (c *rpcClient) call(method string, params interface{}) (rr rpcResponse, err
 
 	err = json.Unmarshal(data, &rr)
 	return
-}
\ No newline at end of file
+}
+
+// callStream prepare & exec the request, then decodes the response body as it
+// is read instead of loading it in memory.
+// onResult is called with the decoder positioned right after the opening
+// delimiter of a non null result object or array, and must consume the
+// result up to and including its closing delimiter.
+func (c *rpcClient) callStream(method string, params interface{}, onResult func(dec *json.Decoder) error) (err error) {
+	resp, err := c.do(method, params)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	if err = expectDelim(dec, '{'); err != nil {
+		return
+	}
+	var rpcErr *RPCError
+	for dec.More() {
+		var key string
+		if key, err = decodeKey(dec); err != nil {
+			return
+		}
+		switch key {
+		case "result":
+			var t json.Token
+			if t, err = dec.Token(); err != nil {
+				return
+			}
+			switch t {
+			case nil:
+			case json.Delim('{'), json.Delim('['):
+				if err = onResult(dec); err != nil {
+					return
+				}
+			default:
+				return fmt.Errorf("Unexpected result %v in %s response", t, method)
+			}
+		case "error":
+			if err = dec.Decode(&rpcErr); err != nil {
+				return
+			}
+		default:
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return
+			}
+		}
+	}
+	if rpcErr != nil {
+		return rpcErr
+	}
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token and checks it is the delimiter delim
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("Unexpected token %v, expecting %v", t, delim)
+	}
+	return nil
+}
+
+// decodeKey reads the next token as an object key
+func decodeKey(dec *json.Decoder) (string, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := t.(string)
+	if !ok {
+		return "", fmt.Errorf("Unexpected token %v, expecting an object key", t)
+	}
+	return key, nil
+}
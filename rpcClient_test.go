@@ -62,6 +62,19 @@ var _ = Describe("RpcClient", func() {
 
 		})
 
+		Context("When the response has trailing data", func() {
+			ts, host, port, err := getNewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, `{"result":1.5,"error":null,"id":1} garbage`)
+			}))
+			defer ts.Close()
+			client, err := newClient(host, port, "fake", "fake", false, 30)
+			_, err = client.call("getdifficulty", nil)
+
+			It("err should occured", func() {
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+
 	})
 
 })
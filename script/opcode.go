@@ -0,0 +1,161 @@
+package script
+
+import "strconv"
+
+// Opcodes used to parse and classify scripts
+const (
+	Op0                   = 0x00
+	OpPushData1           = 0x4c
+	OpPushData2           = 0x4d
+	OpPushData4           = 0x4e
+	Op1Negate             = 0x4f
+	Op1                   = 0x51
+	Op16                  = 0x60
+	OpReturn              = 0x6a
+	OpDup                 = 0x76
+	OpEqual               = 0x87
+	OpEqualVerify         = 0x88
+	OpHash160             = 0xa9
+	OpCheckSig            = 0xac
+	OpCheckMultiSig       = 0xae
+	OpCheckMultiSigVerify = 0xaf
+	OpInvalidOpcode       = 0xff
+)
+
+// opNames are the names of the opcodes, as bitcoind writes them in ASM
+var opNames = map[byte]string{
+	OpPushData1: "OP_PUSHDATA1",
+	OpPushData2: "OP_PUSHDATA2",
+	OpPushData4: "OP_PUSHDATA4",
+	0x50:        "OP_RESERVED",
+
+	// Control
+	0x61:     "OP_NOP",
+	0x62:     "OP_VER",
+	0x63:     "OP_IF",
+	0x64:     "OP_NOTIF",
+	0x65:     "OP_VERIF",
+	0x66:     "OP_VERNOTIF",
+	0x67:     "OP_ELSE",
+	0x68:     "OP_ENDIF",
+	0x69:     "OP_VERIFY",
+	OpReturn: "OP_RETURN",
+
+	// Stack
+	0x6b:  "OP_TOALTSTACK",
+	0x6c:  "OP_FROMALTSTACK",
+	0x6d:  "OP_2DROP",
+	0x6e:  "OP_2DUP",
+	0x6f:  "OP_3DUP",
+	0x70:  "OP_2OVER",
+	0x71:  "OP_2ROT",
+	0x72:  "OP_2SWAP",
+	0x73:  "OP_IFDUP",
+	0x74:  "OP_DEPTH",
+	0x75:  "OP_DROP",
+	OpDup: "OP_DUP",
+	0x77:  "OP_NIP",
+	0x78:  "OP_OVER",
+	0x79:  "OP_PICK",
+	0x7a:  "OP_ROLL",
+	0x7b:  "OP_ROT",
+	0x7c:  "OP_SWAP",
+	0x7d:  "OP_TUCK",
+
+	// Splice
+	0x7e: "OP_CAT",
+	0x7f: "OP_SUBSTR",
+	0x80: "OP_LEFT",
+	0x81: "OP_RIGHT",
+	0x82: "OP_SIZE",
+
+	// Bit logic
+	0x83:          "OP_INVERT",
+	0x84:          "OP_AND",
+	0x85:          "OP_OR",
+	0x86:          "OP_XOR",
+	OpEqual:       "OP_EQUAL",
+	OpEqualVerify: "OP_EQUALVERIFY",
+	0x89:          "OP_RESERVED1",
+	0x8a:          "OP_RESERVED2",
+
+	// Numeric
+	0x8b: "OP_1ADD",
+	0x8c: "OP_1SUB",
+	0x8d: "OP_2MUL",
+	0x8e: "OP_2DIV",
+	0x8f: "OP_NEGATE",
+	0x90: "OP_ABS",
+	0x91: "OP_NOT",
+	0x92: "OP_0NOTEQUAL",
+	0x93: "OP_ADD",
+	0x94: "OP_SUB",
+	0x95: "OP_MUL",
+	0x96: "OP_DIV",
+	0x97: "OP_MOD",
+	0x98: "OP_LSHIFT",
+	0x99: "OP_RSHIFT",
+	0x9a: "OP_BOOLAND",
+	0x9b: "OP_BOOLOR",
+	0x9c: "OP_NUMEQUAL",
+	0x9d: "OP_NUMEQUALVERIFY",
+	0x9e: "OP_NUMNOTEQUAL",
+	0x9f: "OP_LESSTHAN",
+	0xa0: "OP_GREATERTHAN",
+	0xa1: "OP_LESSTHANOREQUAL",
+	0xa2: "OP_GREATERTHANOREQUAL",
+	0xa3: "OP_MIN",
+	0xa4: "OP_MAX",
+	0xa5: "OP_WITHIN",
+
+	// Crypto
+	0xa6:                  "OP_RIPEMD160",
+	0xa7:                  "OP_SHA1",
+	0xa8:                  "OP_SHA256",
+	OpHash160:             "OP_HASH160",
+	0xaa:                  "OP_HASH256",
+	0xab:                  "OP_CODESEPARATOR",
+	OpCheckSig:            "OP_CHECKSIG",
+	0xad:                  "OP_CHECKSIGVERIFY",
+	OpCheckMultiSig:       "OP_CHECKMULTISIG",
+	OpCheckMultiSigVerify: "OP_CHECKMULTISIGVERIFY",
+
+	// Expansion
+	0xb0: "OP_NOP1",
+	0xb1: "OP_CHECKLOCKTIMEVERIFY",
+	0xb2: "OP_CHECKSEQUENCEVERIFY",
+	0xb3: "OP_NOP4",
+	0xb4: "OP_NOP5",
+	0xb5: "OP_NOP6",
+	0xb6: "OP_NOP7",
+	0xb7: "OP_NOP8",
+	0xb8: "OP_NOP9",
+	0xb9: "OP_NOP10",
+
+	// Tapscript
+	0xba: "OP_CHECKSIGADD",
+
+	OpInvalidOpcode: "OP_INVALIDOPCODE",
+}
+
+// OpName returns the name of an opcode as bitcoind writes it in ASM: small
+// integers are written as numbers, unknown opcodes as OP_UNKNOWN
+func OpName(op byte) string {
+	switch {
+	case op == Op0:
+		return "0"
+	case op == Op1Negate:
+		return "-1"
+	case op >= Op1 && op <= Op16:
+		return strconv.Itoa(int(op - Op1 + 1))
+	}
+	if name, ok := opNames[op]; ok {
+		return name
+	}
+	return "OP_UNKNOWN"
+}
+
+// isSmallInteger tells whether op pushes a number from 1 to 16
+func isSmallInteger(op byte) bool {
+	return op >= Op1 && op <= Op16
+}
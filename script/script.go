@@ -0,0 +1,224 @@
+// Package script parses, disassembles and classifies Bitcoin scripts.
+//
+// The ASM, script types and addresses are the ones Bitcoin Core returns in
+// the "asm", "type" and "address" fields of "decodescript" and
+// "decoderawtransaction", so that transactions decoded locally can be
+// inspected like the node would.
+package script
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// maxScriptSize is the maximum size of a spendable script
+const maxScriptSize = 10000
+
+// A Script is a serialized Bitcoin script
+type Script []byte
+
+// ParseHex decodes an hex encoded script
+func ParseHex(scriptHex string) (Script, error) {
+	return hex.DecodeString(scriptHex)
+}
+
+// String returns the hex encoding of the script
+func (s Script) String() string {
+	return hex.EncodeToString(s)
+}
+
+// An Instruction is an opcode of a script, with the data it pushes
+type Instruction struct {
+	// The opcode
+	Op byte
+
+	// The pushed data, empty for opcodes that are not data pushes
+	Data []byte
+}
+
+// Name returns the name of the opcode, as written in ASM
+func (i Instruction) Name() string {
+	return OpName(i.Op)
+}
+
+// readInstruction reads the instruction starting at pos and returns it with
+// the position of the next one
+func (s Script) readInstruction(pos int) (Instruction, int, error) {
+	op := s[pos]
+	pos++
+	if op > OpPushData4 {
+		return Instruction{Op: op}, pos, nil
+	}
+	size := int(op)
+	var lenSize int
+	switch op {
+	case OpPushData1:
+		lenSize = 1
+	case OpPushData2:
+		lenSize = 2
+	case OpPushData4:
+		lenSize = 4
+	}
+	if lenSize > 0 {
+		if len(s)-pos < lenSize {
+			return Instruction{}, pos, errors.New("Script push size out of bounds")
+		}
+		var b [4]byte
+		copy(b[:], s[pos:pos+lenSize])
+		n := binary.LittleEndian.Uint32(b[:])
+		if uint64(n) > uint64(len(s)-pos-lenSize) {
+			return Instruction{}, pos, errors.New("Script push out of bounds")
+		}
+		size = int(n)
+		pos += lenSize
+	}
+	if len(s)-pos < size {
+		return Instruction{}, pos, errors.New("Script push out of bounds")
+	}
+	return Instruction{Op: op, Data: s[pos : pos+size]}, pos + size, nil
+}
+
+// Instructions parses the script into its instructions. On a truncated data
+// push, it returns the instructions before it and an error.
+func (s Script) Instructions() ([]Instruction, error) {
+	var instructions []Instruction
+	for pos := 0; pos < len(s); {
+		var inst Instruction
+		var err error
+		if inst, pos, err = s.readInstruction(pos); err != nil {
+			return instructions, err
+		}
+		instructions = append(instructions, inst)
+	}
+	return instructions, nil
+}
+
+// IsPushOnly tells whether the script only pushes data, OP_RESERVED being
+// counted as a push as bitcoind does
+func (s Script) IsPushOnly() bool {
+	for pos := 0; pos < len(s); {
+		var inst Instruction
+		var err error
+		if inst, pos, err = s.readInstruction(pos); err != nil || inst.Op > Op16 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsUnspendable tells whether the script is provably unspendable: it starts
+// with OP_RETURN or is too large to be executed
+func (s Script) IsUnspendable() bool {
+	return (len(s) > 0 && s[0] == OpReturn) || len(s) > maxScriptSize
+}
+
+// Asm returns the script disassembly as bitcoind writes it for a
+// scriptPubKey: pushes of up to 4 bytes are written as numbers, other pushes
+// in hex and other opcodes by name. A truncated push ends with "[error]".
+func (s Script) Asm() string {
+	return s.asm(false)
+}
+
+// SigAsm returns the script disassembly as bitcoind writes it for a
+// scriptSig: like Asm, but the sighash type of signatures is decoded, as in
+// "3044...01" written "3044...[ALL]".
+func (s Script) SigAsm() string {
+	return s.asm(true)
+}
+
+// asm returns the disassembly of the script, decoding the sighash type of
+// signatures if sighashDecode is set
+func (s Script) asm(sighashDecode bool) string {
+	var parts []string
+	for pos := 0; pos < len(s); {
+		var inst Instruction
+		var err error
+		if inst, pos, err = s.readInstruction(pos); err != nil {
+			parts = append(parts, "[error]")
+			break
+		}
+		switch {
+		case inst.Op > OpPushData4:
+			parts = append(parts, OpName(inst.Op))
+		case len(inst.Data) <= 4:
+			parts = append(parts, strconv.FormatInt(scriptNum(inst.Data), 10))
+		case sighashDecode && !s.IsUnspendable():
+			parts = append(parts, signatureAsm(inst.Data))
+		default:
+			parts = append(parts, hex.EncodeToString(inst.Data))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// scriptNum decodes a script number of up to 4 bytes: little endian, the
+// highest bit of the last byte being the sign
+func scriptNum(data []byte) int64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var n int64
+	for i, b := range data {
+		n |= int64(b) << (8 * uint(i))
+	}
+	if data[len(data)-1]&0x80 != 0 {
+		return -(n &^ (0x80 << (8 * uint(len(data)-1))))
+	}
+	return n
+}
+
+// sighashTypes are the names of the sighash types
+var sighashTypes = map[byte]string{
+	0x01: "ALL",
+	0x81: "ALL|ANYONECANPAY",
+	0x02: "NONE",
+	0x82: "NONE|ANYONECANPAY",
+	0x03: "SINGLE",
+	0x83: "SINGLE|ANYONECANPAY",
+}
+
+// signatureAsm returns the hex of data, with the sighash type decoded if data
+// is a strictly encoded signature
+func signatureAsm(data []byte) string {
+	if isValidSignatureEncoding(data) {
+		if name, ok := sighashTypes[data[len(data)-1]]; ok {
+			return hex.EncodeToString(data[:len(data)-1]) + "[" + name + "]"
+		}
+	}
+	return hex.EncodeToString(data)
+}
+
+// isValidSignatureEncoding tells whether sig is a strict DER signature
+// followed by a sighash type byte, as defined in BIP 66
+func isValidSignatureEncoding(sig []byte) bool {
+	if len(sig) < 9 || len(sig) > 73 {
+		return false
+	}
+	if sig[0] != 0x30 || int(sig[1]) != len(sig)-3 {
+		return false
+	}
+	lenR := int(sig[3])
+	if 5+lenR >= len(sig) {
+		return false
+	}
+	lenS := int(sig[5+lenR])
+	if lenR+lenS+7 != len(sig) {
+		return false
+	}
+	if sig[2] != 0x02 || lenR == 0 || sig[4]&0x80 != 0 {
+		return false
+	}
+	if lenR > 1 && sig[4] == 0x00 && sig[5]&0x80 == 0 {
+		return false
+	}
+	if sig[lenR+4] != 0x02 || lenS == 0 || sig[lenR+6]&0x80 != 0 {
+		return false
+	}
+	if lenS > 1 && sig[lenR+6] == 0x00 && sig[lenR+7]&0x80 == 0 {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package script
+
+import (
+	"strings"
+
+	"github.com/toorop/go-bitcoind/address"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Public keys of the private keys 1 and 2
+const (
+	pubKey1 = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	pubKey2 = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+)
+
+// genesisScript is the pay-to-pubkey output of the genesis block coinbase
+const genesisScript = "4104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac"
+
+// mustParse decodes an hex script
+func mustParse(scriptHex string) Script {
+	s, err := ParseHex(scriptHex)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+var _ = Describe("Script", func() {
+	Describe("Testing Instructions", func() {
+		It("should parse pushes and opcodes", func() {
+			instructions, err := mustParse("004c0201024d0100034e010000000476").Instructions()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instructions).To(Equal([]Instruction{
+				{Op: Op0, Data: []byte{}},
+				{Op: OpPushData1, Data: []byte{1, 2}},
+				{Op: OpPushData2, Data: []byte{3}},
+				{Op: OpPushData4, Data: []byte{4}},
+				{Op: OpDup},
+			}))
+			Expect(instructions[4].Name()).To(Equal("OP_DUP"))
+		})
+		It("should fail on truncated pushes", func() {
+			for _, scriptHex := range []string{"01", "4c", "4c02ff", "4d01", "4e01000000", "76a914751e76e8"} {
+				_, err := mustParse(scriptHex).Instructions()
+				Expect(err).To(HaveOccurred(), scriptHex)
+			}
+		})
+	})
+
+	Describe("Testing Asm", func() {
+		It("should disassemble as bitcoind does", func() {
+			for _, v := range []struct {
+				script string
+				asm    string
+			}{
+				{"76a914e5344f52ecc92c279028a851c9d8ed57bb5dfc6088ac", "OP_DUP OP_HASH160 e5344f52ecc92c279028a851c9d8ed57bb5dfc60 OP_EQUALVERIFY OP_CHECKSIG"},
+				{genesisScript, genesisScript[2:132] + " OP_CHECKSIG"},
+				{"0014751e76e8199196d454941c45d1b3a323f1433bd6", "0 751e76e8199196d454941c45d1b3a323f1433bd6"},
+				{"5121" + pubKey1 + "21" + pubKey2 + "52ae", "1 " + pubKey1 + " " + pubKey2 + " 2 OP_CHECKMULTISIG"},
+				{"6a0b68656c6c6f20776f726c64", "OP_RETURN 68656c6c6f20776f726c64"},
+				// Pushes of up to 4 bytes are written as script numbers
+				{"6a04deadbeef", "OP_RETURN -1874767326"},
+				{"6002751e", "16 7797"},
+				{"0100 0181 0180 4f", "0 -1 0 -1"},
+				{"b1b2ba bb ff", "OP_CHECKLOCKTIMEVERIFY OP_CHECKSEQUENCEVERIFY OP_CHECKSIGADD OP_UNKNOWN OP_INVALIDOPCODE"},
+				{"4c00 50", "0 OP_RESERVED"},
+				{"76a914751e76e8", "OP_DUP OP_HASH160 [error]"},
+				{"", ""},
+			} {
+				Expect(mustParse(strings.ReplaceAll(v.script, " ", "")).Asm()).To(Equal(v.asm), v.script)
+			}
+		})
+		It("should decode sighash types in scriptSigs", func() {
+			sig := "3044022032771ee953e3c7809f57179dfb8d58eb4e47fb8721441174325a3e3008c11c8102200b436200ab9d006aab87c8595749da9c03e4490179401de9b5c281cfa5e658c4"
+			pubKey := "03bfde0ae35e6aabc875f937ea708934583aa33f47b5653b12acae191a4ad5ff5e"
+			s := mustParse("47" + sig + "0121" + pubKey)
+			Expect(s.SigAsm()).To(Equal(sig + "[ALL] " + pubKey))
+			Expect(s.Asm()).To(Equal(sig + "01 " + pubKey))
+			// Undefined sighash type
+			Expect(mustParse("47" + sig + "04").SigAsm()).To(Equal(sig + "04"))
+			// Not a signature
+			Expect(mustParse("21" + pubKey).SigAsm()).To(Equal(pubKey))
+		})
+	})
+
+	Describe("Testing Type", func() {
+		It("should classify scripts as bitcoind does", func() {
+			for _, v := range []struct {
+				script string
+				typ    Type
+			}{
+				{genesisScript, PubKey},
+				{"21" + pubKey1 + "ac", PubKey},
+				{"76a914e5344f52ecc92c279028a851c9d8ed57bb5dfc6088ac", PubKeyHash},
+				{"a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487", ScriptHash},
+				{"0014751e76e8199196d454941c45d1b3a323f1433bd6", WitnessV0KeyHash},
+				{"00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", WitnessV0ScriptHash},
+				{"512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", WitnessV1Taproot},
+				{"51024e73", Anchor},
+				{"6002751e", WitnessUnknown},
+				{"5210751e76e8199196d454941c45d1b3a323", WitnessUnknown},
+				{"5121" + pubKey1 + "21" + pubKey2 + "52ae", MultiSig},
+				{"6a", NullData},
+				{"6a0b68656c6c6f20776f726c64", NullData},
+				{"6a4c0100", NullData},
+				// Invalid witness v0 program size
+				{"0010751e76e8199196d454941c45d1b3a323", NonStandard},
+				// Invalid public key header
+				{"2105" + pubKey1[2:] + "ac", NonStandard},
+				// Non minimal required signatures push
+				{"010121" + pubKey1 + "51ae", NonStandard},
+				// Key count mismatch
+				{"5121" + pubKey1 + "52ae", NonStandard},
+				// More required signatures than keys
+				{"5221" + pubKey1 + "51ae", NonStandard},
+				// Data after OP_RETURN that is not a push
+				{"6a76", NonStandard},
+				{"76a914751e76e8", NonStandard},
+				{"", NonStandard},
+			} {
+				Expect(mustParse(v.script).Type()).To(Equal(v.typ), v.script)
+			}
+		})
+	})
+
+	Describe("Testing Address", func() {
+		It("should return the address of scripts having one", func() {
+			for _, v := range []struct {
+				script string
+				addr   string
+			}{
+				{"76a914e5344f52ecc92c279028a851c9d8ed57bb5dfc6088ac", "1MtvQUx6A8y9tQVfQ2VEFFNVPSUuQ7gfzG"},
+				{"0014751e76e8199196d454941c45d1b3a323f1433bd6", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+				{"00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", "bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3"},
+				{"512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0"},
+				{"51024e73", "bc1pfeessrawgf"},
+				{"6002751e", "bc1sw50qgdz25j"},
+			} {
+				addr, err := mustParse(v.script).Address(address.MainNet)
+				Expect(err).NotTo(HaveOccurred(), v.script)
+				Expect(addr.Address).To(Equal(v.addr))
+			}
+		})
+		It("should fail for scripts without address", func() {
+			for _, scriptHex := range []string{genesisScript, "5121" + pubKey1 + "51ae", "6a", "0010751e76e8199196d454941c45d1b3a323"} {
+				_, err := mustParse(scriptHex).Address(address.MainNet)
+				Expect(err).To(HaveOccurred(), scriptHex)
+			}
+		})
+		It("should use the network", func() {
+			addr, err := mustParse("0014751e76e8199196d454941c45d1b3a323f1433bd6").Address(address.RegTest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addr.Address).To(HavePrefix("bcrt1q"))
+		})
+	})
+
+	Describe("Testing Addresses", func() {
+		It("should return the legacy addresses", func() {
+			addrs, reqSigs := mustParse(genesisScript).Addresses(address.MainNet)
+			Expect(addrs).To(Equal([]string{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"}))
+			Expect(reqSigs).To(Equal(1))
+
+			addrs, reqSigs = mustParse("5121" + pubKey1 + "21" + pubKey2 + "52ae").Addresses(address.MainNet)
+			Expect(addrs).To(Equal([]string{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP"}))
+			Expect(reqSigs).To(Equal(1))
+
+			addrs, reqSigs = mustParse("76a914e5344f52ecc92c279028a851c9d8ed57bb5dfc6088ac").Addresses(address.MainNet)
+			Expect(addrs).To(Equal([]string{"1MtvQUx6A8y9tQVfQ2VEFFNVPSUuQ7gfzG"}))
+			Expect(reqSigs).To(Equal(1))
+
+			addrs, reqSigs = mustParse("6a").Addresses(address.MainNet)
+			Expect(addrs).To(BeNil())
+			Expect(reqSigs).To(Equal(0))
+		})
+	})
+
+	Describe("Testing MultiSig", func() {
+		It("should return the keys", func() {
+			required, pubKeys, ok := mustParse("5221" + pubKey1 + "21" + pubKey2 + "52ae").MultiSig()
+			Expect(ok).To(BeTrue())
+			Expect(required).To(Equal(2))
+			Expect(pubKeys).To(HaveLen(2))
+			Expect(Script(pubKeys[1]).String()).To(Equal(pubKey2))
+		})
+	})
+})
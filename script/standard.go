@@ -0,0 +1,247 @@
+package script
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/toorop/go-bitcoind/address"
+)
+
+// maxPubKeysPerMultisig is the maximum number of keys of a bare multisig
+const maxPubKeysPerMultisig = 20
+
+// A Type represents a scriptPubKey template, named as in the "type" field of
+// bitcoind responses
+type Type string
+
+// Script types
+const (
+	NonStandard         Type = "nonstandard"
+	PubKey              Type = "pubkey"
+	PubKeyHash          Type = "pubkeyhash"
+	ScriptHash          Type = "scripthash"
+	MultiSig            Type = "multisig"
+	NullData            Type = "nulldata"
+	WitnessV0KeyHash    Type = "witness_v0_keyhash"
+	WitnessV0ScriptHash Type = "witness_v0_scripthash"
+	WitnessV1Taproot    Type = "witness_v1_taproot"
+	Anchor              Type = "anchor"
+	WitnessUnknown      Type = "witness_unknown"
+)
+
+// anchorProgram is the witness program of pay-to-anchor outputs
+var anchorProgram = []byte{0x4e, 0x73}
+
+// Type returns the template the script matches, as bitcoind classifies
+// scriptPubKeys
+func (s Script) Type() Type {
+	typ, _ := s.solve()
+	return typ
+}
+
+// IsWitnessProgram tells whether the script is a segwit output, and returns
+// its witness version and program
+func (s Script) IsWitnessProgram() (version int, program []byte, ok bool) {
+	if len(s) < 4 || len(s) > 42 {
+		return 0, nil, false
+	}
+	if s[0] != Op0 && !isSmallInteger(s[0]) {
+		return 0, nil, false
+	}
+	if int(s[1])+2 != len(s) {
+		return 0, nil, false
+	}
+	if s[0] != Op0 {
+		version = int(s[0] - Op1 + 1)
+	}
+	return version, s[2:], true
+}
+
+// MultiSig returns the number of required signatures and the public keys of
+// a bare multisig script
+func (s Script) MultiSig() (required int, pubKeys [][]byte, ok bool) {
+	if len(s) < 1 || s[len(s)-1] != OpCheckMultiSig {
+		return 0, nil, false
+	}
+	inst, pos, err := s.readInstruction(0)
+	if err != nil {
+		return 0, nil, false
+	}
+	if required, ok = multisigNumber(inst, 1, maxPubKeysPerMultisig); !ok {
+		return 0, nil, false
+	}
+	for pos < len(s) {
+		if inst, pos, err = s.readInstruction(pos); err != nil || !isValidPubKeySize(inst.Data) {
+			break
+		}
+		pubKeys = append(pubKeys, inst.Data)
+	}
+	if err != nil {
+		return 0, nil, false
+	}
+	count, ok := multisigNumber(inst, required, maxPubKeysPerMultisig)
+	if !ok || count != len(pubKeys) || pos+1 != len(s) {
+		return 0, nil, false
+	}
+	return required, pubKeys, true
+}
+
+// solve returns the type of the script and the data identifying its
+// destination: the public key, hash or witness program, or the public keys
+// of a multisig
+func (s Script) solve() (Type, [][]byte) {
+	if len(s) == 23 && s[0] == OpHash160 && s[1] == 20 && s[22] == OpEqual {
+		return ScriptHash, [][]byte{s[2:22]}
+	}
+	if version, program, ok := s.IsWitnessProgram(); ok {
+		switch {
+		case version == 0 && len(program) == 20:
+			return WitnessV0KeyHash, [][]byte{program}
+		case version == 0 && len(program) == 32:
+			return WitnessV0ScriptHash, [][]byte{program}
+		case version == 1 && len(program) == 32:
+			return WitnessV1Taproot, [][]byte{program}
+		case version == 1 && bytes.Equal(program, anchorProgram):
+			return Anchor, nil
+		case version != 0:
+			return WitnessUnknown, [][]byte{program}
+		}
+		return NonStandard, nil
+	}
+	if len(s) >= 1 && s[0] == OpReturn && s[1:].IsPushOnly() {
+		return NullData, nil
+	}
+	if pubKey := s.payToPubKey(); pubKey != nil {
+		return PubKey, [][]byte{pubKey}
+	}
+	if len(s) == 25 && s[0] == OpDup && s[1] == OpHash160 && s[2] == 20 && s[23] == OpEqualVerify && s[24] == OpCheckSig {
+		return PubKeyHash, [][]byte{s[3:23]}
+	}
+	if _, pubKeys, ok := s.MultiSig(); ok {
+		return MultiSig, pubKeys
+	}
+	return NonStandard, nil
+}
+
+// payToPubKey returns the public key of a pay-to-pubkey script, nil if the
+// script is not one
+func (s Script) payToPubKey() []byte {
+	for _, size := range []int{65, 33} {
+		if len(s) == size+2 && int(s[0]) == size && s[len(s)-1] == OpCheckSig && isValidPubKeySize(s[1:size+1]) {
+			return s[1 : size+1]
+		}
+	}
+	return nil
+}
+
+// isValidPubKeySize tells whether the size of a public key matches its
+// header byte, compressed or not
+func isValidPubKeySize(pubKey []byte) bool {
+	if len(pubKey) == 0 {
+		return false
+	}
+	switch pubKey[0] {
+	case 0x02, 0x03:
+		return len(pubKey) == 33
+	case 0x04, 0x06, 0x07:
+		return len(pubKey) == 65
+	}
+	return false
+}
+
+// multisigNumber returns the number pushed by inst, which must be between
+// min and max: a small integer opcode or a minimal push of a script number
+func multisigNumber(inst Instruction, min, max int) (int, bool) {
+	var n int64
+	switch {
+	case isSmallInteger(inst.Op):
+		n = int64(inst.Op - Op1 + 1)
+	case inst.Op > Op0 && inst.Op <= OpPushData4:
+		if !isMinimalPush(inst) || len(inst.Data) > 4 || !isMinimalNum(inst.Data) {
+			return 0, false
+		}
+		n = scriptNum(inst.Data)
+	default:
+		return 0, false
+	}
+	if n < int64(min) || n > int64(max) {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// isMinimalPush tells whether inst pushes its data with the smallest opcode
+func isMinimalPush(inst Instruction) bool {
+	size := len(inst.Data)
+	switch {
+	case size == 0:
+		return inst.Op == Op0
+	case size == 1 && inst.Data[0] >= 1 && inst.Data[0] <= 16:
+		return false
+	case size == 1 && inst.Data[0] == 0x81:
+		return false
+	case size <= 75:
+		return int(inst.Op) == size
+	case size <= 0xff:
+		return inst.Op == OpPushData1
+	case size <= 0xffff:
+		return inst.Op == OpPushData2
+	}
+	return true
+}
+
+// isMinimalNum tells whether data is the minimal encoding of a script number
+func isMinimalNum(data []byte) bool {
+	if len(data) == 0 || data[len(data)-1]&0x7f != 0 {
+		return true
+	}
+	return len(data) > 1 && data[len(data)-2]&0x80 != 0
+}
+
+// Address returns the address the script pays to on net, for P2PKH, P2SH
+// and segwit scripts. Like bitcoind, pay-to-pubkey, multisig and data
+// scripts have no address.
+func (s Script) Address(net *address.Network) (*address.Address, error) {
+	switch s.Type() {
+	case PubKeyHash, ScriptHash, WitnessV0KeyHash, WitnessV0ScriptHash, WitnessV1Taproot, Anchor, WitnessUnknown:
+		return address.FromScript(s, net)
+	}
+	return nil, errors.New("scriptPubKey has no address")
+}
+
+// Addresses returns the addresses of the script on net and the number of
+// signatures required to spend it, like the "addresses" and "reqSigs"
+// fields of bitcoind before v22: pay-to-pubkey and multisig keys are
+// written as P2PKH addresses. Scripts without address return nil.
+func (s Script) Addresses(net *address.Network) (addrs []string, reqSigs int) {
+	typ, solutions := s.solve()
+	switch typ {
+	case PubKey, MultiSig:
+		reqSigs = 1
+		if typ == MultiSig {
+			reqSigs, _, _ = s.MultiSig()
+		}
+		for _, pubKey := range solutions {
+			addr, err := address.FromScript(p2pkhScript(address.Hash160(pubKey)), net)
+			if err != nil {
+				return nil, 0
+			}
+			addrs = append(addrs, addr.Address)
+		}
+		return addrs, reqSigs
+	case NullData, NonStandard:
+		return nil, 0
+	}
+	addr, err := s.Address(net)
+	if err != nil {
+		return nil, 0
+	}
+	return []string{addr.Address}, 1
+}
+
+// p2pkhScript returns the P2PKH scriptPubKey of a public key hash
+func p2pkhScript(pubKeyHash []byte) []byte {
+	script := []byte{OpDup, OpHash160, 20}
+	script = append(script, pubKeyHash...)
+	return append(script, OpEqualVerify, OpCheckSig)
+}
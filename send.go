@@ -0,0 +1,347 @@
+package bitcoind
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Fee estimate modes of the "estimate_mode" option
+const (
+	EstimateModeUnset        = "unset"
+	EstimateModeEconomical   = "economical"
+	EstimateModeConservative = "conservative"
+)
+
+// SendToAddressOptions represents the options of "sendtoaddress" call, nil
+// and zero fields are left to the node defaults
+type SendToAddressOptions struct {
+	// A comment used to store what the transaction is for
+	Comment string
+	// A comment to store the name of the person or organization to which
+	// you're sending the transaction
+	CommentTo string
+	// The fee will be deducted from the amount being sent
+	SubtractFeeFromAmount bool
+	// Allow this transaction to be replaced by a transaction with higher
+	// fees via BIP 125
+	Replaceable *bool
+	// Confirmation target in blocks
+	ConfTarget *int
+	// The fee estimate mode, one of the EstimateMode constants
+	EstimateMode string
+	// Avoid spending from dirty addresses, only with avoid_reuse wallets
+	AvoidReuse *bool
+	// Fee rate in sat/vB
+	FeeRate *float64
+	// Also return the reason of the fee selection
+	Verbose bool
+}
+
+// SendManyOptions represents the options of "sendmany" call, nil and zero
+// fields are left to the node defaults
+type SendManyOptions struct {
+	// A comment
+	Comment string
+	// The addresses the fee is equally deducted from
+	SubtractFeeFrom []string
+	// Allow this transaction to be replaced by a transaction with higher
+	// fees via BIP 125
+	Replaceable *bool
+	// Confirmation target in blocks
+	ConfTarget *int
+	// The fee estimate mode, one of the EstimateMode constants
+	EstimateMode string
+	// Fee rate in sat/vB
+	FeeRate *float64
+	// Also return the reason of the fee selection
+	Verbose bool
+}
+
+// WalletSendResult represents a response to "sendtoaddress" and "sendmany"
+// calls
+type WalletSendResult struct {
+	// The transaction id
+	TxID string `json:"txid"`
+	// The transaction fee reason, only with the Verbose option
+	FeeReason string `json:"fee_reason,omitempty"`
+}
+
+// UnmarshalJSON decodes the txid returned by default, or the object returned
+// with the Verbose option
+func (r *WalletSendResult) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &r.TxID); err == nil {
+		return nil
+	}
+	type result WalletSendResult
+	return json.Unmarshal(data, (*result)(r))
+}
+
+// SendOptions represents the options of "send" call, nil and zero fields are
+// left to the node defaults
+type SendOptions struct {
+	// Automatically include coins from the wallet to cover the target amount
+	AddInputs *bool `json:"add_inputs,omitempty"`
+	// Include inputs that are not safe to spend
+	IncludeUnsafe *bool `json:"include_unsafe,omitempty"`
+	// When false, returns a serialized transaction which will not be added
+	// to the wallet or broadcast
+	AddToWallet *bool `json:"add_to_wallet,omitempty"`
+	// The bitcoin address to receive the change
+	ChangeAddress string `json:"change_address,omitempty"`
+	// The index of the change output
+	ChangePosition *int `json:"change_position,omitempty"`
+	// The output type to use, one of the AddressType constants
+	ChangeType string `json:"change_type,omitempty"`
+	// Confirmation target in blocks
+	ConfTarget *int `json:"conf_target,omitempty"`
+	// The fee estimate mode, one of the EstimateMode constants
+	EstimateMode string `json:"estimate_mode,omitempty"`
+	// Fee rate in sat/vB
+	FeeRate *float64 `json:"fee_rate,omitempty"`
+	// Also select inputs which are watch only
+	IncludeWatching *bool `json:"include_watching,omitempty"`
+	// Specify inputs instead of adding them automatically
+	Inputs []TxInput `json:"inputs,omitempty"`
+	// Raw locktime
+	Locktime *uint32 `json:"locktime,omitempty"`
+	// Lock selected unspent outputs
+	LockUnspents *bool `json:"lock_unspents,omitempty"`
+	// Always return a PSBT
+	Psbt *bool `json:"psbt,omitempty"`
+	// Outputs to subtract the fee from, specified as integer indices
+	SubtractFeeFromOutputs []int `json:"subtract_fee_from_outputs,omitempty"`
+	// Marks this transaction as BIP 125 replaceable
+	Replaceable *bool `json:"replaceable,omitempty"`
+}
+
+// SendAllRecipient represents a recipient of "sendall" call, receiving
+// Amount, or AmountSat, or when both are 0, an equal share of the remaining
+// value
+type SendAllRecipient struct {
+	Address string
+	// The amount in BTC, 0 for a share of the remaining value
+	Amount float64
+	// The exact amount, used instead of Amount when not 0
+	AmountSat Amount
+}
+
+// MarshalJSON encodes the recipient as expected by bitcoind, "address" or
+// {"address": amount}
+func (r SendAllRecipient) MarshalJSON() ([]byte, error) {
+	switch {
+	case r.AmountSat != 0:
+		return json.Marshal(map[string]Amount{r.Address: r.AmountSat})
+	case r.Amount != 0:
+		return json.Marshal(map[string]float64{r.Address: r.Amount})
+	}
+	return json.Marshal(r.Address)
+}
+
+// SendAllOptions represents the options of "sendall" call, nil and zero
+// fields are left to the node defaults
+type SendAllOptions struct {
+	// When false, returns the serialized transaction without broadcasting
+	// or adding it to the wallet
+	AddToWallet *bool `json:"add_to_wallet,omitempty"`
+	// Confirmation target in blocks
+	ConfTarget *int `json:"conf_target,omitempty"`
+	// The fee estimate mode, one of the EstimateMode constants
+	EstimateMode string `json:"estimate_mode,omitempty"`
+	// Fee rate in sat/vB
+	FeeRate *float64 `json:"fee_rate,omitempty"`
+	// Also select inputs which are watch-only
+	IncludeWatching *bool `json:"include_watching,omitempty"`
+	// Use exactly the specified inputs to build the transaction
+	Inputs []TxInput `json:"inputs,omitempty"`
+	// Raw locktime
+	Locktime *uint32 `json:"locktime,omitempty"`
+	// Lock selected unspent outputs
+	LockUnspents *bool `json:"lock_unspents,omitempty"`
+	// Always return a PSBT
+	Psbt *bool `json:"psbt,omitempty"`
+	// Send the maximum amount, spending only outputs worth more than their
+	// spending fee
+	SendMax *bool `json:"send_max,omitempty"`
+	// Require inputs with at least this many confirmations
+	MinConf *uint32 `json:"minconf,omitempty"`
+	// Require inputs with at most this many confirmations
+	MaxConf *uint32 `json:"maxconf,omitempty"`
+	// Marks this transaction as BIP 125 replaceable
+	Replaceable *bool `json:"replaceable,omitempty"`
+}
+
+// SendResult represents a response to "send" and "sendall" calls
+type SendResult struct {
+	// If the transaction has a complete set of signatures
+	Complete bool `json:"complete"`
+	// The transaction id for the send, only if the transaction was
+	// complete and added to the wallet
+	TxID string `json:"txid,omitempty"`
+	// The hex-encoded raw transaction, if add_to_wallet is false
+	Hex string `json:"hex,omitempty"`
+	// The base64-encoded unsigned PSBT, if not complete or add_to_wallet is
+	// false
+	Psbt string `json:"psbt,omitempty"`
+}
+
+// SendToAddressWithOptions sends an amount to a given address.
+// [options] may be nil.
+func (b *Bitcoind) SendToAddressWithOptions(address string, amount float64, options *SendToAddressOptions) (result WalletSendResult, err error) {
+	return b.sendToAddress(address, amount, options)
+}
+
+// SendToAddressSat sends an exact amount to a given address.
+// [options] may be nil.
+func (b *Bitcoind) SendToAddressSat(address string, amount Amount, options *SendToAddressOptions) (result WalletSendResult, err error) {
+	return b.sendToAddress(address, amount, options)
+}
+
+// sendToAddress calls "sendtoaddress" with a float64 or Amount amount
+func (b *Bitcoind) sendToAddress(address string, amount interface{}, options *SendToAddressOptions) (result WalletSendResult, err error) {
+	if options == nil {
+		options = &SendToAddressOptions{}
+	}
+	params := []interface{}{address, amount, options.Comment, options.CommentTo, optionalFlag(options.SubtractFeeFromAmount),
+		optionalBool(options.Replaceable), optionalInt(options.ConfTarget), optionalString(options.EstimateMode),
+		optionalBool(options.AvoidReuse), optionalFloat(options.FeeRate), optionalFlag(options.Verbose)}
+	r, err := b.client.call("sendtoaddress", trimParams(params, 2))
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// SendManyWithOptions sends amounts to multiple addresses, indexed by
+// address, from outputs having at least [minconf] confirmations.
+// [options] may be nil.
+func (b *Bitcoind) SendManyWithOptions(amounts map[string]float64, minconf uint32, options *SendManyOptions) (result WalletSendResult, err error) {
+	if len(amounts) == 0 {
+		err = errors.New("Bad parameters for SendManyWithOptions: you must set at least one amount")
+		return
+	}
+	return b.sendMany(amounts, minconf, options)
+}
+
+// SendManySat sends exact amounts to multiple addresses, indexed by address,
+// from outputs having at least [minconf] confirmations.
+// [options] may be nil.
+func (b *Bitcoind) SendManySat(amounts map[string]Amount, minconf uint32, options *SendManyOptions) (result WalletSendResult, err error) {
+	if len(amounts) == 0 {
+		err = errors.New("Bad parameters for SendManySat: you must set at least one amount")
+		return
+	}
+	return b.sendMany(amounts, minconf, options)
+}
+
+// sendMany calls "sendmany" with float64 or Amount amounts
+func (b *Bitcoind) sendMany(amounts interface{}, minconf uint32, options *SendManyOptions) (result WalletSendResult, err error) {
+	if options == nil {
+		options = &SendManyOptions{}
+	}
+	subtractFeeFrom := options.SubtractFeeFrom
+	if subtractFeeFrom == nil {
+		subtractFeeFrom = []string{}
+	}
+	params := []interface{}{"", amounts, minconf, options.Comment, subtractFeeFrom,
+		optionalBool(options.Replaceable), optionalInt(options.ConfTarget), optionalString(options.EstimateMode),
+		optionalFloat(options.FeeRate), optionalFlag(options.Verbose)}
+	r, err := b.client.call("sendmany", trimParams(params, 3))
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// Send sends a transaction paying [outputs], funded and signed by the
+// wallet, and broadcasts it unless the AddToWallet option is false.
+// [options] may be nil.
+func (b *Bitcoind) Send(outputs []TxOutput, options *SendOptions) (result SendResult, err error) {
+	if len(outputs) == 0 {
+		err = errors.New("Bad parameters for Send: you must set at least one output")
+		return
+	}
+	params := []interface{}{outputs}
+	if options != nil {
+		params = append(params, nil, nil, nil, options)
+	}
+	r, err := b.client.call("send", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// SendAll spends the wallet outputs, or the Inputs option, to [recipients]
+// and broadcasts the transaction unless the AddToWallet option is false.
+// [options] may be nil.
+func (b *Bitcoind) SendAll(recipients []SendAllRecipient, options *SendAllOptions) (result SendResult, err error) {
+	if len(recipients) == 0 {
+		err = errors.New("Bad parameters for SendAll: you must set at least one recipient")
+		return
+	}
+	params := []interface{}{recipients}
+	if options != nil {
+		params = append(params, nil, nil, nil, options)
+	}
+	r, err := b.client.call("sendall", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &result)
+	return
+}
+
+// optionalBool returns *b, or nil to let bitcoind use the parameter default
+func optionalBool(b *bool) interface{} {
+	if b == nil {
+		return nil
+	}
+	return *b
+}
+
+// optionalInt returns *i, or nil to let bitcoind use the parameter default
+func optionalInt(i *int) interface{} {
+	if i == nil {
+		return nil
+	}
+	return *i
+}
+
+// optionalFloat returns *f, or nil to let bitcoind use the parameter default
+func optionalFloat(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
+
+// optionalString returns s, or nil to let bitcoind use the parameter default
+// when s is empty
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// optionalFlag returns true, or nil to let bitcoind use the parameter
+// default (false) when f is false
+func optionalFlag(f bool) interface{} {
+	if !f {
+		return nil
+	}
+	return true
+}
+
+// trimParams removes the trailing nil positional parameters, left to their
+// default, keeping at least the first [required] ones
+func trimParams(params []interface{}, required int) []interface{} {
+	n := len(params)
+	for n > required && params[n-1] == nil {
+		n--
+	}
+	return params[:n]
+}
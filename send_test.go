@@ -0,0 +1,221 @@
+package bitcoind
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Send", func() {
+	Describe("Testing SendToAddressWithOptions", func() {
+		Context("when success with options", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"txid":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515","fee_reason":"Fallback fee"},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			replaceable := false
+			feeRate := 12.5
+			result, err := bitcoindClient.SendToAddressWithOptions("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", 0.1, &SendToAddressOptions{
+				SubtractFeeFromAmount: true,
+				Replaceable:           &replaceable,
+				FeeRate:               &feeRate,
+				Verbose:               true,
+			})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the options, unset ones as null", func() {
+				Expect(params).To(Equal([]interface{}{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", 0.1, "", "", true, false, nil, nil, nil, 12.5, true}))
+			})
+			It("should return the verbose result", func() {
+				Expect(result).To(Equal(WalletSendResult{TxID: "a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515", FeeReason: "Fallback fee"}))
+			})
+		})
+		Context("when success without options", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			result, err := bitcoindClient.SendToAddressWithOptions("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", 0.1, nil)
+			It("should not send trailing defaults", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(params).To(Equal([]interface{}{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", 0.1, "", ""}))
+			})
+			It("should return the txid", func() {
+				Expect(result).To(Equal(WalletSendResult{TxID: "a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"}))
+			})
+		})
+	})
+
+	Describe("Testing SendManyWithOptions", func() {
+		Context("when success with options", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515","error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			confTarget := 6
+			result, err := bitcoindClient.SendManyWithOptions(map[string]float64{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4": 0.1}, 1, &SendManyOptions{
+				SubtractFeeFrom: []string{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+				ConfTarget:      &confTarget,
+				EstimateMode:    EstimateModeEconomical,
+			})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the options", func() {
+				Expect(params).To(Equal([]interface{}{
+					"",
+					map[string]interface{}{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4": 0.1},
+					float64(1),
+					"",
+					[]interface{}{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+					nil,
+					float64(6),
+					"economical",
+				}))
+			})
+			It("should return the txid", func() {
+				Expect(result.TxID).To(Equal("a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"))
+			})
+		})
+		Context("when no amount is given", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.SendManyWithOptions(nil, 1, nil)
+			It("should error", func() {
+				Expect(err).To(MatchError("Bad parameters for SendManyWithOptions: you must set at least one amount"))
+			})
+		})
+	})
+
+	Describe("Testing SendManyReplaceable", func() {
+		for _, replaceable := range []*bool{nil, new(bool)} {
+			replaceable := replaceable
+			Context(fmt.Sprintf("when replaceable is set: %v", replaceable != nil), func() {
+				var params []interface{}
+				handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					params = decodeParams(r)
+					fmt.Fprintln(w, `{"result":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515","error":null,"id":1400502065079564568}`)
+				})
+				ts, host, port, err := getNewTestServer(handler)
+				if err != nil {
+					log.Fatalln(err)
+				}
+				defer ts.Close()
+				bitcoindClient, _ := New(host, port, "x", "fake", false)
+				_, err = bitcoindClient.SendManyReplaceable("", map[string]float64{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4": 0.1}, 1, "", []string{}, replaceable)
+				It("should not error", func() {
+					Expect(err).NotTo(HaveOccurred())
+				})
+				if replaceable == nil {
+					It("should not send replaceable", func() {
+						Expect(params).To(HaveLen(5))
+					})
+				} else {
+					It("should send replaceable", func() {
+						Expect(params).To(HaveLen(6))
+						Expect(params[5]).To(BeFalse())
+					})
+				}
+			})
+		}
+	})
+
+	Describe("Testing Send", func() {
+		Context("when success with options", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"complete":true,"hex":"02000000000101"},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			addToWallet := false
+			feeRate := 2.0
+			result, err := bitcoindClient.Send(
+				[]TxOutput{{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", Amount: 0.1}},
+				&SendOptions{AddToWallet: &addToWallet, FeeRate: &feeRate, SubtractFeeFromOutputs: []int{0}})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the options object", func() {
+				Expect(params).To(Equal([]interface{}{
+					[]interface{}{map[string]interface{}{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4": 0.1}},
+					nil, nil, nil,
+					map[string]interface{}{"add_to_wallet": false, "fee_rate": float64(2), "subtract_fee_from_outputs": []interface{}{float64(0)}},
+				}))
+			})
+			It("should return", func() {
+				Expect(result).To(Equal(SendResult{Complete: true, Hex: "02000000000101"}))
+			})
+		})
+	})
+
+	Describe("Testing SendAll", func() {
+		Context("when success", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":{"complete":true,"txid":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"},"error":null,"id":1400502065079564568}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			sendMax := true
+			result, err := bitcoindClient.SendAll([]SendAllRecipient{
+				{Address: "1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy", Amount: 0.5},
+				{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+			}, &SendAllOptions{SendMax: &sendMax})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the recipients and options", func() {
+				Expect(params).To(Equal([]interface{}{
+					[]interface{}{map[string]interface{}{"1Pyizp4HK7Bfz7CdbSwHHtprk7Ghumhxmy": 0.5}, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+					nil, nil, nil,
+					map[string]interface{}{"send_max": true},
+				}))
+			})
+			It("should return", func() {
+				Expect(result).To(Equal(SendResult{Complete: true, TxID: "a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515"}))
+			})
+		})
+		Context("when no recipient is given", func() {
+			bitcoindClient, _ := New("127.0.0.1", 123, "x", "fake", false)
+			_, err := bitcoindClient.SendAll(nil, nil)
+			It("should error", func() {
+				Expect(err).To(MatchError("Bad parameters for SendAll: you must set at least one recipient"))
+			})
+		})
+	})
+})
@@ -6,13 +6,24 @@ type ScriptSig struct {
 	Hex string `json:"hex"`
 }
 
+// Prevout represents the output spent by an input, as returned by getblock
+// with verbosity 3
+type Prevout struct {
+	Generated    bool         `json:"generated"`
+	Height       uint64       `json:"height"`
+	Value        Amount       `json:"value"`
+	ScriptPubKey ScriptPubKey `json:"scriptPubKey"`
+}
+
 // Vin represent an IN value
 type Vin struct {
-	Coinbase  string    `json:"coinbase"`
-	Txid      string    `json:"txid"`
-	Vout      int       `json:"vout"`
-	ScriptSig ScriptSig `json:"scriptSig"`
-	Sequence  uint32    `json:"sequence"`
+	Coinbase    string    `json:"coinbase"`
+	Txid        string    `json:"txid"`
+	Vout        int       `json:"vout"`
+	ScriptSig   ScriptSig `json:"scriptSig"`
+	Txinwitness []string  `json:"txinwitness,omitempty"`
+	Prevout     *Prevout  `json:"prevout,omitempty"`
+	Sequence    uint32    `json:"sequence"`
 }
 
 type ScriptPubKey struct {
@@ -26,7 +37,7 @@ type ScriptPubKey struct {
 
 // Vout represent an OUT value
 type Vout struct {
-	Value        float64      `json:"value"`
+	Value        Amount       `json:"value"`
 	N            int          `json:"n"`
 	ScriptPubKey ScriptPubKey `json:"scriptPubKey"`
 }
@@ -35,10 +46,15 @@ type Vout struct {
 type RawTransaction struct {
 	Hex           string `json:"hex"`
 	Txid          string `json:"txid"`
+	Hash          string `json:"hash,omitempty"`
+	Size          uint64 `json:"size,omitempty"`
+	Vsize         uint64 `json:"vsize,omitempty"`
+	Weight        uint64 `json:"weight,omitempty"`
 	Version       uint32 `json:"version"`
 	LockTime      uint32 `json:"locktime"`
 	Vin           []Vin  `json:"vin"`
 	Vout          []Vout `json:"vout"`
+	Fee           Amount `json:"fee,omitempty"`
 	BlockHash     string `json:"blockhash,omitempty"`
 	Confirmations uint64 `json:"confirmations,omitempty"`
 	Time          int64  `json:"time,omitempty"`
@@ -47,38 +63,42 @@ type RawTransaction struct {
 
 // TransactionDetails represents details about a transaction
 type TransactionDetails struct {
-	Account  string  `json:"account"`
-	Address  string  `json:"address,omitempty"`
-	Category string  `json:"category"`
-	Amount   float64 `json:"amount"`
-	Fee      float64 `json:"fee,omitempty"`
-	Label    string  `json:"label,omitempty"`
+	Account   string `json:"account"`
+	Address   string `json:"address,omitempty"`
+	Category  string `json:"category"`
+	Amount    Amount `json:"amount"`
+	Fee       Amount `json:"fee,omitempty"`
+	Label     string `json:"label,omitempty"`
+	Abandoned bool   `json:"abandoned,omitempty"`
 }
 
 // Transaction represents a transaction
 type Transaction struct {
-	Amount          float64              `json:"amount"`
-	Account         string               `json:"account,omitempty"`
-	Address         string               `json:"address,omitempty"`
-	Category        string               `json:"category,omitempty"`
-	Fee             float64              `json:"fee,omitempty"`
-	Confirmations   int64                `json:"confirmations"`
-	BlockHash       string               `json:"blockhash"`
-	BlockIndex      int64                `json:"blockindex"`
-	BlockTime       int64                `json:"blocktime"`
-	TxID            string               `json:"txid"`
-	WalletConflicts []string             `json:"walletconflicts"`
-	Time            int64                `json:"time"`
-	TimeReceived    int64                `json:"timereceived"`
-	Details         []TransactionDetails `json:"details,omitempty"`
-	Hex             string               `json:"hex,omitempty"`
+	Amount            Amount               `json:"amount"`
+	Account           string               `json:"account,omitempty"`
+	Address           string               `json:"address,omitempty"`
+	Category          string               `json:"category,omitempty"`
+	Fee               Amount               `json:"fee,omitempty"`
+	Confirmations     int64                `json:"confirmations"`
+	BlockHash         string               `json:"blockhash"`
+	BlockIndex        int64                `json:"blockindex"`
+	BlockTime         int64                `json:"blocktime"`
+	TxID              string               `json:"txid"`
+	WalletConflicts   []string             `json:"walletconflicts"`
+	Time              int64                `json:"time"`
+	TimeReceived      int64                `json:"timereceived"`
+	Details           []TransactionDetails `json:"details,omitempty"`
+	Hex               string               `json:"hex,omitempty"`
+	Bip125Replaceable string               `json:"bip125-replaceable,omitempty"`
+	ReplacedByTxID    string               `json:"replaced_by_txid,omitempty"`
+	ReplacesTxID      string               `json:"replaces_txid,omitempty"`
 }
 
 // UTransactionOut represents a unspent transaction out (UTXO)
 type UTransactionOut struct {
 	Bestblock     string       `json:"bestblock"`
 	Confirmations uint32       `json:"confirmations"`
-	Value         float64      `json:"value"`
+	Value         Amount       `json:"value"`
 	ScriptPubKey  ScriptPubKey `json:"scriptPubKey"`
 	Version       uint32       `json:"version"`
 	Coinbase      bool         `json:"coinbase"`
@@ -92,5 +112,5 @@ type TransactionOutSet struct {
 	TxOuts          float64 `json:"txouts"`
 	BytesSerialized float64 `json:"bytes_serialized"`
 	HashSerialized  string  `json:"hash_serialized"`
-	TotalAmount     float64 `json:"total_amount"`
+	TotalAmount     Amount  `json:"total_amount"`
 }
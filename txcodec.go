@@ -0,0 +1,398 @@
+package bitcoind
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/toorop/go-bitcoind/address"
+	"github.com/toorop/go-bitcoind/script"
+)
+
+// maxSize is the maximum size of a serialized object, as enforced by bitcoind
+// when reading compact sizes
+const maxSize = 0x02000000
+
+// witnessScaleFactor is the weight of a non witness byte, as defined in
+// BIP 141
+const witnessScaleFactor = 4
+
+// A txReader reads the fields of serialized transactions and blocks
+type txReader struct {
+	data []byte
+	pos  int
+}
+
+// read returns the next n bytes
+func (r *txReader) read(n int) ([]byte, error) {
+	if n < 0 || n > len(r.data)-r.pos {
+		return nil, errors.New("Unexpected end of data")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// readUint32 returns the next little endian uint32
+func (r *txReader) readUint32() (uint32, error) {
+	b, err := r.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// readUint64 returns the next little endian uint64
+func (r *txReader) readUint64() (uint64, error) {
+	b, err := r.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// readCompactSize returns the next compact size, which must be canonical and
+// at most maxSize, as bitcoind requires
+func (r *txReader) readCompactSize() (uint64, error) {
+	b, err := r.read(1)
+	if err != nil {
+		return 0, err
+	}
+	var n, min uint64
+	switch b[0] {
+	case 0xfd:
+		v, err := r.read(2)
+		if err != nil {
+			return 0, err
+		}
+		n, min = uint64(binary.LittleEndian.Uint16(v)), 0xfd
+	case 0xfe:
+		v, err := r.readUint32()
+		if err != nil {
+			return 0, err
+		}
+		n, min = uint64(v), 0x10000
+	case 0xff:
+		if n, err = r.readUint64(); err != nil {
+			return 0, err
+		}
+		min = 0x100000000
+	default:
+		return uint64(b[0]), nil
+	}
+	if n < min {
+		return 0, errors.New("Non-canonical compact size")
+	}
+	if n > maxSize {
+		return 0, errors.New("Compact size too large")
+	}
+	return n, nil
+}
+
+// readVarBytes returns the next bytes prefixed by their compact size length
+func (r *txReader) readVarBytes() ([]byte, error) {
+	n, err := r.readCompactSize()
+	if err != nil {
+		return nil, err
+	}
+	return r.read(int(n))
+}
+
+// writeCompactSize appends the compact size encoding of n
+func writeCompactSize(buf *bytes.Buffer, n uint64) {
+	var b [9]byte
+	switch {
+	case n < 0xfd:
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		b[0] = 0xfd
+		binary.LittleEndian.PutUint16(b[1:], uint16(n))
+		buf.Write(b[:3])
+	case n <= 0xffffffff:
+		b[0] = 0xfe
+		binary.LittleEndian.PutUint32(b[1:], uint32(n))
+		buf.Write(b[:5])
+	default:
+		b[0] = 0xff
+		binary.LittleEndian.PutUint64(b[1:], n)
+		buf.Write(b[:9])
+	}
+}
+
+// ParseTransaction decodes an hex encoded serialized transaction, as returned
+// by GetRawTransaction without verbose
+func ParseTransaction(rawHex string) (tx RawTransaction, err error) {
+	data, err := hex.DecodeString(rawHex)
+	if err != nil {
+		return
+	}
+	return DecodeTransaction(data)
+}
+
+// DecodeTransaction decodes a serialized transaction, legacy or segwit, like
+// "decoderawtransaction" does: inputs, outputs and witness data, txid, hash
+// (wtxid), size, vsize and weight are set, as well as the asm of scripts and
+// the type of scriptPubKeys. Addresses depend on the network and are left
+// empty, see DecodeScriptPubKey.
+func DecodeTransaction(data []byte) (tx RawTransaction, err error) {
+	r := &txReader{data: data}
+	if tx, err = readTransaction(r); err != nil {
+		return
+	}
+	if r.pos != len(data) {
+		err = fmt.Errorf("Bad transaction: %d trailing bytes", len(data)-r.pos)
+	}
+	return
+}
+
+// readTransaction reads a serialized transaction, as bitcoind does
+func readTransaction(r *txReader) (tx RawTransaction, err error) {
+	start := r.pos
+	if tx.Version, err = r.readUint32(); err != nil {
+		return
+	}
+	var flags byte
+	if tx.Vin, err = readInputs(r); err != nil {
+		return
+	}
+	if len(tx.Vin) == 0 {
+		// Either the segwit marker, or a transaction without inputs nor
+		// outputs
+		var b []byte
+		if b, err = r.read(1); err != nil {
+			return
+		}
+		if flags = b[0]; flags != 0 {
+			if tx.Vin, err = readInputs(r); err != nil {
+				return
+			}
+			if tx.Vout, err = readOutputs(r); err != nil {
+				return
+			}
+		} else {
+			tx.Vout = []Vout{}
+		}
+	} else if tx.Vout, err = readOutputs(r); err != nil {
+		return
+	}
+	if flags&1 != 0 {
+		flags ^= 1
+		for i := range tx.Vin {
+			if tx.Vin[i].Txinwitness, err = readWitness(r); err != nil {
+				return
+			}
+		}
+		if !hasWitness(tx) {
+			err = errors.New("Superfluous witness record")
+			return
+		}
+	}
+	if flags != 0 {
+		err = errors.New("Unknown transaction optional data")
+		return
+	}
+	if tx.LockTime, err = r.readUint32(); err != nil {
+		return
+	}
+	if len(tx.Vin) == 1 && tx.Vin[0].Txid == nullHash && uint32(tx.Vin[0].Vout) == 0xffffffff {
+		tx.Vin[0] = Vin{Coinbase: tx.Vin[0].ScriptSig.Hex, Txinwitness: tx.Vin[0].Txinwitness, Sequence: tx.Vin[0].Sequence}
+	}
+
+	raw := r.data[start:r.pos]
+	stripped, err := tx.serialize(false)
+	if err != nil {
+		return
+	}
+	tx.Hex = hex.EncodeToString(raw)
+	tx.Txid = hashToString(doubleSha256(stripped))
+	tx.Hash = hashToString(doubleSha256(raw))
+	tx.Size = uint64(len(raw))
+	tx.Weight = uint64(len(stripped)*(witnessScaleFactor-1) + len(raw))
+	tx.Vsize = (tx.Weight + witnessScaleFactor - 1) / witnessScaleFactor
+	return
+}
+
+// nullHash is the previous transaction id of coinbase inputs
+const nullHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// readInputs reads the inputs of a transaction, without their witness
+func readInputs(r *txReader) ([]Vin, error) {
+	n, err := r.readCompactSize()
+	if err != nil {
+		return nil, err
+	}
+	vin := make([]Vin, 0, minCount(n, r))
+	for i := uint64(0); i < n; i++ {
+		hash, err := r.read(32)
+		if err != nil {
+			return nil, err
+		}
+		index, err := r.readUint32()
+		if err != nil {
+			return nil, err
+		}
+		sig, err := r.readVarBytes()
+		if err != nil {
+			return nil, err
+		}
+		sequence, err := r.readUint32()
+		if err != nil {
+			return nil, err
+		}
+		scriptSig := ScriptSig{Asm: script.Script(sig).SigAsm(), Hex: hex.EncodeToString(sig)}
+		vin = append(vin, Vin{Txid: hashToString(hash), Vout: int(index), ScriptSig: scriptSig, Sequence: sequence})
+	}
+	return vin, nil
+}
+
+// readOutputs reads the outputs of a transaction
+func readOutputs(r *txReader) ([]Vout, error) {
+	n, err := r.readCompactSize()
+	if err != nil {
+		return nil, err
+	}
+	vout := make([]Vout, 0, minCount(n, r))
+	for i := uint64(0); i < n; i++ {
+		value, err := r.readUint64()
+		if err != nil {
+			return nil, err
+		}
+		scriptPubKey, err := r.readVarBytes()
+		if err != nil {
+			return nil, err
+		}
+		vout = append(vout, Vout{Value: Amount(value), N: int(i), ScriptPubKey: scriptPubKeyOf(scriptPubKey, nil)})
+	}
+	return vout, nil
+}
+
+// DecodeScriptPubKey decodes an hex encoded scriptPubKey like bitcoind does:
+// asm, type and, for scripts paying to an address, the address on net
+func DecodeScriptPubKey(scriptHex string, net *address.Network) (ScriptPubKey, error) {
+	s, err := script.ParseHex(scriptHex)
+	if err != nil {
+		return ScriptPubKey{}, err
+	}
+	return scriptPubKeyOf(s, net), nil
+}
+
+// scriptPubKeyOf returns the decoded scriptPubKey s, with its address on net
+// if net is not nil
+func scriptPubKeyOf(s script.Script, net *address.Network) ScriptPubKey {
+	spk := ScriptPubKey{Asm: s.Asm(), Hex: s.String(), Type: string(s.Type())}
+	if net != nil {
+		if addr, err := s.Address(net); err == nil {
+			spk.Address = addr.Address
+		}
+	}
+	return spk
+}
+
+// readWitness reads the witness stack of an input
+func readWitness(r *txReader) ([]string, error) {
+	n, err := r.readCompactSize()
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	stack := make([]string, 0, minCount(n, r))
+	for i := uint64(0); i < n; i++ {
+		item, err := r.readVarBytes()
+		if err != nil {
+			return nil, err
+		}
+		stack = append(stack, hex.EncodeToString(item))
+	}
+	return stack, nil
+}
+
+// minCount bounds a count read from data to the remaining bytes, so that a
+// bad count does not allocate more than the data size
+func minCount(n uint64, r *txReader) int {
+	if remaining := uint64(len(r.data) - r.pos); n > remaining {
+		return int(remaining)
+	}
+	return int(n)
+}
+
+// hasWitness tells whether an input of tx has witness data
+func hasWitness(tx RawTransaction) bool {
+	for _, in := range tx.Vin {
+		if len(in.Txinwitness) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Serialize returns the serialized form of the transaction, with witness data
+// when an input has some, from its inputs, outputs, version and locktime
+func (tx RawTransaction) Serialize() ([]byte, error) {
+	return tx.serialize(true)
+}
+
+// serialize returns the serialized form of the transaction, with or without
+// witness data
+func (tx RawTransaction) serialize(witness bool) ([]byte, error) {
+	witness = witness && hasWitness(tx)
+	var buf bytes.Buffer
+	var b [8]byte
+	binary.LittleEndian.PutUint32(b[:4], tx.Version)
+	buf.Write(b[:4])
+	if witness {
+		buf.Write([]byte{0x00, 0x01})
+	}
+	writeCompactSize(&buf, uint64(len(tx.Vin)))
+	for i, in := range tx.Vin {
+		hash, index, scriptHex := make([]byte, 32), uint32(0xffffffff), in.Coinbase
+		if in.Txid != "" {
+			var err error
+			if hash, err = hashFromString(in.Txid); err != nil {
+				return nil, fmt.Errorf("Bad input %d: %v", i, err)
+			}
+			index, scriptHex = uint32(in.Vout), in.ScriptSig.Hex
+		}
+		script, err := hex.DecodeString(scriptHex)
+		if err != nil {
+			return nil, fmt.Errorf("Bad input %d script: %v", i, err)
+		}
+		buf.Write(hash)
+		binary.LittleEndian.PutUint32(b[:4], index)
+		buf.Write(b[:4])
+		writeCompactSize(&buf, uint64(len(script)))
+		buf.Write(script)
+		binary.LittleEndian.PutUint32(b[:4], in.Sequence)
+		buf.Write(b[:4])
+	}
+	writeCompactSize(&buf, uint64(len(tx.Vout)))
+	for i, out := range tx.Vout {
+		script, err := hex.DecodeString(out.ScriptPubKey.Hex)
+		if err != nil {
+			return nil, fmt.Errorf("Bad output %d script: %v", i, err)
+		}
+		binary.LittleEndian.PutUint64(b[:], uint64(out.Value))
+		buf.Write(b[:])
+		writeCompactSize(&buf, uint64(len(script)))
+		buf.Write(script)
+	}
+	if witness {
+		for i, in := range tx.Vin {
+			writeCompactSize(&buf, uint64(len(in.Txinwitness)))
+			for _, itemHex := range in.Txinwitness {
+				item, err := hex.DecodeString(itemHex)
+				if err != nil {
+					return nil, fmt.Errorf("Bad input %d witness: %v", i, err)
+				}
+				writeCompactSize(&buf, uint64(len(item)))
+				buf.Write(item)
+			}
+		}
+	}
+	binary.LittleEndian.PutUint32(b[:4], tx.LockTime)
+	buf.Write(b[:4])
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,177 @@
+package bitcoind
+
+import (
+	"encoding/hex"
+
+	"github.com/toorop/go-bitcoind/address"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// genesisCoinbaseHex is the coinbase transaction of the genesis block
+const genesisCoinbaseHex = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff4d04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73ffffffff0100f2052a01000000434104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac00000000"
+
+// segwitTxHex is the signed native P2WPKH transaction of BIP 143
+const segwitTxHex = "01000000000102fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f00000000494830450221008b9d1dc26ba6a9cb62127b02742fa9d754cd3bebf337f7a55d114c8e5cdd30be022040529b194ba3f9281a99f2b1c0a19c0489bc22ede944ccf4ecbab4cc618ef3ed01eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac000247304402203609e17b84f6a7d30c80bfa610b5b4542f32a8a0d5447a12fb1366d7f01cc44a0220573a954c4518331561406f90300e8f3358f51928d43c212a8caed02de67eebee0121025476c2e83188368da1ff3e292e7acafcdb3566bb0ad253f62fc70f07aeee635711000000"
+
+// segwitTxStrippedHex is segwitTxHex without witness data
+const segwitTxStrippedHex = "0100000002fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f00000000494830450221008b9d1dc26ba6a9cb62127b02742fa9d754cd3bebf337f7a55d114c8e5cdd30be022040529b194ba3f9281a99f2b1c0a19c0489bc22ede944ccf4ecbab4cc618ef3ed01eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac11000000"
+
+var _ = Describe("TxCodec", func() {
+	Describe("Testing ParseTransaction", func() {
+		Context("when the transaction is a legacy coinbase", func() {
+			tx, err := ParseTransaction(genesisCoinbaseHex)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should compute the txid", func() {
+				Expect(tx.Txid).To(Equal("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"))
+				Expect(tx.Hash).To(Equal(tx.Txid))
+			})
+			It("should compute the sizes", func() {
+				Expect(tx.Size).To(Equal(uint64(204)))
+				Expect(tx.Vsize).To(Equal(uint64(204)))
+				Expect(tx.Weight).To(Equal(uint64(816)))
+			})
+			It("should decode the coinbase input", func() {
+				Expect(tx.Vin).To(HaveLen(1))
+				Expect(tx.Vin[0].Txid).To(BeEmpty())
+				Expect(tx.Vin[0].Coinbase).To(HavePrefix("04ffff001d0104455468652054696d6573"))
+				Expect(tx.Vin[0].Sequence).To(Equal(uint32(0xffffffff)))
+			})
+			It("should decode the output", func() {
+				Expect(tx.Vout).To(HaveLen(1))
+				Expect(tx.Vout[0].Value).To(Equal(Amount(5000000000)))
+				Expect(tx.Vout[0].ScriptPubKey.Hex).To(HavePrefix("4104678afdb0"))
+				Expect(tx.Version).To(Equal(uint32(1)))
+				Expect(tx.LockTime).To(Equal(uint32(0)))
+			})
+			It("should roundtrip", func() {
+				data, err := tx.Serialize()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hex.EncodeToString(data)).To(Equal(genesisCoinbaseHex))
+				Expect(tx.Hex).To(Equal(genesisCoinbaseHex))
+			})
+		})
+
+		Context("when the transaction is segwit", func() {
+			tx, err := ParseTransaction(segwitTxHex)
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should decode the witness", func() {
+				Expect(tx.Vin).To(HaveLen(2))
+				Expect(tx.Vin[0].Txinwitness).To(BeEmpty())
+				Expect(tx.Vin[0].ScriptSig.Hex).To(HavePrefix("4830450221008b9d1dc2"))
+				Expect(tx.Vin[0].Txid).To(Equal("9f96ade4b41d5433f4eda31e1738ec2b36f6e7d1420d94a6af99801a88f7f7ff"))
+				Expect(tx.Vin[0].Sequence).To(Equal(uint32(0xffffffee)))
+				Expect(tx.Vin[1].Vout).To(Equal(1))
+				Expect(tx.Vin[1].Txinwitness).To(HaveLen(2))
+				Expect(tx.Vin[1].Txinwitness[1]).To(Equal("025476c2e83188368da1ff3e292e7acafcdb3566bb0ad253f62fc70f07aeee6357"))
+			})
+			It("should decode the outputs", func() {
+				Expect(tx.Vout).To(HaveLen(2))
+				Expect(tx.Vout[0].Value).To(Equal(Amount(112340000)))
+				Expect(tx.Vout[1].Value).To(Equal(Amount(223450000)))
+				Expect(tx.Vout[1].N).To(Equal(1))
+				Expect(tx.LockTime).To(Equal(uint32(17)))
+			})
+			It("should disassemble the scripts", func() {
+				Expect(tx.Vin[0].ScriptSig.Asm).To(Equal("30450221008b9d1dc26ba6a9cb62127b02742fa9d754cd3bebf337f7a55d114c8e5cdd30be022040529b194ba3f9281a99f2b1c0a19c0489bc22ede944ccf4ecbab4cc618ef3ed[ALL]"))
+				Expect(tx.Vout[0].ScriptPubKey.Asm).To(Equal("OP_DUP OP_HASH160 8280b37df378db99f66f85c95a783a76ac7a6d59 OP_EQUALVERIFY OP_CHECKSIG"))
+				Expect(tx.Vout[0].ScriptPubKey.Type).To(Equal("pubkeyhash"))
+				Expect(tx.Vout[0].ScriptPubKey.Address).To(BeEmpty())
+			})
+			It("should compute the txid without witness", func() {
+				stripped, err := ParseTransaction(segwitTxStrippedHex)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tx.Txid).To(Equal(stripped.Txid))
+				Expect(tx.Hash).NotTo(Equal(tx.Txid))
+			})
+			It("should compute the sizes", func() {
+				Expect(tx.Size).To(Equal(uint64(len(segwitTxHex) / 2)))
+				Expect(tx.Weight).To(Equal(uint64(len(segwitTxStrippedHex)/2*3 + len(segwitTxHex)/2)))
+				Expect(tx.Vsize).To(Equal((tx.Weight + 3) / 4))
+			})
+			It("should roundtrip", func() {
+				data, err := tx.Serialize()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hex.EncodeToString(data)).To(Equal(segwitTxHex))
+			})
+		})
+
+		Context("when the transaction has no inputs nor outputs", func() {
+			tx, err := ParseTransaction("01000000000000000000")
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tx.Vin).To(BeEmpty())
+				Expect(tx.Vout).To(BeEmpty())
+			})
+		})
+
+		Context("when the transaction is invalid", func() {
+			It("should error", func() {
+				for _, rawHex := range []string{
+					"",
+					"zz",
+					genesisCoinbaseHex[:len(genesisCoinbaseHex)-2],
+					genesisCoinbaseHex + "00",
+					// Superfluous witness record
+					"0100000000010100000000000000000000000000000000000000000000000000000000000000000000000000ffffffff0000000000000000000000",
+					// Unknown optional data
+					"01000000000201000000000000000000000000000000000000000000000000000000000000000000000000ffffffff000000000000",
+					// Non canonical compact size
+					"01000000fd0100",
+				} {
+					_, err := ParseTransaction(rawHex)
+					Expect(err).To(HaveOccurred(), rawHex)
+				}
+			})
+		})
+	})
+
+	Describe("Testing DecodeScriptPubKey", func() {
+		It("should decode the script and its address", func() {
+			spk, err := DecodeScriptPubKey("0014751e76e8199196d454941c45d1b3a323f1433bd6", address.MainNet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spk).To(Equal(ScriptPubKey{
+				Asm:     "0 751e76e8199196d454941c45d1b3a323f1433bd6",
+				Hex:     "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+				Type:    "witness_v0_keyhash",
+				Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+			}))
+		})
+		It("should not set an address for scripts without one", func() {
+			spk, err := DecodeScriptPubKey("6a0b68656c6c6f20776f726c64", address.MainNet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spk.Type).To(Equal("nulldata"))
+			Expect(spk.Address).To(BeEmpty())
+		})
+		It("should fail on bad hex", func() {
+			_, err := DecodeScriptPubKey("zz", address.MainNet)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Testing Serialize", func() {
+		It("should serialize a new transaction", func() {
+			tx := RawTransaction{
+				Version: 2,
+				Vin:     []Vin{{Txid: "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b", Vout: 0, Sequence: 0xfffffffd, Txinwitness: []string{"", "01"}}},
+				Vout:    []Vout{{Value: 1010000, ScriptPubKey: ScriptPubKey{Hex: "0014751e76e8199196d454941c45d1b3a323f1433bd6"}}},
+			}
+			data, err := tx.Serialize()
+			Expect(err).NotTo(HaveOccurred())
+			decoded, err := DecodeTransaction(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded.Vin[0].Txinwitness).To(Equal([]string{"", "01"}))
+			Expect(decoded.Vout[0].Value).To(Equal(Amount(1010000)))
+			Expect(decoded.Version).To(Equal(uint32(2)))
+		})
+		It("should fail on bad hex", func() {
+			_, err := RawTransaction{Vin: []Vin{{Txid: "bad"}}}.Serialize()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
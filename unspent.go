@@ -0,0 +1,109 @@
+package bitcoind
+
+import (
+	"encoding/json"
+)
+
+// MaxConfirmations is the default maximum number of confirmations of
+// "listunspent" call
+const MaxConfirmations = 9999999
+
+// Unspent represents an unspent output of the wallet, as returned by
+// "listunspent" call
+type Unspent struct {
+	// The transaction id
+	TxID string `json:"txid"`
+	// The output number
+	Vout uint32 `json:"vout"`
+	// The bitcoin address
+	Address string `json:"address,omitempty"`
+	// The associated label, or "" for the default label
+	Label string `json:"label,omitempty"`
+	// The associated account, returned by nodes with accounts
+	Account string `json:"account,omitempty"`
+	// The script key
+	ScriptPubKey string `json:"scriptPubKey"`
+	// The transaction output amount
+	Amount Amount `json:"amount"`
+	// The number of confirmations
+	Confirmations int64 `json:"confirmations"`
+	// The number of in-mempool ancestor transactions, including this one
+	// (if transaction is in the mempool)
+	AncestorCount uint64 `json:"ancestorcount,omitempty"`
+	// The virtual transaction size of in-mempool ancestors, including this
+	// one (if transaction is in the mempool)
+	AncestorSize uint64 `json:"ancestorsize,omitempty"`
+	// The total fees of in-mempool ancestors, including this one, in
+	// satoshis (if transaction is in the mempool)
+	AncestorFees uint64 `json:"ancestorfees,omitempty"`
+	// The redeemScript if scriptPubKey is P2SH
+	RedeemScript string `json:"redeemScript,omitempty"`
+	// The witnessScript if the scriptPubKey is P2WSH or P2SH-P2WSH
+	WitnessScript string `json:"witnessScript,omitempty"`
+	// Whether we have the private keys to spend this output
+	Spendable bool `json:"spendable"`
+	// Whether we know how to spend this output, ignoring the lack of keys
+	Solvable bool `json:"solvable"`
+	// Whether this output is reused, only present with avoid_reuse wallets
+	Reused *bool `json:"reused,omitempty"`
+	// A descriptor for spending this output, only when solvable
+	Desc string `json:"desc,omitempty"`
+	// The descriptors used to create this output, for descriptor wallets
+	ParentDescs []string `json:"parent_descs,omitempty"`
+	// Whether this output is considered safe to spend. Unconfirmed
+	// transactions from outside keys and unconfirmed replacement transactions
+	// are considered unsafe and are not eligible for spending by
+	// fundrawtransaction and sendtoaddress.
+	Safe bool `json:"safe"`
+}
+
+// UnspentQueryOptions represents the "query_options" of "listunspent" call,
+// nil fields are left to the node defaults
+type UnspentQueryOptions struct {
+	// Minimum value of each UTXO
+	MinimumAmount *Amount `json:"minimumAmount,omitempty"`
+	// Maximum value of each UTXO
+	MaximumAmount *Amount `json:"maximumAmount,omitempty"`
+	// Maximum number of UTXOs
+	MaximumCount *uint32 `json:"maximumCount,omitempty"`
+	// Minimum sum value of all UTXOs
+	MinimumSumAmount *Amount `json:"minimumSumAmount,omitempty"`
+}
+
+// ListUnspentOptions represents the filters of "listunspent" call
+type ListUnspentOptions struct {
+	// Only return outputs paying to these addresses, all if empty
+	Addresses []string
+	// Whether to include outputs that are not safe to spend, nil for the
+	// node default (true)
+	IncludeUnsafe *bool
+	// Amount and count filters, may be nil
+	QueryOptions *UnspentQueryOptions
+}
+
+// ListUnspentWithOptions returns the unspent outputs of the wallet with
+// between [minconf] and [maxconf] confirmations (MaxConfirmations being the
+// node default), filtered by [options] which may be nil.
+func (b *Bitcoind) ListUnspentWithOptions(minconf, maxconf uint32, options *ListUnspentOptions) (unspents []Unspent, err error) {
+	params := []interface{}{minconf, maxconf}
+	if options != nil {
+		addresses := options.Addresses
+		if addresses == nil {
+			addresses = []string{}
+		}
+		includeUnsafe := true
+		if options.IncludeUnsafe != nil {
+			includeUnsafe = *options.IncludeUnsafe
+		}
+		params = append(params, addresses, includeUnsafe)
+		if options.QueryOptions != nil {
+			params = append(params, options.QueryOptions)
+		}
+	}
+	r, err := b.client.call("listunspent", params)
+	if err = handleError(err, &r); err != nil {
+		return
+	}
+	err = json.Unmarshal(r.Result, &unspents)
+	return
+}
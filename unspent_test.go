@@ -0,0 +1,83 @@
+package bitcoind
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unspent", func() {
+	Describe("Testing ListUnspentWithOptions", func() {
+		Context("when success with options", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":[{"txid":"a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515","vout":1,"address":"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4","label":"savings","scriptPubKey":"0014751e76e8199196d454941c45d1b3a323f1433bd6","amount":0.50000000,"confirmations":6,"spendable":true,"solvable":true,"desc":"wpkh([d34db33f/84h/0h/0h/0/1]0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798)#8zl0zxma","parent_descs":["wpkh(xpub/0/*)#abcdefgh"],"safe":true}],"error":null,"id":1400778484990055255}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			includeUnsafe := false
+			minimumAmount := Amount(10000000)
+			maximumCount := uint32(10)
+			unspents, err := bitcoindClient.ListUnspentWithOptions(1, MaxConfirmations, &ListUnspentOptions{
+				Addresses:     []string{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+				IncludeUnsafe: &includeUnsafe,
+				QueryOptions:  &UnspentQueryOptions{MinimumAmount: &minimumAmount, MaximumCount: &maximumCount},
+			})
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			It("should send the filters", func() {
+				Expect(params).To(Equal([]interface{}{
+					float64(1),
+					float64(MaxConfirmations),
+					[]interface{}{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+					false,
+					map[string]interface{}{"minimumAmount": 0.1, "maximumCount": float64(10)},
+				}))
+			})
+			It("should return typed unspent outputs", func() {
+				Expect(unspents).To(Equal([]Unspent{{
+					TxID:          "a1b7093d041bc1b763ba1ad894d2bd5376b38e6c7369613684e7140e8d9f7515",
+					Vout:          1,
+					Address:       "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+					Label:         "savings",
+					ScriptPubKey:  "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+					Amount:        50000000,
+					Confirmations: 6,
+					Spendable:     true,
+					Solvable:      true,
+					Desc:          "wpkh([d34db33f/84h/0h/0h/0/1]0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798)#8zl0zxma",
+					ParentDescs:   []string{"wpkh(xpub/0/*)#abcdefgh"},
+					Safe:          true,
+				}}))
+			})
+		})
+		Context("when success without options", func() {
+			var params []interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				params = decodeParams(r)
+				fmt.Fprintln(w, `{"result":[],"error":null,"id":1400778484990055255}`)
+			})
+			ts, host, port, err := getNewTestServer(handler)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			defer ts.Close()
+			bitcoindClient, _ := New(host, port, "x", "fake", false)
+			unspents, err := bitcoindClient.ListUnspentWithOptions(0, 10, nil)
+			It("should only send confirmations", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(params).To(Equal([]interface{}{float64(0), float64(10)}))
+				Expect(unspents).To(BeEmpty())
+			})
+		})
+	})
+})